@@ -11,12 +11,13 @@ import (
 	"log"
 	"os"
 	"path"
+	"runtime"
 	"strings"
-	"sync"
 	"text/template"
 
 	"github.com/spf13/cobra"
 	"github.com/timothyrenner/movies-app/database"
+	"golang.org/x/sync/errgroup"
 )
 
 // buildObsidianVaultCmd represents the buildObsidianVault command
@@ -34,10 +35,33 @@ func init() {
 	buildObsidianVaultCmd.Flags().BoolP(
 		"force", "f", false, "Whether to force rebuild the whole vault or not.",
 	)
+	buildObsidianVaultCmd.Flags().BoolP(
+		"merge", "m", false,
+		"Whether to update the generated sections of existing pages, "+
+			"keeping everything else.",
+	)
 	buildObsidianVaultCmd.Flags().IntP(
 		"limit", "l", 0,
 		"The maximum number of records to pull. 0 means pull all of them.",
 	)
+	buildObsidianVaultCmd.Flags().IntP(
+		"concurrency", "j", runtime.NumCPU(),
+		"How many pages to write at once.",
+	)
+	buildObsidianVaultCmd.Flags().Bool(
+		"prune", false,
+		"Whether to list movie and watch pages that aren't in the database.",
+	)
+	buildObsidianVaultCmd.Flags().Bool(
+		"archive", false,
+		"Whether to move the pages --prune finds to the Archive folder and "+
+			"point their links at the pages that replaced them.",
+	)
+	buildObsidianVaultCmd.Flags().Bool(
+		"archive-unknown", false,
+		"Whether --archive also moves watch pages with no watch in the "+
+			"database, which usually haven't been ingested yet.",
+	)
 }
 
 func createOrOpenFile(force bool, path string) (*os.File, bool, error) {
@@ -65,6 +89,63 @@ func createOrOpenFile(force bool, path string) (*os.File, bool, error) {
 	return file, created, nil
 }
 
+func MoviePageFileName(fileTitle string, imdbId string) string {
+	return fmt.Sprintf("%v (%v).md", fileTitle, imdbId)
+}
+
+func MovieWatchPageFileName(page *MovieWatchPage) string {
+	return fmt.Sprintf("%v %v.md", page.Watched, page.FileTitle)
+}
+
+// ReviewPageName is the review page's file name without the extension,
+// which is what the movie page links to.
+func ReviewPageName(page *MovieReviewPage) string {
+	if page.Watched != "" {
+		return fmt.Sprintf(
+			"%v (%v) Review %v", page.MovieTitle, page.ImdbId, page.Watched,
+		)
+	}
+	return fmt.Sprintf("%v (%v) Review", page.MovieTitle, page.ImdbId)
+}
+
+func ReviewPageFileName(page *MovieReviewPage) string {
+	return ReviewPageName(page) + ".md"
+}
+
+// WriteNewPage renders the page into filePath unless there's already a file
+// there, and reports whether it wrote one.
+func WriteNewPage(
+	pageTemplate *template.Template, filePath string, page interface{},
+) (bool, error) {
+	file, exists, err := createOrOpenFile(false, filePath)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+	defer file.Close()
+	if err := pageTemplate.Execute(file, page); err != nil {
+		return false, fmt.Errorf("error writing page %v: %v", filePath, err)
+	}
+	return true, nil
+}
+
+// WritePage renders the page into filePath, replacing whatever was there.
+func WritePage(
+	pageTemplate *template.Template, filePath string, page interface{},
+) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("error opening %v: %v", filePath, err)
+	}
+	defer file.Close()
+	if err := pageTemplate.Execute(file, page); err != nil {
+		return fmt.Errorf("error writing page %v: %v", filePath, err)
+	}
+	return nil
+}
+
 func buildObsidianVault(cmd *cobra.Command, args []string) {
 
 	vaultDir := args[0]
@@ -81,8 +162,45 @@ func buildObsidianVault(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Panicf("Error obtaining force value: %v", err)
 	}
-	if force {
+
+	concurrency, err := cmd.Flags().GetInt("concurrency")
+	if err != nil {
+		log.Panicf("Error obtaining concurrency: %v", err)
+	}
+	if concurrency < 1 {
+		log.Panicf("concurrency must be >= 1, got %v", concurrency)
+	}
+	merge, err := cmd.Flags().GetBool("merge")
+	if err != nil {
+		log.Panicf("Error obtaining merge value: %v", err)
+	}
+	mode := WRITE_MISSING
+	if force && merge {
+		log.Panicf("Use one of --force or --merge, not both.")
+	} else if force {
 		log.Println("Rebuilding entire vault (except notes).")
+		mode = WRITE_FORCE
+	} else if merge {
+		log.Println("Merging the database into existing pages.")
+		mode = WRITE_MERGE
+	}
+	prune, err := cmd.Flags().GetBool("prune")
+	if err != nil {
+		log.Panicf("Error obtaining prune value: %v", err)
+	}
+	archive, err := cmd.Flags().GetBool("archive")
+	if err != nil {
+		log.Panicf("Error obtaining archive value: %v", err)
+	}
+	if archive && !prune {
+		log.Panicf("--archive only works with --prune.")
+	}
+	archiveUnknown, err := cmd.Flags().GetBool("archive-unknown")
+	if err != nil {
+		log.Panicf("Error obtaining archive-unknown value: %v", err)
+	}
+	if archiveUnknown && !archive {
+		log.Panicf("--archive-unknown only works with --archive.")
 	}
 
 	ctx := context.Background()
@@ -110,6 +228,54 @@ func buildObsidianVault(cmd *cobra.Command, args []string) {
 			log.Panicf("Error creating %v", moviesDir)
 		}
 	}
+	peopleDir := path.Join(vaultDir, "People")
+	if err = os.Mkdir(peopleDir, 0755); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			log.Printf("%v exists", peopleDir)
+		} else {
+			log.Panicf("Error creating %v", peopleDir)
+		}
+	}
+
+	reviewsDir := path.Join(vaultDir, "Reviews")
+	if err = os.Mkdir(reviewsDir, 0755); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			log.Printf("%v exists", reviewsDir)
+		} else {
+			log.Panicf("Error creating %v", reviewsDir)
+		}
+	}
+
+	genresDir := path.Join(vaultDir, "Genres")
+	if err = os.Mkdir(genresDir, 0755); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			log.Printf("%v exists", genresDir)
+		} else {
+			log.Panicf("Error creating %v", genresDir)
+		}
+	}
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		log.Panicf("Error loading genre taxonomy: %v", err)
+	}
+
+	// Every page is named from these, so sort out titles that end up with
+	// the same name before writing anything.
+	log.Println("Saving vault names.")
+	suffixed, err := SaveAllVaultNames(ctx, queries)
+	if err != nil {
+		log.Panicf("Error saving vault names: %v", err)
+	}
+	for title, fileTitle := range suffixed {
+		log.Printf(
+			"%v has the same file name as another title, so it's named %v.",
+			title, fileTitle,
+		)
+	}
+	vaultNames, err := LoadVaultNames(ctx, queries)
+	if err != nil {
+		log.Panicf("Error loading vault names: %v", err)
+	}
 
 	// Step 1: Get all the movie watch records.
 	// Note: this is should be like ... paginated or something. Future
@@ -128,148 +294,315 @@ func buildObsidianVault(cmd *cobra.Command, args []string) {
 	}
 	log.Printf("Building vault info for %v watches.", len(movieWatches))
 
-	movieWatchTemplate, err := template.New("movie_watch").Parse(MOVIE_WATCH_TEMPLATE)
+	vaultTemplates, err := LoadVaultTemplates(vaultDir)
+	if err != nil {
+		log.Panicf("Unable to load templates: %v", err)
+	}
+	for _, vaultTemplate := range vaultTemplates.All() {
+		if vaultTemplate.Source != "" {
+			log.Printf("Using template %v", vaultTemplate.Source)
+		}
+	}
+	movieWatchTemplate := vaultTemplates.MovieWatch.Template
+	movieTemplate := vaultTemplates.Movie.Template
+	personTemplate, err := template.New("person").Parse(PERSON_TEMPLATE)
+	if err != nil {
+		log.Panicf("Unable to parse person template: %v", err)
+	}
+	genreTemplate, err := template.New("genre").Parse(GENRE_TEMPLATE)
+	if err != nil {
+		log.Panicf("Unable to parse genre template: %v", err)
+	}
+	reviewTemplate := vaultTemplates.Review.Template
+	// Steps 2 and 3: A page for each watch and one for each movie watched.
+	// Existing pages are left alone, replaced or merged depending on the
+	// mode.
+	progress := NewProgressBar(
+		os.Stderr, "Writing pages", len(movieWatches)+countMovies(movieWatches),
+	)
+	counts, err := WriteWatchAndMoviePages(
+		ctx,
+		queries,
+		taxonomy,
+		vaultNames,
+		movieWatchTemplate,
+		movieTemplate,
+		watchesDir,
+		moviesDir,
+		movieWatches,
+		mode,
+		concurrency,
+		progress,
+	)
+	progress.Finish()
+	if err != nil {
+		log.Panicf("Error writing pages (%v): %v", counts, err)
+	}
+	log.Printf("Watch and movie pages: %v.", counts)
+	for ii := range counts.Conflicts {
+		log.Printf(
+			"Left %v alone, it needs exactly one of each of %v to merge.",
+			counts.Conflicts[ii], strings.Join(GENERATED_SECTIONS, ", "),
+		)
+	}
+
+	// Step 4: Create a page for everyone credited on those movies. These are
+	// always rebuilt, but anything under the notes heading is kept.
+	movieUuids := make([]string, 0)
+	seenMovies := make(map[string]bool)
+	for ii := range movieWatches {
+		if !seenMovies[movieWatches[ii].MovieUuid] {
+			seenMovies[movieWatches[ii].MovieUuid] = true
+			movieUuids = append(movieUuids, movieWatches[ii].MovieUuid)
+		}
+	}
+	people, err := GetCreditedPeople(ctx, queries, movieUuids)
+	if err != nil {
+		log.Panicf("Error getting credited people: %v", err)
+	}
+	log.Printf("Building person pages for %v people.", len(people))
+	watchDates := make(map[string][]string)
+	for ii := range people {
+		if err := WritePersonPage(
+			ctx, queries, vaultNames, personTemplate, peopleDir, people[ii],
+			watchDates,
+		); err != nil {
+			log.Panicf("Error writing person page for %v: %v", people[ii], err)
+		}
+	}
+
+	// Step 5: Build the genre index pages for those same movies.
+	genres, err := GetGenreNames(ctx, queries, movieUuids)
+	if err != nil {
+		log.Panicf("Error getting genre names: %v", err)
+	}
+	log.Printf("Building genre pages for %v genres.", len(genres))
+	for ii := range genres {
+		if err := WriteGenrePage(
+			ctx,
+			queries,
+			vaultNames,
+			genreTemplate,
+			genresDir,
+			genres[ii],
+			taxonomy.Slug(genres[ii]),
+			watchDates,
+		); err != nil {
+			log.Panicf("Error writing genre page for %v: %v", genres[ii], err)
+		}
+	}
+
+	// Step 6: The watchlist. Its links only resolve for movies that already
+	// have a page, the rest fill in as they're watched.
+	log.Println("Building watchlist page.")
+	if err := WriteWatchlistPage(ctx, queries, vaultDir); err != nil {
+		log.Panicf("Error writing watchlist page: %v", err)
+	}
+
+	// Step 7: The lists, which are always rebuilt from the database. Run
+	// list update on any list page that's been edited before rebuilding.
+	log.Println("Building list pages.")
+	if err := WriteListPages(ctx, queries, vaultDir); err != nil {
+		log.Panicf("Error writing list pages: %v", err)
+	}
+
+	// Step 8: The reviews of those movies. Like watch pages these are only
+	// written if they're missing, so run update-review on any that have been
+	// edited before forcing a rebuild.
+	written, err := WriteReviewPages(
+		ctx, queries, vaultNames, reviewTemplate, reviewsDir, seenMovies, force,
+	)
+	if err != nil {
+		log.Panicf("Error writing review pages: %v", err)
+	}
+	log.Printf("Wrote %v review pages.", written)
+
+	// Step 9: The dashboards, which are always rebuilt like the lists. They
+	// count every watch, so they go by the whole database, not the limit.
+	log.Println("Building dashboard pages.")
+	written, err = WriteDashboardPages(vaultDir, allMovieWatches, vaultNames)
+	if err != nil {
+		log.Panicf("Error writing dashboard pages: %v", err)
+	}
+	log.Printf("Wrote %v dashboard pages.", written)
+
+	// Step 10: Pages left behind by movies and watches that were deleted or
+	// merged in the database. These go by the whole database, not the limit.
+	if !prune {
+		return
+	}
+	log.Println("Looking for stale pages.")
+	stalePages, unknownPages, err := FindStalePages(ctx, queries, vaultDir)
+	if err != nil {
+		log.Panicf("Error finding stale pages: %v", err)
+	}
+	for ii := range stalePages {
+		log.Printf("Stale page: %v", stalePages[ii].String())
+	}
+	log.Printf("Found %v stale pages.", len(stalePages))
+	for ii := range unknownPages {
+		log.Printf("Unknown page: %v", unknownPages[ii].String())
+	}
+	if len(unknownPages) > 0 {
+		log.Printf(
+			"Found %v watch pages that aren't in the database, run "+
+				"update-recent-movies to add them.",
+			len(unknownPages),
+		)
+	}
+	if archiveUnknown {
+		stalePages = append(stalePages, unknownPages...)
+	}
+	if !archive || len(stalePages) == 0 {
+		return
+	}
+	if err := ArchiveStalePages(vaultDir, stalePages); err != nil {
+		log.Panicf("Error archiving stale pages: %v", err)
+	}
+	rewritten, err := RewriteBacklinks(vaultDir, stalePages)
 	if err != nil {
-		log.Panicf("Unable to parse movie watch template: %v", err)
+		log.Panicf("Error rewriting backlinks: %v", err)
+	}
+	log.Printf(
+		"Archived %v stale pages and rewrote links in %v pages.",
+		len(stalePages), rewritten,
+	)
+}
+
+// countMovies is how many different movies the watches are for.
+func countMovies(movieWatches []database.GetAllMovieWatchesRow) int {
+	movies := make(map[string]bool)
+	for ii := range movieWatches {
+		movies[movieWatches[ii].MovieUuid] = true
 	}
-	movieTemplate, err := template.New("movie").Parse(MOVIE_TEMPLATE)
+	return len(movies)
+}
+
+// WriteWatchAndMoviePages writes a page for each watch and one page for each
+// movie watched, at most concurrency at a time, treating existing pages
+// according to the mode. It stops at the first error and returns it along
+// with what it did up to then.
+func WriteWatchAndMoviePages(
+	ctx context.Context,
+	queries *database.Queries,
+	taxonomy *GenreTaxonomy,
+	vaultNames VaultNames,
+	movieWatchTemplate *template.Template,
+	movieTemplate *template.Template,
+	watchesDir string,
+	moviesDir string,
+	movieWatches []database.GetAllMovieWatchesRow,
+	mode WriteMode,
+	concurrency int,
+	progress *ProgressBar,
+) (*PageCounts, error) {
+	counts := &PageCounts{}
+	moviePages, err := LoadMoviePages(ctx, queries, taxonomy, vaultNames)
 	if err != nil {
-		log.Panicf("Unable to parse movie template: %v", err)
+		return counts, err
+	}
+
+	writePage := func(
+		pageTemplate *template.Template, filePath string, page interface{},
+	) error {
+		defer progress.Add(1)
+		result, err := WritePageWithMode(pageTemplate, filePath, page, mode)
+		if err != nil {
+			return err
+		}
+		counts.Add(result, filePath)
+		return nil
 	}
-	var wg sync.WaitGroup
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(concurrency)
+	seenMovies := make(map[string]bool)
 	for ii := range movieWatches {
-		movieWatchPage := CreateMovieWatchPage(&movieWatches[ii])
-		// Step 2: If there's no movie watch page, create one.
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			filename := fmt.Sprintf(
-				"%v %v.md", movieWatchPage.Watched, movieWatchPage.FileTitle,
+		if groupCtx.Err() != nil {
+			break
+		}
+		movieWatchPage := CreateMovieWatchPage(&movieWatches[ii], vaultNames)
+		group.Go(func() error {
+			if groupCtx.Err() != nil {
+				return nil
+			}
+			return writePage(
+				movieWatchTemplate,
+				path.Join(watchesDir, MovieWatchPageFileName(movieWatchPage)),
+				movieWatchPage,
 			)
+		})
 
-			filePath := path.Join(watchesDir, filename)
-			file, skipWatch, err := createOrOpenFile(force, filePath)
-			if err != nil {
-				log.Panicf("Error creating or opening %v: %v", filePath, err)
-			}
-			defer file.Close()
-			if !skipWatch {
-				if err := movieWatchTemplate.Execute(
-					file, movieWatchPage,
-				); err != nil {
-					log.Panicf("Error writing movie watch page: %v", err)
-				}
-				file.Close()
+		// Movies watched more than once only get rendered once.
+		movieUuid := movieWatches[ii].MovieUuid
+		if seenMovies[movieUuid] {
+			continue
+		}
+		seenMovies[movieUuid] = true
+		moviePage, ok := moviePages[movieUuid]
+		if !ok {
+			group.Go(func() error {
+				return fmt.Errorf("no movie %v for %v", movieUuid, movieWatchPage.Title)
+			})
+			break
+		}
+		group.Go(func() error {
+			if groupCtx.Err() != nil {
+				return nil
 			}
-		}()
-		// Step 3: If there's no movie page, retrieve the movie and create one.
-		wg.Add(1)
-		go func(ii int) {
-			defer wg.Done()
-			moviePageFileName := fmt.Sprintf(
-				"%v (%v).md", movieWatchPage.FileTitle, movieWatchPage.ImdbId,
-			)
-			moviePageFilePath := path.Join(moviesDir, moviePageFileName)
-			moviePageFile, skipMovie, err := createOrOpenFile(
-				force, moviePageFilePath,
+			return writePage(
+				movieTemplate,
+				path.Join(
+					moviesDir,
+					MoviePageFileName(
+						movieWatchPage.FileTitle, movieWatchPage.ImdbId,
+					),
+				),
+				moviePage,
 			)
-			if err != nil {
-				log.Panicf(
-					"Error creating or opening %v: %v",
-					moviePageFilePath, err,
-				)
-			}
-			defer moviePageFile.Close()
-			if !skipMovie {
-				// Get directors for movie.
-				directors, err := queries.GetDirectorNamesForMovie(
-					ctx,
-					movieWatches[ii].MovieUuid,
-				)
-				if err != nil {
-					log.Panicf(
-						"Error getting directors for %v: %v",
-						movieWatches[ii].MovieTitle,
-						err,
-					)
-				}
-
-				// Get writers for movie.
-				writers, err := queries.GetWriterNamesForMovie(
-					ctx,
-					movieWatches[ii].MovieUuid,
-				)
-				if err != nil {
-					log.Panicf(
-						"Error getting writers for %v: %v",
-						movieWatches[ii].MovieTitle,
-						err,
-					)
-				}
-
-				// Get actors for movie.
-				actors, err := queries.GetActorNamesForMovie(
-					ctx,
-					movieWatches[ii].MovieUuid,
-				)
-				if err != nil {
-					log.Panicf(
-						"Error getting actors for %v: %v",
-						movieWatches[ii].MovieTitle,
-						err,
-					)
-				}
-
-				// Get genres for movie.
-				genres, err := queries.GetGenreNamesForMovie(
-					ctx,
-					movieWatches[ii].MovieUuid,
-				)
-				if err != nil {
-					log.Panicf(
-						"Error getting genres for %v: %v",
-						movieWatches[ii].MovieTitle,
-						err,
-					)
-				}
-
-				movieRow, err := queries.GetMovie(
-					ctx, movieWatches[ii].MovieUuid,
-				)
-				if err != nil {
-					log.Panicf(
-						"Error getting movie %v: %v",
-						movieWatches[ii].MovieTitle, err,
-					)
-				}
-				moviePage := CreateMoviePageFromRow(
-					&movieRow, genres, directors, writers, actors,
-				)
-				if err := movieTemplate.Execute(
-					moviePageFile, moviePage,
-				); err != nil {
-					log.Panicf(
-						"Error writing movie page %v: %v",
-						moviePageFilePath, err,
-					)
-				}
-				moviePageFile.Close()
-			}
-		}(ii)
+		})
 	}
-	wg.Wait()
+	err = group.Wait()
+	return counts, err
 }
 
-func cleanTitle(title string) string {
-	title = strings.TrimSpace(title)
-	title = strings.ReplaceAll(title, ":", "")
-	title = strings.ReplaceAll(title, "/", "")
-	title = strings.ReplaceAll(title, "\\", "")
-	title = strings.ReplaceAll(title, "#", "")
-	title = strings.ReplaceAll(title, "^", "")
-	title = strings.ReplaceAll(title, "[", "")
-	title = strings.ReplaceAll(title, "]", "")
-	title = strings.ReplaceAll(title, "|", "")
-
-	return title
+// WriteReviewPages writes a page for each review of the movies, replacing
+// existing pages only when force is set. It returns how many it wrote.
+func WriteReviewPages(
+	ctx context.Context,
+	queries *database.Queries,
+	vaultNames VaultNames,
+	reviewTemplate *template.Template,
+	reviewsDir string,
+	movieUuids map[string]bool,
+	force bool,
+) (int, error) {
+	reviews, err := queries.GetAllReviews(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error getting reviews: %v", err)
+	}
+	written := 0
+	for ii := range reviews {
+		if !movieUuids[reviews[ii].MovieUuid] {
+			continue
+		}
+		reviewRow := database.GetReviewRow(reviews[ii])
+		reviewPage := CreateMovieReviewPage(&reviewRow, vaultNames)
+		filePath := path.Join(reviewsDir, ReviewPageFileName(reviewPage))
+		if force {
+			if err := WritePage(reviewTemplate, filePath, reviewPage); err != nil {
+				return written, err
+			}
+			written++
+			continue
+		}
+		created, err := WriteNewPage(reviewTemplate, filePath, reviewPage)
+		if err != nil {
+			return written, err
+		}
+		if created {
+			written++
+		}
+	}
+	return written, nil
 }
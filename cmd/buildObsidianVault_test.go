@@ -1,9 +1,16 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"strings"
 	"testing"
+	"text/template"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
 )
 
 func TestCleanTitle(t *testing.T) {
@@ -20,4 +27,310 @@ func TestCleanTitle(t *testing.T) {
 	if !cmp.Equal(truth, answer) {
 		t.Errorf("Expected %v, got %v", truth, answer)
 	}
+
+	title = "Mother! :"
+	truth = "Mother!"
+	answer = cleanTitle(title)
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected %v, got %v", truth, answer)
+	}
+}
+
+func TestWriteReviewPages(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	reviewsDir := t.TempDir()
+
+	movieDetails, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := queries.InsertMovieWatch(ctx, *CreateInsertMovieWatchParams(
+		sampleMovieWatchPage(), movieDetails.Movie,
+	)); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	reviews := []MovieReviewPage{
+		{MovieTitle: "Tenebrae", ImdbId: "tt0084777", Review: "Sharp."},
+		{
+			MovieTitle: "Tenebrae", ImdbId: "tt0084777", Watched: "2022-05-27",
+			Liked: true, Review: "Sharper.", Rating: 4.5,
+		},
+	}
+	for ii := range reviews {
+		if _, err := SaveReview(db, ctx, queries, &reviews[ii]); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+
+	reviewTemplate, err := template.New("review").Parse(REVIEW_TEMPLATE)
+	if err != nil {
+		t.Fatalf("Error parsing template: %v", err)
+	}
+	vaultNames, err := LoadVaultNames(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	movieUuids := map[string]bool{movieDetails.Movie: true}
+	written, err := WriteReviewPages(
+		ctx, queries, vaultNames, reviewTemplate, reviewsDir, movieUuids, false,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if written != 2 {
+		t.Errorf("Expected 2 pages written, got %v", written)
+	}
+
+	// The pages parse back to what went in.
+	parser, err := CreateMovieReviewParser()
+	if err != nil {
+		t.Fatalf("Error creating parser: %v", err)
+	}
+	for ii := range reviews {
+		answer, err := parser.ParseMovieReviewPage(
+			path.Join(reviewsDir, ReviewPageFileName(&reviews[ii])),
+		)
+		if err != nil {
+			t.Fatalf("Error parsing page: %v", err)
+		}
+		if !cmp.Equal(reviews[ii], *answer) {
+			t.Errorf("Expected \n%v, got \n%v", reviews[ii], *answer)
+		}
+	}
+
+	// Existing pages are left alone unless forced.
+	pagePath := path.Join(reviewsDir, ReviewPageFileName(&reviews[0]))
+	if err := os.WriteFile(pagePath, []byte("edited"), 0644); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	written, err = WriteReviewPages(
+		ctx, queries, vaultNames, reviewTemplate, reviewsDir, movieUuids, false,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if written != 0 {
+		t.Errorf("Expected no pages written, got %v", written)
+	}
+	written, err = WriteReviewPages(
+		ctx, queries, vaultNames, reviewTemplate, reviewsDir, movieUuids, true,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if written != 2 {
+		t.Errorf("Expected 2 pages written, got %v", written)
+	}
+	written, err = WriteReviewPages(
+		ctx, queries, vaultNames, reviewTemplate, reviewsDir, map[string]bool{}, true,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if written != 0 {
+		t.Errorf("Expected no pages for other movies, got %v", written)
+	}
+
+	// The movie page links to both.
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	moviePage, err := GetMoviePage(ctx, queries, taxonomy, movieDetails.Movie)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	linksTruth := []string{
+		"Tenebrae (tt0084777) Review",
+		"Tenebrae (tt0084777) Review 2022-05-27",
+	}
+	if !cmp.Equal(linksTruth, moviePage.Reviews) {
+		t.Errorf("Expected %v, got %v", linksTruth, moviePage.Reviews)
+	}
+}
+
+func TestWriteWatchAndMoviePages(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	watchesDir := t.TempDir()
+	moviesDir := t.TempDir()
+
+	tenebrae, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	suspiria := sampleMoviePage()
+	suspiria.Title = "Suspiria"
+	suspiria.ImdbLink = "https://www.imdb.com/title/tt0076786/"
+	suspiria.Genres = []string{"Horror"}
+	suspiriaDetails, err := InsertMovieDetails(db, ctx, queries, suspiria, nil)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	for _, watch := range []struct {
+		movieUuid string
+		title     string
+		imdbId    string
+		watched   string
+	}{
+		{tenebrae.Movie, "Tenebrae", "tt0084777", "2022-05-27"},
+		{tenebrae.Movie, "Tenebrae", "tt0084777", "2022-10-31"},
+		{tenebrae.Movie, "Tenebrae", "tt0084777", "2023-01-01"},
+		{suspiriaDetails.Movie, "Suspiria", "tt0076786", "2022-10-31"},
+	} {
+		movieWatch := sampleMovieWatchPage()
+		movieWatch.Title = watch.title
+		movieWatch.ImdbId = watch.imdbId
+		movieWatch.Watched = watch.watched
+		if err := queries.InsertMovieWatch(
+			ctx, *CreateInsertMovieWatchParams(movieWatch, watch.movieUuid),
+		); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+	movieWatches, err := queries.GetAllMovieWatches(ctx)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	movieWatchTemplate, err := template.New("movie_watch").Parse(
+		MOVIE_WATCH_TEMPLATE,
+	)
+	if err != nil {
+		t.Fatalf("Error parsing template: %v", err)
+	}
+	movieTemplate, err := template.New("movie").Parse(MOVIE_TEMPLATE)
+	if err != nil {
+		t.Fatalf("Error parsing template: %v", err)
+	}
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	vaultNames, err := LoadVaultNames(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	progress := &ProgressBar{total: len(movieWatches) + 2}
+	counts, err := WriteWatchAndMoviePages(
+		ctx, queries, taxonomy, vaultNames, movieWatchTemplate, movieTemplate, watchesDir, moviesDir,
+		movieWatches, WRITE_MISSING, 2, progress,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if counts.Created != 6 || counts.Written() != 6 {
+		t.Errorf("Expected 6 pages created, got %v", counts)
+	}
+	if progress.done != 6 {
+		t.Errorf("Expected 6 steps done, got %v", progress.done)
+	}
+
+	// The batched movie pages are the same as loading them one at a time.
+	for _, movie := range []struct {
+		uuid   string
+		imdbId string
+	}{
+		{tenebrae.Movie, "tt0084777"},
+		{suspiriaDetails.Movie, "tt0076786"},
+	} {
+		moviePage, err := GetMoviePage(ctx, queries, taxonomy, movie.uuid)
+		if err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		var truth bytes.Buffer
+		if err := movieTemplate.Execute(&truth, moviePage); err != nil {
+			t.Fatalf("Error executing template: %v", err)
+		}
+		answer, err := os.ReadFile(path.Join(
+			moviesDir,
+			MoviePageFileName(cleanTitle(moviePage.Title), movie.imdbId),
+		))
+		if err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		if truth.String() != string(answer) {
+			t.Errorf("Expected \n%v, got \n%v", truth.String(), string(answer))
+		}
+	}
+
+	// Nothing is rewritten unless forced.
+	counts, err = WriteWatchAndMoviePages(
+		ctx, queries, taxonomy, vaultNames, movieWatchTemplate, movieTemplate, watchesDir, moviesDir,
+		movieWatches, WRITE_MISSING, 2, nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if counts.Unchanged != 6 || counts.Written() != 0 {
+		t.Errorf("Expected no pages written, got %v", counts)
+	}
+	counts, err = WriteWatchAndMoviePages(
+		ctx, queries, taxonomy, vaultNames, movieWatchTemplate, movieTemplate, watchesDir, moviesDir,
+		movieWatches, WRITE_FORCE, 1, nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if counts.Updated != 6 {
+		t.Errorf("Expected 6 pages updated, got %v", counts)
+	}
+
+	// Merging fixes the generated sections and keeps the rest.
+	watchPath := path.Join(watchesDir, "2022-10-31 Suspiria.md")
+	watchPage, err := os.ReadFile(watchPath)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	edited := strings.Replace(
+		string(watchPage), "service:: Shudder", "service:: VHS", 1,
+	) + "Edited notes.\n\n## Mine\nStays.\n"
+	if err := os.WriteFile(watchPath, []byte(edited), 0644); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	conflictPath := path.Join(moviesDir, "Suspiria (tt0076786).md")
+	if err := os.WriteFile(
+		conflictPath, []byte("# Suspiria\nNo sections.\n"), 0644,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	counts, err = WriteWatchAndMoviePages(
+		ctx, queries, taxonomy, vaultNames, movieWatchTemplate, movieTemplate, watchesDir, moviesDir,
+		movieWatches, WRITE_MERGE, 3, nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if counts.Updated != 1 || counts.Unchanged != 4 || counts.Conflicted != 1 ||
+		!cmp.Equal([]string{conflictPath}, counts.Conflicts) {
+		t.Errorf("Unexpected merge %v %v", counts, counts.Conflicts)
+	}
+	merged, err := os.ReadFile(watchPath)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	mergedTruth := string(watchPage) + "Edited notes.\n\n## Mine\nStays.\n"
+	if string(merged) != mergedTruth {
+		t.Errorf("Expected \n%v, got \n%v", mergedTruth, string(merged))
+	}
+
+	// Errors come back instead of taking down the process.
+	_, err = WriteWatchAndMoviePages(
+		ctx, queries, taxonomy, vaultNames, movieWatchTemplate, movieTemplate, watchesDir,
+		path.Join(moviesDir, "missing"), movieWatches, WRITE_FORCE, 4, nil,
+	)
+	if err == nil {
+		t.Errorf("Expected an error writing to a missing directory")
+	}
 }
@@ -0,0 +1,551 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// buildSiteCmd represents the buildSite command
+var buildSiteCmd = &cobra.Command{
+	Use:   "build-site <outdir>",
+	Short: "Builds a static HTML movie diary from the movies database.",
+	Long: `Builds a static HTML movie diary from the movies database: the diary,
+a films grid, a page per film, reviews and stats for each year.
+
+Rebuilds are incremental. Film pages are only rendered again when a movie,
+watch or review row has been added for the film or one of its watches or
+reviews has changed since the last build, and pages are only written when
+their content changed.`,
+	Run:  buildSite,
+	Args: cobra.ExactArgs(1),
+}
+
+func init() {
+	rootCmd.AddCommand(buildSiteCmd)
+
+	buildSiteCmd.Flags().BoolP(
+		"force", "f", false, "Whether to force rebuild the whole site or not.",
+	)
+	buildSiteCmd.Flags().Bool(
+		"private-notes", true,
+		"Whether to include watch notes. Use --private-notes=false for a public site.",
+	)
+	buildSiteCmd.Flags().IntP(
+		"top", "n", 10, "The number of directors and actors in the stats.",
+	)
+}
+
+var SITE_LAYOUT_TEMPLATE = `{{define "layout"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} | Movie Diary</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+<header>
+<a href="{{.Root}}index.html">Diary</a>
+<a href="{{.Root}}films/index.html">Films</a>
+<a href="{{.Root}}reviews/index.html">Reviews</a>
+<a href="{{.Root}}stats/index.html">Stats</a>
+</header>
+<main>
+{{template "content" .}}
+</main>
+</body>
+</html>
+{{end}}`
+
+var SITE_DIARY_TEMPLATE = `{{define "content"}}<h1>Diary</h1>
+{{range .Content}}<h2>{{.Name}}</h2>
+<table>
+{{range .Watches}}<tr>
+<td class="day">{{.Day}}</td>
+<td><a href="{{$.Root}}films/{{.ImdbId}}.html">{{.Title}}</a> <span class="muted">{{.Year}}</span></td>
+<td>{{.Service}}</td>
+<td>{{if not .FirstTime}}rewatch{{end}}</td>
+</tr>
+{{end}}</table>
+{{end}}{{end}}`
+
+var SITE_FILMS_TEMPLATE = `{{define "content"}}<h1>Films</h1>
+<ul class="grid">
+{{range .Content}}<li><a href="{{$.Root}}films/{{.ImdbID}}.html">{{if .Poster.Valid}}<img src="{{.Poster.String}}" alt="{{.Title}}" loading="lazy">{{else}}<span class="no-poster">{{.Title}}</span>{{end}}</a>
+<span>{{.Title}} <span class="muted">{{.Year}}</span></span></li>
+{{end}}</ul>
+{{end}}`
+
+var SITE_FILM_TEMPLATE = `{{define "content"}}{{with .Content}}{{with .Movie}}{{if .Poster}}<img class="poster" src="{{.Poster}}" alt="Poster for {{.Title}}">
+{{end}}<h1>{{.Title}} <span class="muted">{{.Year}}</span></h1>
+<p class="muted">{{if .Rated}}{{.Rated}} &middot; {{end}}{{if .RuntimeMinutes}}{{.RuntimeMinutes}} min &middot; {{end}}{{range $ii, $genre := .Genres}}{{if $ii}}, {{end}}{{$genre}}{{end}}</p>
+{{if .Plot}}<p>{{.Plot}}</p>
+{{end}}<table>
+<tr><th>Directed by</th><td>{{range $ii, $name := .Directors}}{{if $ii}}, {{end}}{{$name}}{{end}}</td></tr>
+<tr><th>Written by</th><td>{{range $ii, $name := .Writers}}{{if $ii}}, {{end}}{{$name}}{{end}}</td></tr>
+<tr><th>Starring</th><td>{{range $ii, $name := .Actors}}{{if $ii}}, {{end}}{{$name}}{{end}}</td></tr>
+{{range .Ratings}}<tr><th>{{.Source}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+<p><a href="https://www.imdb.com/title/{{.ImdbId}}/">IMDB</a></p>
+{{end}}<h2>Watches</h2>
+<table>
+{{range .Watches}}<tr>
+<td>{{.Watched}}</td>
+<td>{{.Service}}</td>
+<td>{{if .FirstTime}}first time{{else}}rewatch{{end}}</td>
+</tr>
+{{if .Notes.Valid}}<tr><td></td><td colspan="2">{{.Notes.String}}</td></tr>
+{{end}}{{end}}</table>
+{{if .Reviews}}<h2>Reviews</h2>
+{{range .Reviews}}<p class="muted">{{with .Watched.String}}{{.}} &middot; {{end}}{{if .Liked}}Liked it.{{else}}Didn't like it.{{end}}{{if .Rating.Valid}} {{.Rating.Float64}} stars.{{end}}</p>
+<p>{{.Review}}</p>
+{{end}}{{end}}{{end}}{{end}}`
+
+var SITE_REVIEWS_TEMPLATE = `{{define "content"}}<h1>Reviews</h1>
+{{range .Content}}<article>
+<h2><a href="{{$.Root}}films/{{.ImdbID}}.html">{{.Title}}</a> <span class="muted">{{.Year}}</span></h2>
+<p class="muted">{{if .Liked}}Liked it.{{else}}Didn't like it.{{end}}</p>
+<p>{{.Review}}</p>
+</article>
+{{else}}<p>No reviews yet.</p>
+{{end}}{{end}}`
+
+var SITE_STATS_TEMPLATE = `{{define "content"}}<h1>Stats</h1>
+<table>
+{{range .Content}}<tr><td><a href="{{$.Root}}stats/{{.Year}}.html">{{.Year}}</a></td><td>{{.NumWatches}} watches</td></tr>
+{{end}}</table>
+{{end}}`
+
+var SITE_YEAR_TEMPLATE = `{{define "content"}}{{with .Content}}<h1>{{.Year}}</h1>
+<p>{{.TotalWatches}} watches, {{.FirstTimeWatches}} for the first time and {{.Rewatches}} rewatches.</p>
+<h2>By Month</h2>
+<table>
+{{range .Months}}<tr><td>{{.Month}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+<h2>Most Watched Directors</h2>
+<table>
+{{range .Directors}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+<h2>Most Watched Actors</h2>
+<table>
+{{range .Actors}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+{{with .Longest}}<p>Longest: <a href="{{$.Root}}films/{{.ImdbId}}.html">{{.Title}}</a> ({{.RuntimeMinutes}} min)</p>
+{{end}}{{with .Shortest}}<p>Shortest: <a href="{{$.Root}}films/{{.ImdbId}}.html">{{.Title}}</a> ({{.RuntimeMinutes}} min)</p>
+{{end}}{{if .Liked}}<h2>Liked</h2>
+<ul>
+{{range .Liked}}<li><a href="{{$.Root}}films/{{.ImdbId}}.html">{{.Title}}</a></li>
+{{end}}</ul>
+{{end}}{{end}}{{end}}`
+
+var SITE_STYLESHEET = `body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 0 auto; padding: 1rem; line-height: 1.5; }
+header { display: flex; gap: 1rem; border-bottom: 1px solid #ccc; padding-bottom: 0.5rem; }
+a { color: #8b0000; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: 0.25rem 0.5rem 0.25rem 0; vertical-align: top; }
+.day { width: 2rem; }
+.muted { color: #666; }
+.poster { float: right; max-width: 14rem; margin: 0 0 1rem 1rem; }
+.grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(8rem, 1fr)); gap: 1rem; list-style: none; padding: 0; }
+.grid img, .grid .no-poster { display: block; width: 100%; aspect-ratio: 2 / 3; object-fit: cover; background: #eee; }
+`
+
+var siteTemplates = map[string]*template.Template{
+	"diary":   parseSiteTemplate("diary", SITE_DIARY_TEMPLATE),
+	"films":   parseSiteTemplate("films", SITE_FILMS_TEMPLATE),
+	"film":    parseSiteTemplate("film", SITE_FILM_TEMPLATE),
+	"reviews": parseSiteTemplate("reviews", SITE_REVIEWS_TEMPLATE),
+	"stats":   parseSiteTemplate("stats", SITE_STATS_TEMPLATE),
+	"year":    parseSiteTemplate("year", SITE_YEAR_TEMPLATE),
+}
+
+func parseSiteTemplate(name string, content string) *template.Template {
+	return template.Must(
+		template.Must(template.New(name).Parse(SITE_LAYOUT_TEMPLATE)).Parse(
+			content,
+		),
+	)
+}
+
+type sitePage struct {
+	Title string
+	// The relative path back to the top of the site, so it can be served
+	// from anywhere or opened straight off the disk.
+	Root    string
+	Content interface{}
+}
+
+type SiteDiaryWatch struct {
+	Watched   string
+	Day       string
+	Title     string
+	ImdbId    string
+	Year      int64
+	Service   string
+	FirstTime bool
+}
+
+type SiteDiaryMonth struct {
+	Name    string
+	Watches []SiteDiaryWatch
+}
+
+// CreateSiteDiary groups the watches by month, newest first.
+func CreateSiteDiary(
+	watches []database.GetAllMovieWatchesRow,
+	movies []database.ListWatchedMoviesRow,
+) ([]SiteDiaryMonth, error) {
+	years := make(map[string]int64)
+	for ii := range movies {
+		years[movies[ii].Uuid] = movies[ii].Year
+	}
+	sorted := make([]database.GetAllMovieWatchesRow, len(watches))
+	copy(sorted, watches)
+	sort.SliceStable(sorted, func(ii, jj int) bool {
+		if sorted[ii].Watched != sorted[jj].Watched {
+			return sorted[ii].Watched > sorted[jj].Watched
+		}
+		return sorted[ii].MovieTitle < sorted[jj].MovieTitle
+	})
+
+	months := make([]SiteDiaryMonth, 0)
+	for ii := range sorted {
+		watched, err := ParseWatchedDate(sorted[ii].Watched)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error parsing watched date %v for %v: %v",
+				sorted[ii].Watched, sorted[ii].MovieTitle, err,
+			)
+		}
+		name := watched.Format("January 2006")
+		if len(months) == 0 || months[len(months)-1].Name != name {
+			months = append(months, SiteDiaryMonth{Name: name})
+		}
+		month := &months[len(months)-1]
+		month.Watches = append(month.Watches, SiteDiaryWatch{
+			Watched:   sorted[ii].Watched,
+			Day:       strconv.Itoa(watched.Day()),
+			Title:     sorted[ii].MovieTitle,
+			ImdbId:    sorted[ii].ImdbID,
+			Year:      years[sorted[ii].MovieUuid],
+			Service:   sorted[ii].Service,
+			FirstTime: sorted[ii].FirstTime != 0,
+		})
+	}
+	return months, nil
+}
+
+const SITE_MANIFEST_FILE = ".build-site.json"
+
+type SiteManifestPage struct {
+	Hash string `json:"hash"`
+	// Film pages are rendered again when either of these change.
+	LatestCreatedDatetime int64 `json:"latest_created_datetime,omitempty"`
+	NumWatches            int64 `json:"num_watches,omitempty"`
+}
+
+type SiteManifest struct {
+	PrivateNotes bool                        `json:"private_notes"`
+	Pages        map[string]SiteManifestPage `json:"pages"`
+}
+
+// ReadSiteManifest reads the manifest from the last build, which is empty if
+// there wasn't one.
+func ReadSiteManifest(outDir string) (*SiteManifest, error) {
+	manifest := SiteManifest{Pages: make(map[string]SiteManifestPage)}
+	manifestBytes, err := os.ReadFile(filepath.Join(outDir, SITE_MANIFEST_FILE))
+	if errors.Is(err, os.ErrNotExist) {
+		return &manifest, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading manifest: %v", err)
+	}
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("error parsing manifest: %v", err)
+	}
+	if manifest.Pages == nil {
+		manifest.Pages = make(map[string]SiteManifestPage)
+	}
+	return &manifest, nil
+}
+
+type SiteBuilder struct {
+	OutDir       string
+	PrivateNotes bool
+	Top          int
+	Written      int
+	Unchanged    int
+	Removed      int
+	previous     *SiteManifest
+	current      *SiteManifest
+}
+
+func NewSiteBuilder(
+	outDir string, privateNotes bool, force bool, top int,
+) (*SiteBuilder, error) {
+	previous, err := ReadSiteManifest(outDir)
+	if err != nil {
+		return nil, err
+	}
+	// Every film page changes when the notes come or go.
+	if force || previous.PrivateNotes != privateNotes {
+		previous.Pages = make(map[string]SiteManifestPage)
+	}
+	return &SiteBuilder{
+		OutDir:       outDir,
+		PrivateNotes: privateNotes,
+		Top:          top,
+		previous:     previous,
+		current: &SiteManifest{
+			PrivateNotes: privateNotes,
+			Pages:        make(map[string]SiteManifestPage),
+		},
+	}, nil
+}
+
+func (b *SiteBuilder) exists(relPath string) bool {
+	_, err := os.Stat(filepath.Join(b.OutDir, relPath))
+	return err == nil
+}
+
+// writeFile only touches the file if the content changed since the last
+// build.
+func (b *SiteBuilder) writeFile(
+	relPath string, content []byte, entry SiteManifestPage,
+) error {
+	hash := sha256.Sum256(content)
+	entry.Hash = hex.EncodeToString(hash[:])
+	b.current.Pages[relPath] = entry
+
+	if previous, ok := b.previous.Pages[relPath]; ok &&
+		previous.Hash == entry.Hash && b.exists(relPath) {
+		b.Unchanged += 1
+		return nil
+	}
+	filePath := filepath.Join(b.OutDir, relPath)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("error creating %v: %v", filepath.Dir(filePath), err)
+	}
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		return fmt.Errorf("error writing %v: %v", filePath, err)
+	}
+	b.Written += 1
+	return nil
+}
+
+func (b *SiteBuilder) renderPage(
+	relPath string,
+	name string,
+	title string,
+	content interface{},
+	entry SiteManifestPage,
+) error {
+	page := sitePage{
+		Title:   title,
+		Root:    strings.Repeat("../", strings.Count(relPath, "/")),
+		Content: content,
+	}
+	var body bytes.Buffer
+	if err := siteTemplates[name].ExecuteTemplate(
+		&body, "layout", &page,
+	); err != nil {
+		return fmt.Errorf("error rendering %v: %v", relPath, err)
+	}
+	return b.writeFile(relPath, body.Bytes(), entry)
+}
+
+// buildFilm skips the queries and rendering for films nothing has been added
+// to since the last build.
+func (b *SiteBuilder) buildFilm(
+	ctx context.Context,
+	queries *database.Queries,
+	movie *database.ListWatchedMoviesRow,
+) error {
+	relPath := fmt.Sprintf("films/%v.html", movie.ImdbID)
+	entry := SiteManifestPage{
+		LatestCreatedDatetime: movie.LatestCreatedDatetime,
+		NumWatches:            movie.NumWatches,
+	}
+	if previous, ok := b.previous.Pages[relPath]; ok &&
+		previous.LatestCreatedDatetime == entry.LatestCreatedDatetime &&
+		previous.NumWatches == entry.NumWatches && b.exists(relPath) {
+		b.current.Pages[relPath] = previous
+		b.Unchanged += 1
+		return nil
+	}
+
+	page, err := CreateUiMoviePage(ctx, queries, movie.ImdbID)
+	if err != nil {
+		return fmt.Errorf("error getting film %v: %v", movie.ImdbID, err)
+	}
+	if !b.PrivateNotes {
+		for ii := range page.Watches {
+			page.Watches[ii].Notes = sql.NullString{}
+		}
+	}
+	return b.renderPage(relPath, "film", page.Movie.Title, page, entry)
+}
+
+func (b *SiteBuilder) Build(
+	ctx context.Context, queries *database.Queries,
+) error {
+	movies, err := queries.ListWatchedMovies(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting movies: %v", err)
+	}
+	watches, err := queries.GetAllMovieWatches(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting movie watches: %v", err)
+	}
+	reviews, err := queries.ListReviews(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting reviews: %v", err)
+	}
+	years, err := queries.GetWatchCountsByYear(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting years: %v", err)
+	}
+	log.Printf(
+		"Building site for %v watches of %v movies.", len(watches), len(movies),
+	)
+
+	if err := b.writeFile(
+		"style.css", []byte(SITE_STYLESHEET), SiteManifestPage{},
+	); err != nil {
+		return err
+	}
+
+	diary, err := CreateSiteDiary(watches, movies)
+	if err != nil {
+		return fmt.Errorf("error creating diary: %v", err)
+	}
+	if err := b.renderPage(
+		"index.html", "diary", "Diary", diary, SiteManifestPage{},
+	); err != nil {
+		return err
+	}
+
+	if err := b.renderPage(
+		"films/index.html", "films", "Films", movies, SiteManifestPage{},
+	); err != nil {
+		return err
+	}
+	for ii := range movies {
+		if err := b.buildFilm(ctx, queries, &movies[ii]); err != nil {
+			return err
+		}
+	}
+
+	if err := b.renderPage(
+		"reviews/index.html", "reviews", "Reviews", reviews, SiteManifestPage{},
+	); err != nil {
+		return err
+	}
+
+	if err := b.renderPage(
+		"stats/index.html", "stats", "Stats", years, SiteManifestPage{},
+	); err != nil {
+		return err
+	}
+	for ii := range years {
+		year, err := strconv.Atoi(years[ii].Year)
+		if err != nil {
+			return fmt.Errorf("error parsing year %v: %v", years[ii].Year, err)
+		}
+		yearPage, err := GetYearReviewPage(ctx, queries, year, b.Top)
+		if err != nil {
+			return fmt.Errorf("error getting stats for %v: %v", year, err)
+		}
+		if err := b.renderPage(
+			fmt.Sprintf("stats/%v.html", year), "year", years[ii].Year,
+			yearPage, SiteManifestPage{},
+		); err != nil {
+			return err
+		}
+	}
+
+	return b.finish()
+}
+
+// finish removes pages that were in the last build but not this one, like
+// films whose watches were all deleted, then saves the manifest.
+func (b *SiteBuilder) finish() error {
+	for relPath := range b.previous.Pages {
+		if _, ok := b.current.Pages[relPath]; ok {
+			continue
+		}
+		err := os.Remove(filepath.Join(b.OutDir, relPath))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error removing %v: %v", relPath, err)
+		}
+		b.Removed += 1
+	}
+	manifestBytes, err := json.MarshalIndent(b.current, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding manifest: %v", err)
+	}
+	if err := os.WriteFile(
+		filepath.Join(b.OutDir, SITE_MANIFEST_FILE), manifestBytes, 0644,
+	); err != nil {
+		return fmt.Errorf("error writing manifest: %v", err)
+	}
+	return nil
+}
+
+func buildSite(cmd *cobra.Command, args []string) {
+	outDir := args[0]
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		log.Panicf("Error obtaining force value: %v", err)
+	}
+	privateNotes, err := cmd.Flags().GetBool("private-notes")
+	if err != nil {
+		log.Panicf("Error obtaining private-notes value: %v", err)
+	}
+	top, err := cmd.Flags().GetInt("top")
+	if err != nil {
+		log.Panicf("Error obtaining top: %v", err)
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		log.Panicf("Error creating %v: %v", outDir, err)
+	}
+	builder, err := NewSiteBuilder(outDir, privateNotes, force, top)
+	if err != nil {
+		log.Panicf("Error reading the last build: %v", err)
+	}
+	if err := builder.Build(ctx, queries); err != nil {
+		log.Panicf("Error building site: %v", err)
+	}
+	log.Printf(
+		"Wrote %v pages, %v unchanged, %v removed.",
+		builder.Written, builder.Unchanged, builder.Removed,
+	)
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestCreateSiteDiary(t *testing.T) {
+	watches := []database.GetAllMovieWatchesRow{
+		{
+			MovieUuid: "abc", MovieTitle: "Tenebrae", ImdbID: "tt0084777",
+			Watched: "2022-05-27", Service: "Shudder", FirstTime: 1,
+		},
+		{
+			MovieUuid: "def", MovieTitle: "Things", ImdbID: "tt0098472",
+			Watched: "2022-10-31", Service: "Tubi",
+		},
+		{
+			MovieUuid: "abc", MovieTitle: "Tenebrae", ImdbID: "tt0084777",
+			Watched: "2022-10-02", Service: "Shudder",
+		},
+	}
+	movies := []database.ListWatchedMoviesRow{
+		{Uuid: "abc", Year: 1982}, {Uuid: "def", Year: 1989},
+	}
+	truth := []SiteDiaryMonth{
+		{
+			Name: "October 2022",
+			Watches: []SiteDiaryWatch{
+				{
+					Watched: "2022-10-31", Day: "31", Title: "Things",
+					ImdbId: "tt0098472", Year: 1989, Service: "Tubi",
+				},
+				{
+					Watched: "2022-10-02", Day: "2", Title: "Tenebrae",
+					ImdbId: "tt0084777", Year: 1982, Service: "Shudder",
+				},
+			},
+		},
+		{
+			Name: "May 2022",
+			Watches: []SiteDiaryWatch{
+				{
+					Watched: "2022-05-27", Day: "27", Title: "Tenebrae",
+					ImdbId: "tt0084777", Year: 1982, Service: "Shudder",
+					FirstTime: true,
+				},
+			},
+		},
+	}
+	answer, err := CreateSiteDiary(watches, movies)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected %v, got %v", truth, answer)
+	}
+}
+
+func buildTestSite(
+	t *testing.T,
+	queries *database.Queries,
+	outDir string,
+	privateNotes bool,
+) *SiteBuilder {
+	builder, err := NewSiteBuilder(outDir, privateNotes, false, 10)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := builder.Build(context.Background(), queries); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	return builder
+}
+
+func TestBuildSite(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	movieDetails, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	movieWatch := sampleMovieWatchPage()
+	movieWatch.Notes = "Keep this one to myself."
+	if err := queries.InsertMovieWatch(
+		ctx, *CreateInsertMovieWatchParams(movieWatch, movieDetails.Movie),
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	outDir := t.TempDir()
+	builder := buildTestSite(t, queries, outDir, true)
+	// Style, diary, films, one film, reviews, stats and one year.
+	if builder.Written != 7 || builder.Unchanged != 0 {
+		t.Errorf(
+			"Expected 7 written, got %v written and %v unchanged",
+			builder.Written, builder.Unchanged,
+		)
+	}
+	filmPath := filepath.Join(outDir, "films", "tt0084777.html")
+	filmBytes, err := os.ReadFile(filmPath)
+	if err != nil {
+		t.Fatalf("Expected film page: %v", err)
+	}
+	if !strings.Contains(string(filmBytes), "Keep this one to myself.") ||
+		!strings.Contains(string(filmBytes), `href="../style.css"`) {
+		t.Errorf("Unexpected film page %v", string(filmBytes))
+	}
+	diaryBytes, err := os.ReadFile(filepath.Join(outDir, "index.html"))
+	if err != nil {
+		t.Fatalf("Expected diary: %v", err)
+	}
+	if !strings.Contains(string(diaryBytes), `href="films/tt0084777.html"`) {
+		t.Errorf("Unexpected diary %v", string(diaryBytes))
+	}
+
+	// Nothing changed, so nothing gets written.
+	builder = buildTestSite(t, queries, outDir, true)
+	if builder.Written != 0 || builder.Unchanged != 7 {
+		t.Errorf(
+			"Expected 7 unchanged, got %v written and %v unchanged",
+			builder.Written, builder.Unchanged,
+		)
+	}
+
+	// Editing the watch rebuilds its film page, the trigger moves the
+	// updated time past when the rows were created.
+	for _, table := range []string{"movie", "movie_watch"} {
+		if _, err := db.Exec(
+			"UPDATE " + table + " SET created_datetime = created_datetime - 60",
+		); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+	buildTestSite(t, queries, outDir, true)
+	if _, err := db.Exec(
+		"UPDATE movie_watch SET notes = 'Changed my mind.'",
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	buildTestSite(t, queries, outDir, true)
+	filmBytes, err = os.ReadFile(filmPath)
+	if err != nil {
+		t.Fatalf("Expected film page: %v", err)
+	}
+	if !strings.Contains(string(filmBytes), "Changed my mind.") {
+		t.Errorf("Expected the edited notes in %v", string(filmBytes))
+	}
+
+	// Leaving the notes out rebuilds the film pages without them.
+	buildTestSite(t, queries, outDir, false)
+	filmBytes, err = os.ReadFile(filmPath)
+	if err != nil {
+		t.Fatalf("Expected film page: %v", err)
+	}
+	if strings.Contains(string(filmBytes), "Changed my mind.") {
+		t.Errorf("Expected no notes in %v", string(filmBytes))
+	}
+
+	// Deleting the only watch takes the film page with it.
+	watchUuid, err := queries.FindMovieWatch(
+		ctx, database.FindMovieWatchParams{
+			ImdbID: movieWatch.ImdbId, Watched: movieWatch.Watched,
+		},
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := queries.DeleteMovieWatch(ctx, watchUuid); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	builder = buildTestSite(t, queries, outDir, false)
+	if builder.Removed != 2 {
+		t.Errorf("Expected 2 removed, got %v", builder.Removed)
+	}
+	if _, err := os.Stat(filmPath); !os.IsNotExist(err) {
+		t.Errorf("Expected %v to be removed", filmPath)
+	}
+}
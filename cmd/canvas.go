@@ -0,0 +1,466 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// Sizes on the canvas, in Obsidian's canvas units.
+const CANVAS_NODE_WIDTH = 400
+const CANVAS_NODE_HEIGHT = 300
+
+// How far apart the layout tries to keep connected movies. It has to clear
+// the node size or pages end up on top of each other.
+const CANVAS_NODE_SPACING = 700
+
+const CANVAS_LAYOUT_ITERATIONS = 500
+
+// How many shared credits an edge label names before it says how many more.
+const CANVAS_EDGE_LABEL_CREDITS = 3
+
+// The order roles go in on an edge label.
+var CANVAS_ROLES = []string{DIRECTOR_ROLE, WRITER_ROLE, ACTOR_ROLE}
+
+// The flags --flag takes, by their names on the watch pages.
+var CANVAS_FLAGS = map[string]func(*MovieWatchPage) bool{
+	"joe_bob":      func(p *MovieWatchPage) bool { return p.JoeBob },
+	"slasher":      func(p *MovieWatchPage) bool { return p.Slasher },
+	"call_felissa": func(p *MovieWatchPage) bool { return p.CallFelissa },
+	"beast":        func(p *MovieWatchPage) bool { return p.Beast },
+	"godzilla":     func(p *MovieWatchPage) bool { return p.Godzilla },
+	"zombies":      func(p *MovieWatchPage) bool { return p.Zombies },
+	"wallpaper_fu": func(p *MovieWatchPage) bool { return p.WallpaperFu },
+}
+
+// Canvas is an Obsidian .canvas file, see https://jsoncanvas.org.
+type Canvas struct {
+	Nodes []CanvasNode `json:"nodes"`
+	Edges []CanvasEdge `json:"edges"`
+}
+
+type CanvasNode struct {
+	Id     string `json:"id"`
+	Type   string `json:"type"`
+	File   string `json:"file"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type CanvasEdge struct {
+	Id       string `json:"id"`
+	FromNode string `json:"fromNode"`
+	ToNode   string `json:"toNode"`
+	Label    string `json:"label,omitempty"`
+}
+
+// CanvasCredit is someone who worked on a movie. People are told apart by
+// their ID, so the names they were credited under don't matter.
+type CanvasCredit struct {
+	PersonId int64
+	Name     string
+	Role     string
+}
+
+// CanvasMovie is a watched movie with everything the filters and edges
+// need.
+type CanvasMovie struct {
+	Uuid   string
+	Title  string
+	ImdbId string
+	// What the movie's page is named with, from vault_name.
+	FileTitle string
+	Genres    []string
+	Credits   []CanvasCredit
+	Watches   []*MovieWatchPage
+}
+
+type CanvasFilters struct {
+	// The year watched, 0 for any.
+	Year int
+	// The canonical name, empty for any. Movies have canonical genres
+	// already, so only the case can differ.
+	Genre string
+	// One of CANVAS_FLAGS, empty for any.
+	Flag string
+	// How many people two movies need in common to be connected.
+	MinSharedCredits int
+}
+
+// LoadCanvasMovies gets every watched movie with its genres, credits and
+// watches, ordered by title.
+func LoadCanvasMovies(
+	ctx context.Context, queries *database.Queries,
+) ([]*CanvasMovie, error) {
+	watches, err := queries.GetAllMovieWatches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting movie watches: %v", err)
+	}
+	vaultNames, err := LoadVaultNames(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+	movies := make(map[string]*CanvasMovie)
+	for ii := range watches {
+		movie, ok := movies[watches[ii].MovieUuid]
+		if !ok {
+			movie = &CanvasMovie{
+				Uuid:   watches[ii].MovieUuid,
+				Title:  watches[ii].MovieTitle,
+				ImdbId: watches[ii].ImdbID,
+				FileTitle: vaultNames.FileTitle(
+					watches[ii].ImdbID, watches[ii].MovieTitle,
+				),
+			}
+			movies[movie.Uuid] = movie
+		}
+		movie.Watches = append(
+			movie.Watches, CreateMovieWatchPage(&watches[ii], vaultNames),
+		)
+	}
+
+	genres, err := queries.GetAllMovieGenreNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting genres: %v", err)
+	}
+	for ii := range genres {
+		if movie, ok := movies[genres[ii].MovieUuid]; ok {
+			movie.Genres = append(movie.Genres, genres[ii].Name)
+		}
+	}
+
+	// Credits go by person, so people who were merged connect their movies
+	// whatever names they were credited under.
+	credits, err := queries.GetAllMovieCredits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting credits: %v", err)
+	}
+	for ii := range credits {
+		if movie, ok := movies[credits[ii].MovieUuid]; ok {
+			movie.Credits = append(movie.Credits, CanvasCredit{
+				PersonId: credits[ii].PersonID,
+				Name:     credits[ii].Name,
+				Role:     CREDIT_ROLE_NAMES[credits[ii].Role],
+			})
+		}
+	}
+
+	canvasMovies := make([]*CanvasMovie, 0, len(movies))
+	for _, movie := range movies {
+		canvasMovies = append(canvasMovies, movie)
+	}
+	sort.Slice(canvasMovies, func(ii, jj int) bool {
+		if canvasMovies[ii].Title != canvasMovies[jj].Title {
+			return canvasMovies[ii].Title < canvasMovies[jj].Title
+		}
+		return canvasMovies[ii].Uuid < canvasMovies[jj].Uuid
+	})
+	return canvasMovies, nil
+}
+
+// matches is whether the movie passes the filters. The year and flag have
+// to hold for the same watch.
+func (f *CanvasFilters) matches(movie *CanvasMovie) bool {
+	if f.Genre != "" {
+		found := false
+		for ii := range movie.Genres {
+			if strings.EqualFold(movie.Genres[ii], f.Genre) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	yearPrefix := fmt.Sprintf("%04d-", f.Year)
+	for _, watch := range movie.Watches {
+		if f.Year != 0 && !strings.HasPrefix(watch.Watched, yearPrefix) {
+			continue
+		}
+		if f.Flag != "" && !CANVAS_FLAGS[f.Flag](watch) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// CreateCanvas connects the movies that pass the filters through the
+// people they have in common, and lays them out. Movies that don't end up
+// connected to anything are left off. The same movies and filters always
+// give the same canvas.
+func CreateCanvas(movies []*CanvasMovie, filters *CanvasFilters) (*Canvas, error) {
+	if filters.Flag != "" && CANVAS_FLAGS[filters.Flag] == nil {
+		flags := make([]string, 0, len(CANVAS_FLAGS))
+		for flag := range CANVAS_FLAGS {
+			flags = append(flags, flag)
+		}
+		sort.Strings(flags)
+		return nil, fmt.Errorf(
+			"unknown flag %v, expected one of %v",
+			filters.Flag, strings.Join(flags, ", "),
+		)
+	}
+	minShared := filters.MinSharedCredits
+	if minShared < 1 {
+		minShared = 1
+	}
+
+	included := make([]*CanvasMovie, 0)
+	for _, movie := range movies {
+		if filters.matches(movie) {
+			included = append(included, movie)
+		}
+	}
+
+	// Who worked on what, then who each pair has in common. People count
+	// once whatever they did, so a writer on one and director on the other
+	// still connects them.
+	movieRoles := make([]map[int64]map[string]bool, len(included))
+	personMovies := make(map[int64][]int)
+	personNames := make(map[int64]string)
+	for ii, movie := range included {
+		movieRoles[ii] = make(map[int64]map[string]bool)
+		for _, credit := range movie.Credits {
+			if movieRoles[ii][credit.PersonId] == nil {
+				movieRoles[ii][credit.PersonId] = make(map[string]bool)
+				personMovies[credit.PersonId] = append(
+					personMovies[credit.PersonId], ii,
+				)
+			}
+			movieRoles[ii][credit.PersonId][credit.Role] = true
+			personNames[credit.PersonId] = credit.Name
+		}
+	}
+	shared := make(map[[2]int][]int64)
+	for personId, movieIndexes := range personMovies {
+		for ii := range movieIndexes {
+			for jj := ii + 1; jj < len(movieIndexes); jj++ {
+				pair := [2]int{movieIndexes[ii], movieIndexes[jj]}
+				shared[pair] = append(shared[pair], personId)
+			}
+		}
+	}
+	pairs := make([][2]int, 0, len(shared))
+	for pair, people := range shared {
+		if len(people) >= minShared {
+			pairs = append(pairs, pair)
+		}
+	}
+	sort.Slice(pairs, func(ii, jj int) bool {
+		if pairs[ii][0] != pairs[jj][0] {
+			return pairs[ii][0] < pairs[jj][0]
+		}
+		return pairs[ii][1] < pairs[jj][1]
+	})
+
+	// Only the connected movies go on the canvas.
+	isConnected := make(map[int]bool)
+	for _, pair := range pairs {
+		isConnected[pair[0]] = true
+		isConnected[pair[1]] = true
+	}
+	nodeIndexes := make(map[int]int)
+	connected := make([]*CanvasMovie, 0)
+	for ii := range included {
+		if isConnected[ii] {
+			nodeIndexes[ii] = len(connected)
+			connected = append(connected, included[ii])
+		}
+	}
+
+	edges := make([]canvasLayoutEdge, len(pairs))
+	canvas := Canvas{
+		Nodes: make([]CanvasNode, len(connected)),
+		Edges: make([]CanvasEdge, len(pairs)),
+	}
+	for ii, pair := range pairs {
+		from, to := included[pair[0]], included[pair[1]]
+		edges[ii] = canvasLayoutEdge{
+			from:   nodeIndexes[pair[0]],
+			to:     nodeIndexes[pair[1]],
+			weight: float64(len(shared[pair])),
+		}
+		canvas.Edges[ii] = CanvasEdge{
+			Id:       from.ImdbId + "-" + to.ImdbId,
+			FromNode: from.ImdbId,
+			ToNode:   to.ImdbId,
+			Label: canvasEdgeLabel(
+				shared[pair], personNames,
+				movieRoles[pair[0]], movieRoles[pair[1]],
+			),
+		}
+	}
+	positions := layoutCanvas(len(connected), edges)
+	for ii, movie := range connected {
+		canvas.Nodes[ii] = CanvasNode{
+			Id:   movie.ImdbId,
+			Type: "file",
+			File: path.Join(
+				"Movies", MoviePageFileName(movie.FileTitle, movie.ImdbId),
+			),
+			X:      positions[ii][0],
+			Y:      positions[ii][1],
+			Width:  CANVAS_NODE_WIDTH,
+			Height: CANVAS_NODE_HEIGHT,
+		}
+	}
+	return &canvas, nil
+}
+
+// canvasEdgeLabel names the people the movies share with what they did on
+// either, directors first.
+func canvasEdgeLabel(
+	people []int64, personNames map[int64]string,
+	fromRoles map[int64]map[string]bool, toRoles map[int64]map[string]bool,
+) string {
+	credits := make([]CanvasCredit, len(people))
+	firstRoles := make(map[int64]int)
+	for ii, personId := range people {
+		roles := make([]string, 0, len(CANVAS_ROLES))
+		for jj, role := range CANVAS_ROLES {
+			if fromRoles[personId][role] || toRoles[personId][role] {
+				if len(roles) == 0 {
+					firstRoles[personId] = jj
+				}
+				roles = append(roles, strings.ToLower(role))
+			}
+		}
+		credits[ii] = CanvasCredit{
+			PersonId: personId,
+			Name:     personNames[personId],
+			Role:     strings.Join(roles, "/"),
+		}
+	}
+	sort.Slice(credits, func(ii, jj int) bool {
+		first := firstRoles[credits[ii].PersonId]
+		second := firstRoles[credits[jj].PersonId]
+		if first != second {
+			return first < second
+		}
+		if credits[ii].Name != credits[jj].Name {
+			return credits[ii].Name < credits[jj].Name
+		}
+		return credits[ii].PersonId < credits[jj].PersonId
+	})
+
+	labels := make([]string, 0, CANVAS_EDGE_LABEL_CREDITS+1)
+	for ii := range credits {
+		if ii == CANVAS_EDGE_LABEL_CREDITS {
+			labels = append(labels, fmt.Sprintf(
+				"and %v more", len(credits)-CANVAS_EDGE_LABEL_CREDITS,
+			))
+			break
+		}
+		labels = append(labels, fmt.Sprintf(
+			"%v (%v)", credits[ii].Name, credits[ii].Role,
+		))
+	}
+	return strings.Join(labels, ", ")
+}
+
+type canvasLayoutEdge struct {
+	from   int
+	to     int
+	weight float64
+}
+
+// layoutCanvas is a Fruchterman-Reingold layout: every node pushes every
+// other away, edges pull their ends together harder the more credits they
+// stand for, and how far a node can move shrinks each iteration until it
+// settles. Nodes start evenly around a circle rather than at random so the
+// layout comes out the same every time. Positions are the top left corners,
+// with the canvas starting at 0, 0.
+func layoutCanvas(n int, edges []canvasLayoutEdge) [][2]int {
+	k := float64(CANVAS_NODE_SPACING)
+	x := make([]float64, n)
+	y := make([]float64, n)
+	radius := k * math.Sqrt(float64(n)) / 2
+	for ii := 0; ii < n; ii++ {
+		angle := 2 * math.Pi * float64(ii) / float64(n)
+		x[ii] = radius * math.Cos(angle)
+		y[ii] = radius * math.Sin(angle)
+	}
+
+	dx := make([]float64, n)
+	dy := make([]float64, n)
+	startTemperature := radius
+	for iteration := 0; iteration < CANVAS_LAYOUT_ITERATIONS; iteration++ {
+		for ii := range dx {
+			dx[ii], dy[ii] = 0, 0
+		}
+		for ii := 0; ii < n; ii++ {
+			for jj := ii + 1; jj < n; jj++ {
+				deltaX, deltaY := x[ii]-x[jj], y[ii]-y[jj]
+				distance := math.Hypot(deltaX, deltaY)
+				if distance < 1 {
+					// Pull them apart in a direction that only depends on
+					// which nodes they are.
+					angle := float64(ii*n + jj)
+					deltaX, deltaY, distance = math.Cos(angle), math.Sin(angle), 1
+				}
+				force := k * k / distance
+				dx[ii] += deltaX / distance * force
+				dy[ii] += deltaY / distance * force
+				dx[jj] -= deltaX / distance * force
+				dy[jj] -= deltaY / distance * force
+			}
+		}
+		for _, edge := range edges {
+			deltaX, deltaY := x[edge.from]-x[edge.to], y[edge.from]-y[edge.to]
+			distance := math.Hypot(deltaX, deltaY)
+			if distance < 1 {
+				continue
+			}
+			force := distance * distance / k * edge.weight
+			dx[edge.from] -= deltaX / distance * force
+			dy[edge.from] -= deltaY / distance * force
+			dx[edge.to] += deltaX / distance * force
+			dy[edge.to] += deltaY / distance * force
+		}
+		temperature := startTemperature *
+			(1 - float64(iteration)/CANVAS_LAYOUT_ITERATIONS)
+		for ii := 0; ii < n; ii++ {
+			length := math.Hypot(dx[ii], dy[ii])
+			if length == 0 {
+				continue
+			}
+			step := math.Min(length, temperature)
+			x[ii] += dx[ii] / length * step
+			y[ii] += dy[ii] / length * step
+		}
+	}
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	for ii := 0; ii < n; ii++ {
+		minX = math.Min(minX, x[ii])
+		minY = math.Min(minY, y[ii])
+	}
+	positions := make([][2]int, n)
+	for ii := 0; ii < n; ii++ {
+		positions[ii] = [2]int{
+			int(math.Round(x[ii] - minX)), int(math.Round(y[ii] - minY)),
+		}
+	}
+	return positions
+}
+
+// WriteCanvas writes the canvas as JSON, tab indented like Obsidian does.
+func WriteCanvas(writer io.Writer, canvas *Canvas) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode(canvas); err != nil {
+		return fmt.Errorf("error writing canvas: %v", err)
+	}
+	return nil
+}
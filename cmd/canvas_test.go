@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func sampleCanvasMovies() []*CanvasMovie {
+	watch := func(watched string, slasher bool) *MovieWatchPage {
+		return &MovieWatchPage{Watched: watched, Slasher: slasher}
+	}
+	return []*CanvasMovie{
+		{
+			Title:     "From Beyond",
+			FileTitle: "From Beyond",
+			ImdbId:    "tt0091083",
+			Genres:    []string{"Horror", "Sci-Fi"},
+			Credits: []CanvasCredit{
+				{1, "Stuart Gordon", DIRECTOR_ROLE},
+				{2, "Jeffrey Combs", ACTOR_ROLE},
+				{3, "Barbara Crampton", ACTOR_ROLE},
+			},
+			Watches: []*MovieWatchPage{watch("2022-10-31", false)},
+		},
+		{
+			Title:     "Puppet Master",
+			FileTitle: "Puppet Master",
+			ImdbId:    "tt0098143",
+			Genres:    []string{"Horror"},
+			Credits: []CanvasCredit{
+				{4, "David Schmoeller", DIRECTOR_ROLE},
+				{5, "Charles Band", WRITER_ROLE},
+			},
+			Watches: []*MovieWatchPage{watch("2023-01-01", true)},
+		},
+		{
+			Title:     "Re-Animator",
+			FileTitle: "Re-Animator",
+			ImdbId:    "tt0089885",
+			Genres:    []string{"Comedy", "Horror"},
+			Credits: []CanvasCredit{
+				{1, "Stuart Gordon", DIRECTOR_ROLE},
+				{1, "Stuart Gordon", WRITER_ROLE},
+				{2, "Jeffrey Combs", ACTOR_ROLE},
+				{3, "Barbara Crampton", ACTOR_ROLE},
+				{6, "Bruce Abbott", ACTOR_ROLE},
+			},
+			Watches: []*MovieWatchPage{
+				watch("2022-10-31", false), watch("2023-10-31", true),
+			},
+		},
+		{
+			Title:     "Tenebrae",
+			FileTitle: "Tenebrae",
+			ImdbId:    "tt0084777",
+			Genres:    []string{"Horror"},
+			Credits: []CanvasCredit{
+				{7, "Dario Argento", DIRECTOR_ROLE},
+			},
+			Watches: []*MovieWatchPage{watch("2022-05-27", true)},
+		},
+		{
+			Title:     "Trancers",
+			FileTitle: "Trancers",
+			ImdbId:    "tt0090192",
+			Genres:    []string{"Action", "Sci-Fi"},
+			Credits: []CanvasCredit{
+				{5, "Charles Band", DIRECTOR_ROLE},
+				{5, "Charles Band", DIRECTOR_ROLE},
+			},
+			Watches: []*MovieWatchPage{watch("2023-02-01", false)},
+		},
+	}
+}
+
+func canvasNodeIds(canvas *Canvas) []string {
+	ids := make([]string, len(canvas.Nodes))
+	for ii := range canvas.Nodes {
+		ids[ii] = canvas.Nodes[ii].Id
+	}
+	return ids
+}
+
+func TestCreateCanvas(t *testing.T) {
+	canvas, err := CreateCanvas(sampleCanvasMovies(), &CanvasFilters{})
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	// Tenebrae doesn't share anyone. Charles Band wrote one and directed the
+	// other, which still counts.
+	idsTruth := []string{"tt0091083", "tt0098143", "tt0089885", "tt0090192"}
+	if !cmp.Equal(idsTruth, canvasNodeIds(canvas)) {
+		t.Errorf("Expected %v, got %v", idsTruth, canvasNodeIds(canvas))
+	}
+	if canvas.Nodes[0].File != "Movies/From Beyond (tt0091083).md" {
+		t.Errorf("Expected the movie page, got %v", canvas.Nodes[0].File)
+	}
+	edgesTruth := []CanvasEdge{
+		{
+			Id:       "tt0091083-tt0089885",
+			FromNode: "tt0091083",
+			ToNode:   "tt0089885",
+			Label: "Stuart Gordon (director/writer), " +
+				"Barbara Crampton (actor), Jeffrey Combs (actor)",
+		},
+		{
+			Id:       "tt0098143-tt0090192",
+			FromNode: "tt0098143",
+			ToNode:   "tt0090192",
+			Label:    "Charles Band (director/writer)",
+		},
+	}
+	if !cmp.Equal(edgesTruth, canvas.Edges) {
+		t.Errorf("Expected %v, got %v", edgesTruth, canvas.Edges)
+	}
+
+	// Nothing sits on top of anything else.
+	for ii := range canvas.Nodes {
+		for jj := ii + 1; jj < len(canvas.Nodes); jj++ {
+			first, second := canvas.Nodes[ii], canvas.Nodes[jj]
+			if math.Abs(float64(first.X-second.X)) < CANVAS_NODE_WIDTH &&
+				math.Abs(float64(first.Y-second.Y)) < CANVAS_NODE_HEIGHT {
+				t.Errorf("Expected %v and %v not to overlap", first, second)
+			}
+		}
+	}
+
+	// The layout is the same every time.
+	again, err := CreateCanvas(sampleCanvasMovies(), &CanvasFilters{})
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if !cmp.Equal(canvas, again) {
+		t.Errorf("Expected \n%v, got \n%v", canvas, again)
+	}
+}
+
+func TestCreateCanvasFilters(t *testing.T) {
+	tests := []struct {
+		filters CanvasFilters
+		truth   []string
+	}{
+		{CanvasFilters{Year: 2022}, []string{"tt0091083", "tt0089885"}},
+		{CanvasFilters{Year: 2023}, []string{"tt0098143", "tt0090192"}},
+		{CanvasFilters{Genre: "comedy"}, []string{}},
+		{CanvasFilters{Genre: "Horror"}, []string{"tt0091083", "tt0089885"}},
+		{CanvasFilters{Flag: "slasher"}, []string{}},
+		{CanvasFilters{MinSharedCredits: 3}, []string{"tt0091083", "tt0089885"}},
+		{CanvasFilters{MinSharedCredits: 4}, []string{}},
+	}
+	for _, test := range tests {
+		canvas, err := CreateCanvas(sampleCanvasMovies(), &test.filters)
+		if err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		if !cmp.Equal(test.truth, canvasNodeIds(canvas)) {
+			t.Errorf(
+				"Expected %v for %+v, got %v",
+				test.truth, test.filters, canvasNodeIds(canvas),
+			)
+		}
+	}
+
+	if _, err := CreateCanvas(
+		sampleCanvasMovies(), &CanvasFilters{Flag: "werewolves"},
+	); err == nil {
+		t.Error("Expected an error for an unknown flag")
+	}
+}
+
+func TestLoadCanvasMovies(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	reAnimator := sampleMoviePage()
+	reAnimator.Title = "Re-Animator"
+	reAnimator.ImdbLink = "https://www.imdb.com/title/tt0089885/"
+	reAnimator.Directors = []string{"Stuart Gordon"}
+	reAnimator.Writers = []string{"H.P. Lovecraft (story)"}
+	reAnimator.Actors = []string{"Jeffrey Combs"}
+	fromBeyond := sampleMoviePage()
+	fromBeyond.Title = "From Beyond"
+	fromBeyond.ImdbLink = "https://www.imdb.com/title/tt0091083/"
+	fromBeyond.Directors = []string{"Stuart Gordon"}
+	fromBeyond.Writers = []string{"H.P. Lovecraft"}
+	// Merged into Jeffrey Combs below, which still connects the movies.
+	fromBeyond.Actors = []string{"Jeff Combs"}
+	// Not watched, so not on the canvas.
+	dagon := sampleMoviePage()
+	dagon.Title = "Dagon"
+	dagon.ImdbLink = "https://www.imdb.com/title/tt0264508/"
+	dagon.Directors = []string{"Stuart Gordon"}
+	for _, movie := range []*MoviePage{reAnimator, fromBeyond, dagon} {
+		movieUuids, err := InsertMovieDetails(db, ctx, queries, movie, nil)
+		if err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		if movie == dagon {
+			continue
+		}
+		movieWatch := sampleMovieWatchPage()
+		movieWatch.Title = movie.Title
+		movieWatch.ImdbId = movie.ImdbLink[27:36]
+		movieWatch.Watched = "2022-10-31"
+		if err := queries.InsertMovieWatch(
+			ctx, *CreateInsertMovieWatchParams(movieWatch, movieUuids.Movie),
+		); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+
+	jeffreyCombs, err := queries.FindPersonByName(ctx, "Jeffrey Combs")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	jeffCombs, err := queries.FindPersonByName(ctx, "Jeff Combs")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := MergePeople(ctx, queries, jeffCombs, jeffreyCombs); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	stuartGordon, err := queries.FindPersonByName(ctx, "Stuart Gordon")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	lovecraft, err := queries.FindPersonByName(ctx, "H.P. Lovecraft")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	movies, err := LoadCanvasMovies(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(movies) != 2 || movies[0].Title != "From Beyond" ||
+		movies[1].Title != "Re-Animator" {
+		t.Fatalf("Expected From Beyond and Re-Animator, got %v", movies)
+	}
+	creditsTruth := []CanvasCredit{
+		{stuartGordon, "Stuart Gordon", DIRECTOR_ROLE},
+		{lovecraft, "H.P. Lovecraft", WRITER_ROLE},
+		{jeffreyCombs, "Jeffrey Combs", ACTOR_ROLE},
+	}
+	for ii := range movies {
+		if !cmp.Equal(creditsTruth, movies[ii].Credits) {
+			t.Errorf("Expected %v, got %v", creditsTruth, movies[ii].Credits)
+		}
+	}
+	if len(movies[1].Watches) != 1 ||
+		movies[1].Watches[0].Watched != "2022-10-31" {
+		t.Errorf("Expected the watch on 2022-10-31, got %v", movies[1].Watches)
+	}
+
+	canvas, err := CreateCanvas(movies, &CanvasFilters{})
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	var written bytes.Buffer
+	if err := WriteCanvas(&written, canvas); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	var answer Canvas
+	if err := json.Unmarshal(written.Bytes(), &answer); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if !cmp.Equal(*canvas, answer) {
+		t.Errorf("Expected \n%v, got \n%v", *canvas, answer)
+	}
+	label := "Stuart Gordon (director), H.P. Lovecraft (writer), " +
+		"Jeffrey Combs (actor)"
+	if len(answer.Edges) != 1 || answer.Edges[0].Label != label {
+		t.Errorf("Expected one edge labeled %v, got %v", label, answer.Edges)
+	}
+}
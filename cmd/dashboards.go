@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// Dashboards are tables of watches, rebuilt with the vault. Each one has the
+// table written out for readers without the Dataview plugin, like Obsidian
+// on a phone, and the Dataview query that gives the same table from the
+// watch pages, which stays current between builds.
+const DASHBOARDS_DIR = "Dashboards"
+
+var DASHBOARD_TEMPLATE = `
+# {{.Name}}
+
+{{.Description}}
+
+## Data
+watches:: {{len .Rows}}
+
+## Table
+| Watched | Movie | Service | Rating |
+| --- | --- | --- | --- |
+{{range .Rows}}| [[{{.WatchPage}}]] | [[{{.MoviePage}}]] | {{cell .Service}} | {{if .Rating}}{{.Rating}}{{end}} |
+{{end}}
+## Dataview
+` + "```dataview" + `
+TABLE WITHOUT ID file.link AS "Watched", name AS "Movie", service AS "Service", rating AS "Rating"
+FROM #movie-watch
+WHERE {{.Where}}
+SORT file.name ASC
+` + "```" + `
+
+## Tags
+#dashboard
+
+## Notes
+{{.Notes}}`
+
+var dashboardFuncs = template.FuncMap{
+	// Pipes end the cell and newlines end the table.
+	"cell": func(text string) string {
+		text = strings.ReplaceAll(text, "|", `\|`)
+		return strings.Join(strings.Fields(text), " ")
+	},
+}
+
+type DashboardRow struct {
+	Watched   string
+	WatchPage string
+	MoviePage string
+	Service   string
+	Rating    float64
+}
+
+type DashboardPage struct {
+	Name        string
+	Description string
+	// The Dataview WHERE clause that picks out the same watches.
+	Where string
+	Rows  []DashboardRow
+	Notes string
+}
+
+type dashboardSpec struct {
+	name        string
+	description string
+	where       string
+	include     func(*MovieWatchPage) bool
+}
+
+// The dashboards on top of the diary for each year.
+var WATCH_DASHBOARDS = []dashboardSpec{
+	{
+		name:        "Rewatches",
+		description: "Movies I'd seen before.",
+		where:       "!first_time",
+		include:     func(p *MovieWatchPage) bool { return !p.FirstTime },
+	},
+	{
+		name:        "Joe Bob",
+		description: "Watches with Joe Bob Briggs hosting.",
+		where:       "joe_bob",
+		include:     func(p *MovieWatchPage) bool { return p.JoeBob },
+	},
+	{
+		name:        "Slasher",
+		description: "Slasher watches.",
+		where:       "slasher",
+		include:     func(p *MovieWatchPage) bool { return p.Slasher },
+	},
+	{
+		name:        "Zombies",
+		description: "Zombie watches.",
+		where:       "zombies",
+		include:     func(p *MovieWatchPage) bool { return p.Zombies },
+	},
+	{
+		name:        "Godzilla",
+		description: "Godzilla watches.",
+		where:       "godzilla",
+		include:     func(p *MovieWatchPage) bool { return p.Godzilla },
+	},
+}
+
+// CreateDashboardPages makes a diary page for each year with a watch,
+// oldest year first, followed by the WATCH_DASHBOARDS. Rows are in the
+// order of the watch pages, by date and then title.
+func CreateDashboardPages(
+	movieWatches []database.GetAllMovieWatchesRow, vaultNames VaultNames,
+) []*DashboardPage {
+	watchPages := make([]*MovieWatchPage, len(movieWatches))
+	for ii := range movieWatches {
+		watchPages[ii] = CreateMovieWatchPage(&movieWatches[ii], vaultNames)
+	}
+	sort.SliceStable(watchPages, func(ii, jj int) bool {
+		return MovieWatchPageFileName(watchPages[ii]) <
+			MovieWatchPageFileName(watchPages[jj])
+	})
+
+	pages := make([]*DashboardPage, 0)
+	years := make(map[string]*DashboardPage)
+	for _, watchPage := range watchPages {
+		year := watchPage.Watched
+		if len(year) > 4 {
+			year = year[:4]
+		}
+		diary, ok := years[year]
+		if !ok {
+			diary = &DashboardPage{
+				Name:        "Diary " + year,
+				Description: fmt.Sprintf("Everything I watched in %v.", year),
+				Where:       fmt.Sprintf(`startswith(file.name, "%v-")`, year),
+				Rows:        make([]DashboardRow, 0),
+			}
+			years[year] = diary
+			pages = append(pages, diary)
+		}
+		diary.Rows = append(diary.Rows, createDashboardRow(watchPage))
+	}
+
+	for _, spec := range WATCH_DASHBOARDS {
+		page := DashboardPage{
+			Name:        spec.name,
+			Description: spec.description,
+			Where:       spec.where,
+			Rows:        make([]DashboardRow, 0),
+		}
+		for _, watchPage := range watchPages {
+			if spec.include(watchPage) {
+				page.Rows = append(page.Rows, createDashboardRow(watchPage))
+			}
+		}
+		pages = append(pages, &page)
+	}
+	return pages
+}
+
+func createDashboardRow(watchPage *MovieWatchPage) DashboardRow {
+	return DashboardRow{
+		Watched: watchPage.Watched,
+		WatchPage: strings.TrimSuffix(
+			MovieWatchPageFileName(watchPage), ".md",
+		),
+		MoviePage: strings.TrimSuffix(
+			MoviePageFileName(watchPage.FileTitle, watchPage.ImdbId), ".md",
+		),
+		Service: watchPage.Service,
+		Rating:  watchPage.Rating,
+	}
+}
+
+// WriteDashboardPages rebuilds the dashboards in the vault, keeping their
+// notes. It returns how many it wrote.
+func WriteDashboardPages(
+	vaultDir string,
+	movieWatches []database.GetAllMovieWatchesRow,
+	vaultNames VaultNames,
+) (int, error) {
+	dashboardTemplate, err := template.New("dashboard").Funcs(
+		dashboardFuncs,
+	).Parse(DASHBOARD_TEMPLATE)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse dashboard template: %v", err)
+	}
+	dashboardsDir := path.Join(vaultDir, DASHBOARDS_DIR)
+	if err := os.MkdirAll(dashboardsDir, 0755); err != nil {
+		return 0, fmt.Errorf("error creating %v: %v", dashboardsDir, err)
+	}
+
+	pages := CreateDashboardPages(movieWatches, vaultNames)
+	for ii, page := range pages {
+		filePath := path.Join(dashboardsDir, page.Name+".md")
+		if page.Notes, err = ReadPreservedNotes(filePath); err != nil {
+			return ii, fmt.Errorf(
+				"error reading notes for %v: %v", page.Name, err,
+			)
+		}
+		if err := WritePage(dashboardTemplate, filePath, page); err != nil {
+			return ii, err
+		}
+	}
+	return len(pages), nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"database/sql"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func sampleDashboardWatches() []database.GetAllMovieWatchesRow {
+	return []database.GetAllMovieWatchesRow{
+		{
+			MovieTitle: "Godzilla vs. Hedorah",
+			ImdbID:     "tt0067148",
+			Watched:    "2023-01-01",
+			Service:    "HBO Max | Criterion",
+			FirstTime:  1,
+			Godzilla:   1,
+		},
+		{
+			MovieTitle: "Tenebrae",
+			ImdbID:     "tt0084777",
+			Watched:    "2022-10-31",
+			Service:    "Shudder",
+			JoeBob:     1,
+			Slasher:    1,
+			Rating:     sql.NullFloat64{Float64: 4.5, Valid: true},
+		},
+		{
+			MovieTitle: "Day of the Dead",
+			ImdbID:     "tt0088993",
+			Watched:    "2022-05-27",
+			Service:    "Shudder",
+			FirstTime:  1,
+			JoeBob:     1,
+			Zombies:    1,
+		},
+	}
+}
+
+func TestCreateDashboardPages(t *testing.T) {
+	pages := CreateDashboardPages(sampleDashboardWatches(), nil)
+
+	day := DashboardRow{
+		Watched:   "2022-05-27",
+		WatchPage: "2022-05-27 Day of the Dead",
+		MoviePage: "Day of the Dead (tt0088993)",
+		Service:   "Shudder",
+	}
+	tenebrae := DashboardRow{
+		Watched:   "2022-10-31",
+		WatchPage: "2022-10-31 Tenebrae",
+		MoviePage: "Tenebrae (tt0084777)",
+		Service:   "Shudder",
+		Rating:    4.5,
+	}
+	hedorah := DashboardRow{
+		Watched:   "2023-01-01",
+		WatchPage: "2023-01-01 Godzilla vs. Hedorah",
+		MoviePage: "Godzilla vs. Hedorah (tt0067148)",
+		Service:   "HBO Max | Criterion",
+	}
+	truth := map[string][]DashboardRow{
+		"Diary 2022": {day, tenebrae},
+		"Diary 2023": {hedorah},
+		"Rewatches":  {tenebrae},
+		"Joe Bob":    {day, tenebrae},
+		"Slasher":    {tenebrae},
+		"Zombies":    {day},
+		"Godzilla":   {hedorah},
+	}
+	names := make([]string, len(pages))
+	for ii := range pages {
+		names[ii] = pages[ii].Name
+		if !cmp.Equal(truth[pages[ii].Name], pages[ii].Rows) {
+			t.Errorf(
+				"Expected %v for %v, got %v",
+				truth[pages[ii].Name], pages[ii].Name, pages[ii].Rows,
+			)
+		}
+	}
+	namesTruth := []string{
+		"Diary 2022", "Diary 2023", "Rewatches", "Joe Bob", "Slasher",
+		"Zombies", "Godzilla",
+	}
+	if !cmp.Equal(namesTruth, names) {
+		t.Errorf("Expected %v, got %v", namesTruth, names)
+	}
+	if pages[0].Where != `startswith(file.name, "2022-")` {
+		t.Errorf("Expected a query for 2022, got %v", pages[0].Where)
+	}
+}
+
+func TestWriteDashboardPages(t *testing.T) {
+	vaultDir := t.TempDir()
+	written, err := WriteDashboardPages(vaultDir, sampleDashboardWatches(), nil)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if written != 7 {
+		t.Errorf("Expected 7 pages, got %v", written)
+	}
+
+	filePath := path.Join(vaultDir, DASHBOARDS_DIR, "Diary 2023.md")
+	page, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	truth := `
+# Diary 2023
+
+Everything I watched in 2023.
+
+## Data
+watches:: 1
+
+## Table
+| Watched | Movie | Service | Rating |
+| --- | --- | --- | --- |
+| [[2023-01-01 Godzilla vs. Hedorah]] | [[Godzilla vs. Hedorah (tt0067148)]] | HBO Max \| Criterion |  |
+
+## Dataview
+` + "```dataview" + `
+TABLE WITHOUT ID file.link AS "Watched", name AS "Movie", service AS "Service", rating AS "Rating"
+FROM #movie-watch
+WHERE startswith(file.name, "2023-")
+SORT file.name ASC
+` + "```" + `
+
+## Tags
+#dashboard
+
+## Notes
+`
+	if truth != string(page) {
+		t.Errorf("Expected \n%v, got \n%v", truth, string(page))
+	}
+
+	// Rebuilding keeps the notes.
+	notes := "Kaiju year.\n"
+	if err := os.WriteFile(
+		filePath, append(page, []byte(notes)...), 0644,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if _, err := WriteDashboardPages(
+		vaultDir, sampleDashboardWatches(), nil,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	page, err = os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if truth+notes != string(page) {
+		t.Errorf("Expected \n%v, got \n%v", truth+notes, string(page))
+	}
+}
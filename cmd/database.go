@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log"
 	"regexp"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/timothyrenner/movies-app/database"
@@ -97,6 +99,11 @@ func CreateInsertMovieWatchParams(movieWatch *MovieWatchPage, movieUuid string)
 		movieNotes.String = movieWatch.Notes
 		movieNotes.Valid = true
 	}
+	var watchedAt, timezone sql.NullString
+	if movieWatch.WatchedAt != nil {
+		watchedAt = textToNullString(movieWatch.WatchedAt.Timestamp())
+		timezone = textToNullString(movieWatch.WatchedAt.Timezone)
+	}
 	return &database.InsertMovieWatchParams{
 		Uuid:       uuid.New().String(),
 		MovieUuid:  movieUuid,
@@ -107,6 +114,9 @@ func CreateInsertMovieWatchParams(movieWatch *MovieWatchPage, movieUuid string)
 		FirstTime:  firstTime,
 		JoeBob:     joeBob,
 		Notes:      movieNotes,
+		WatchedAt:  watchedAt,
+		Timezone:   timezone,
+		Rating:     starRatingToNull(movieWatch.Rating),
 	}
 }
 
@@ -125,6 +135,23 @@ func CreateInsertMovieGenreParams(
 	return rows
 }
 
+// InsertGenres makes sure every genre is in the genre table, so genres OMDB
+// comes up with that the taxonomy doesn't know about still get a slug. The
+// genres are canonical already, so the ones it knows about are left alone.
+func InsertGenres(
+	ctx context.Context, queries *database.Queries, genres []string,
+) error {
+	for ii := range genres {
+		if err := queries.InsertGenre(ctx, database.InsertGenreParams{
+			Name: genres[ii],
+			Slug: GenreSlug(genres[ii]),
+		}); err != nil {
+			return fmt.Errorf("error inserting genre %v: %v", genres[ii], err)
+		}
+	}
+	return nil
+}
+
 func CreateInsertMovieActorParams(
 	moviePage *MoviePage,
 	movieUuid string,
@@ -200,18 +227,125 @@ func CreateInsertMovieRatingParams(
 func CreateInsertMovieReviewParams(
 	movieReviewPage *MovieReviewPage,
 	movieUuid string,
+	movieWatchUuid string,
 ) *database.InsertReviewParams {
 	var liked int64
 	if movieReviewPage.Liked {
 		liked = 1
 	}
+	reviewUuid := movieReviewPage.Uuid
+	if reviewUuid == "" {
+		reviewUuid = uuid.New().String()
+	}
 	return &database.InsertReviewParams{
-		Uuid:       uuid.New().String(),
-		MovieUuid:  movieUuid,
-		MovieTitle: movieReviewPage.MovieTitle,
-		Review:     movieReviewPage.Review,
-		Liked:      liked,
+		Uuid:           reviewUuid,
+		MovieUuid:      movieUuid,
+		MovieTitle:     movieReviewPage.MovieTitle,
+		MovieWatchUuid: textToNullString(movieWatchUuid),
+		Review:         movieReviewPage.Review,
+		Liked:          liked,
+		Rating:         starRatingToNull(movieReviewPage.Rating),
+	}
+}
+
+// SaveReview inserts the review, or if it's already in the database, updates
+// it and keeps the old text as a revision. A page without a review_id is the
+// same review as the latest one for its movie and watch. It fills in the
+// page's uuid and rating, and reports whether anything changed.
+func SaveReview(
+	db *sql.DB,
+	ctx context.Context,
+	queries *database.Queries,
+	page *MovieReviewPage,
+) (bool, error) {
+	// The page's title is the movie page's file title.
+	movieUuid, err := FindMovieForPage(
+		ctx, queries, page.MovieTitle, page.ImdbId,
+	)
+	if err != nil {
+		return false, fmt.Errorf(
+			"error finding movie %v (%v): %v", page.ImdbId, page.MovieTitle, err,
+		)
+	}
+	var movieWatchUuid string
+	if page.Watched != "" {
+		movieWatchUuid, err = queries.FindMovieWatch(
+			ctx, database.FindMovieWatchParams{
+				ImdbID: page.ImdbId, Watched: page.Watched,
+			},
+		)
+		if err != nil {
+			return false, fmt.Errorf(
+				"error finding watch of %v on %v: %v",
+				page.ImdbId, page.Watched, err,
+			)
+		}
+	}
+	page.Rating, err = AggregateReviewRating(ctx, queries, movieUuid, page.Rating)
+	if err != nil {
+		return false, fmt.Errorf("error getting rating: %v", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("error starting transaction: %v", err)
 	}
+	defer tx.Rollback()
+	qtx := queries.WithTx(tx)
+
+	if page.Uuid == "" {
+		page.Uuid, err = qtx.FindReview(ctx, database.FindReviewParams{
+			MovieUuid:      movieUuid,
+			MovieWatchUuid: textToNullString(movieWatchUuid),
+		})
+		if err != nil && err != sql.ErrNoRows {
+			return false, fmt.Errorf("error finding review: %v", err)
+		}
+	}
+	params := CreateInsertMovieReviewParams(page, movieUuid, movieWatchUuid)
+	page.Uuid = params.Uuid
+
+	current, err := qtx.GetReview(ctx, params.Uuid)
+	if err == sql.ErrNoRows {
+		if err := qtx.InsertReview(ctx, *params); err != nil {
+			return false, fmt.Errorf("error inserting review: %v", err)
+		}
+	} else if err != nil {
+		return false, fmt.Errorf("error getting review %v: %v", params.Uuid, err)
+	} else if current.MovieUuid != movieUuid {
+		return false, fmt.Errorf(
+			"review %v is for %v, not %v", params.Uuid, current.ImdbID,
+			page.ImdbId,
+		)
+	} else if current.Review == params.Review &&
+		current.Liked == params.Liked &&
+		current.Rating == params.Rating &&
+		current.MovieWatchUuid == params.MovieWatchUuid &&
+		current.MovieTitle == params.MovieTitle {
+		return false, nil
+	} else if err := qtx.UpdateReview(ctx, database.UpdateReviewParams{
+		MovieTitle:     params.MovieTitle,
+		MovieWatchUuid: params.MovieWatchUuid,
+		Review:         params.Review,
+		Liked:          params.Liked,
+		Rating:         params.Rating,
+		Uuid:           params.Uuid,
+	}); err != nil {
+		return false, fmt.Errorf("error updating review: %v", err)
+	}
+
+	if err := qtx.InsertReviewRevision(ctx, database.InsertReviewRevisionParams{
+		ReviewUuid: params.Uuid,
+		Review:     params.Review,
+		Liked:      params.Liked,
+		Rating:     params.Rating,
+	}); err != nil {
+		return false, fmt.Errorf("error inserting review revision: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("error committing transaction: %v", err)
+	}
+	return true, nil
 }
 
 type MovieDetailUuids struct {
@@ -247,6 +381,25 @@ func InsertMovieDetails(
 	if err := qtx.InsertMovie(ctx, *movieParams); err != nil {
 		return nil, fmt.Errorf("error inserting movie: %v", err)
 	}
+	fileTitle, err := SaveVaultName(ctx, qtx, movieParams.Uuid, movie.Title)
+	if err != nil {
+		return nil, err
+	}
+	if fileTitle != cleanTitle(movie.Title) {
+		log.Printf(
+			"%v has the same file name as another title, so it's named %v.",
+			movie.Title, fileTitle,
+		)
+	}
+	// Pages don't carry the poster, so only overwrite it when there's one.
+	if movie.Poster != "" {
+		if err := qtx.UpdateMoviePoster(ctx, database.UpdateMoviePosterParams{
+			Poster: textToNullString(movie.Poster),
+			Uuid:   movieParams.Uuid,
+		}); err != nil {
+			return nil, fmt.Errorf("error updating poster: %v", err)
+		}
+	}
 
 	movieGenreParams := CreateInsertMovieGenreParams(movie, movieParams.Uuid)
 	movieUuids.Genre = make([]string, len(movieGenreParams))
@@ -257,6 +410,9 @@ func InsertMovieDetails(
 			return nil, fmt.Errorf("error inserting movie genre: %v", err)
 		}
 	}
+	if err := InsertGenres(ctx, qtx, movie.Genres); err != nil {
+		return nil, fmt.Errorf("error inserting genres: %v", err)
+	}
 
 	movieActorParams := CreateInsertMovieActorParams(movie, movieParams.Uuid)
 	movieUuids.Actor = make([]string, len(movieActorParams))
@@ -297,6 +453,10 @@ func InsertMovieDetails(
 		}
 	}
 
+	if err := InsertMovieCredits(ctx, qtx, movie, movieParams.Uuid); err != nil {
+		return nil, fmt.Errorf("error inserting movie credits: %v", err)
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return nil, fmt.Errorf("error committing transaction: %v", err)
@@ -304,3 +464,145 @@ func InsertMovieDetails(
 
 	return &movieUuids, nil
 }
+
+// InsertMovieFromOmdb fetches the movie for a watch from OMDB and inserts it
+// with all its details. The watch supplies the flags OMDB doesn't know about.
+func InsertMovieFromOmdb(
+	db *sql.DB,
+	ctx context.Context,
+	queries *database.Queries,
+	omdbClient *OmdbClient,
+	taxonomy *GenreTaxonomy,
+	movieWatch *MovieWatchPage,
+) (*MoviePage, *MovieDetailUuids, error) {
+	omdbResponse, err := omdbClient.GetMovie(movieWatch.ImdbId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching movie from OMDB: %v", err)
+	}
+	if omdbResponse.Response == "False" {
+		return nil, nil, fmt.Errorf(
+			"OMDB has no movie %v", movieWatch.ImdbId,
+		)
+	}
+
+	moviePage, err := CreateMoviePage(omdbResponse, movieWatch, taxonomy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating movie page: %v", err)
+	}
+
+	movieDetailUuids, err := InsertMovieDetails(
+		db, ctx, queries, moviePage, omdbResponse.Ratings,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"error inserting movie details into database: %v", err,
+		)
+	}
+	return moviePage, movieDetailUuids, nil
+}
+
+// Roles as they're stored in movie_credit.
+const (
+	DIRECTOR_CREDIT_ROLE = "director"
+	WRITER_CREDIT_ROLE   = "writer"
+	ACTOR_CREDIT_ROLE    = "actor"
+)
+
+// ResolvePersonID finds the person a credited name refers to, checking
+// aliases before normalized names, and creates the person if there isn't one.
+// Names that differ from their normalized form are recorded as aliases.
+func ResolvePersonID(
+	ctx context.Context, queries *database.Queries, name string,
+) (int64, error) {
+	personID, err := queries.FindPersonByAlias(ctx, name)
+	if err == nil {
+		return personID, nil
+	} else if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("error finding alias %v: %v", name, err)
+	}
+
+	normalizedName := NormalizePersonName(name)
+	personID, err = queries.FindPersonByName(ctx, normalizedName)
+	if err == sql.ErrNoRows {
+		personID, err = queries.FindPersonByAlias(ctx, normalizedName)
+	}
+	if err == sql.ErrNoRows {
+		personID, err = queries.InsertPerson(ctx, normalizedName)
+		if err != nil {
+			return 0, fmt.Errorf(
+				"error inserting person %v: %v", normalizedName, err,
+			)
+		}
+	} else if err != nil {
+		return 0, fmt.Errorf("error finding person %v: %v", normalizedName, err)
+	}
+
+	if normalizedName != name {
+		if err := queries.InsertPersonAlias(
+			ctx, database.InsertPersonAliasParams{
+				Alias: name, PersonID: personID,
+			},
+		); err != nil {
+			return 0, fmt.Errorf("error inserting alias %v: %v", name, err)
+		}
+	}
+	return personID, nil
+}
+
+// FindPersonID looks a person up the same way ResolvePersonID does, but
+// returns sql.ErrNoRows rather than creating them.
+func FindPersonID(
+	ctx context.Context, queries *database.Queries, name string,
+) (int64, error) {
+	personID, err := queries.FindPersonByAlias(ctx, name)
+	if err != sql.ErrNoRows {
+		return personID, err
+	}
+	normalizedName := NormalizePersonName(name)
+	personID, err = queries.FindPersonByName(ctx, normalizedName)
+	if err == sql.ErrNoRows {
+		personID, err = queries.FindPersonByAlias(ctx, normalizedName)
+	}
+	return personID, err
+}
+
+func InsertMovieCredits(
+	ctx context.Context,
+	queries *database.Queries,
+	moviePage *MoviePage,
+	movieUuid string,
+) error {
+	credits := []struct {
+		role  string
+		names []string
+	}{
+		{DIRECTOR_CREDIT_ROLE, moviePage.Directors},
+		{WRITER_CREDIT_ROLE, moviePage.Writers},
+		{ACTOR_CREDIT_ROLE, moviePage.Actors},
+	}
+	for ii := range credits {
+		for jj, name := range credits[ii].names {
+			if strings.TrimSpace(name) == "" || name == "N/A" {
+				continue
+			}
+			personID, err := ResolvePersonID(ctx, queries, name)
+			if err != nil {
+				return err
+			}
+			if err := queries.InsertMovieCredit(
+				ctx, database.InsertMovieCreditParams{
+					MovieUuid:    movieUuid,
+					PersonID:     personID,
+					Role:         credits[ii].role,
+					BillingOrder: int64(jj + 1),
+				},
+			); err != nil {
+				return fmt.Errorf(
+					"error inserting %v credit for %v: %v",
+					credits[ii].role, name, err,
+				)
+			}
+		}
+	}
+	return nil
+}
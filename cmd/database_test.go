@@ -431,7 +431,7 @@ func TestCreateInsertMovieReviewParams(t *testing.T) {
 	movieReview := sampleReviewPage()
 	movieUuid := uuid.New().String()
 
-	answer := CreateInsertMovieReviewParams(movieReview, movieUuid)
+	answer := CreateInsertMovieReviewParams(movieReview, movieUuid, "")
 	truth := &database.InsertReviewParams{
 		Uuid:       answer.Uuid,
 		MovieUuid:  movieUuid,
@@ -443,6 +443,18 @@ func TestCreateInsertMovieReviewParams(t *testing.T) {
 	if !cmp.Equal(truth, answer) {
 		t.Errorf("Expected \n%v, got \n%v", truth, answer)
 	}
+
+	// Reviews already in the database keep their uuid.
+	movieReview.Uuid = uuid.New().String()
+	movieWatchUuid := uuid.New().String()
+	answer = CreateInsertMovieReviewParams(
+		movieReview, movieUuid, movieWatchUuid,
+	)
+	truth.Uuid = movieReview.Uuid
+	truth.MovieWatchUuid = sql.NullString{String: movieWatchUuid, Valid: true}
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected \n%v, got \n%v", truth, answer)
+	}
 }
 
 func TestInsertMovieDetails(t *testing.T) {
@@ -609,3 +621,107 @@ func TestInsertMovieDetails(t *testing.T) {
 		t.Errorf("Expected \n%v, got \n%v", movieRatingTruth, movieRatingAnswer)
 	}
 }
+
+func TestSaveReview(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	movieDetails, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := queries.InsertMovieWatch(ctx, *CreateInsertMovieWatchParams(
+		sampleMovieWatchPage(), movieDetails.Movie,
+	)); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	// Another movie with the same title mustn't collide.
+	otherMovie := sampleMoviePage()
+	otherMovie.ImdbLink = "https://www.imdb.com/title/tt0000001/"
+	if _, err := InsertMovieDetails(db, ctx, queries, otherMovie, nil); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	save := func(page MovieReviewPage) (string, bool) {
+		changed, err := SaveReview(db, ctx, queries, &page)
+		if err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		return page.Uuid, changed
+	}
+	page := MovieReviewPage{
+		MovieTitle: "Tenebrae", ImdbId: "tt0084777", Review: "Sharp.",
+	}
+	reviewUuid, changed := save(page)
+	if !changed {
+		t.Errorf("Expected the review to be inserted")
+	}
+	if _, changed := save(page); changed {
+		t.Errorf("Expected saving the same review to change nothing")
+	}
+
+	// Without a review_id the page revises the movie's review.
+	page.Review = "Razor sharp."
+	page.Liked = true
+	if revisedUuid, changed := save(page); !changed || revisedUuid != reviewUuid {
+		t.Errorf("Expected %v to be revised, got %v", reviewUuid, revisedUuid)
+	}
+	revisions, err := queries.GetReviewRevisions(ctx, reviewUuid)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Review != "Sharp." ||
+		revisions[1].Review != "Razor sharp." || revisions[1].Liked != 1 {
+		t.Errorf("Unexpected revisions %v", revisions)
+	}
+
+	// A review for the watch is a second review.
+	watchPage := MovieReviewPage{
+		MovieTitle: "Tenebrae", ImdbId: "tt0084777", Watched: "2022-05-27",
+		Review: "Better the second time.",
+	}
+	watchReviewUuid, _ := save(watchPage)
+	if watchReviewUuid == reviewUuid {
+		t.Errorf("Expected a new review for the watch")
+	}
+	otherPage := MovieReviewPage{
+		MovieTitle: "Tenebrae", ImdbId: "tt0000001", Review: "Not the Argento.",
+	}
+	save(otherPage)
+
+	reviews, err := queries.GetReviewsForMovie(ctx, "tt0084777")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(reviews) != 2 || reviews[0].Review != "Razor sharp." ||
+		reviews[1].Watched.String != "2022-05-27" {
+		t.Errorf("Unexpected reviews %v", reviews)
+	}
+
+	// A review_id picks the review no matter the watch.
+	watchPage.Uuid = reviewUuid
+	watchPage.Review = "Moved to the watch."
+	save(watchPage)
+	review, err := queries.GetReview(ctx, reviewUuid)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if review.Watched.String != "2022-05-27" ||
+		review.Review != "Moved to the watch." {
+		t.Errorf("Unexpected review %v", review)
+	}
+
+	watchPage.Watched = "2022-05-28"
+	if _, err := SaveReview(db, ctx, queries, &watchPage); err == nil {
+		t.Errorf("Expected an error for a watch that isn't in the database")
+	}
+	otherPage.Uuid = reviewUuid
+	if _, err := SaveReview(db, ctx, queries, &otherPage); err == nil {
+		t.Errorf("Expected an error for a review of another movie")
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports the movies database into other formats.",
+}
+
+var exportFeedCmd = &cobra.Command{
+	Use:   "feed",
+	Short: "Writes an Atom feed of the latest watches and reviews.",
+	Run:   exportFeed,
+	Args:  cobra.NoArgs,
+}
+
+var exportICalCmd = &cobra.Command{
+	Use:   "ical",
+	Short: "Writes the watch history as an iCalendar file of all day events.",
+	Run:   exportICal,
+	Args:  cobra.NoArgs,
+}
+
+var exportCanvasCmd = &cobra.Command{
+	Use:   "canvas",
+	Short: "Writes an Obsidian canvas of watched movies connected by the people they share.",
+	Long: `Writes an Obsidian canvas of watched movies connected by the people they share.
+
+Each movie is its page in the vault, so write the canvas into the vault for
+the pages to show up. Movies that don't share anyone with another movie on
+the canvas are left off.`,
+	Run:  exportCanvas,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportFeedCmd)
+	exportCmd.AddCommand(exportICalCmd)
+	exportCmd.AddCommand(exportCanvasCmd)
+
+	exportFeedCmd.Flags().IntP(
+		"limit", "n", DEFAULT_FEED_SIZE, "The number of watches to include.",
+	)
+	exportFeedCmd.Flags().StringP(
+		"output", "o", "", "The file to write to. Defaults to stdout.",
+	)
+	exportFeedCmd.Flags().StringP(
+		"title", "t", "Movie Diary", "The title of the feed.",
+	)
+	exportFeedCmd.Flags().StringP(
+		"author", "a", "", "The author of the feed. Defaults to the title.",
+	)
+	exportFeedCmd.Flags().StringP(
+		"url", "u", "", "The URL the feed will be published at.",
+	)
+
+	exportICalCmd.Flags().StringP(
+		"output", "o", "", "The .ics file to write to. Defaults to stdout.",
+	)
+	exportICalCmd.Flags().StringP(
+		"name", "n", "Movie Diary", "The name of the calendar.",
+	)
+
+	exportCanvasCmd.Flags().StringP(
+		"output", "o", "", "The .canvas file to write to. Defaults to stdout.",
+	)
+	exportCanvasCmd.Flags().IntP(
+		"year", "y", 0, "Only movies watched this year. 0 means any year.",
+	)
+	exportCanvasCmd.Flags().StringP(
+		"genre", "g", "", "Only movies in this genre.",
+	)
+	exportCanvasCmd.Flags().StringP(
+		"flag", "f", "",
+		"Only movies with a watch with this flag, like slasher or joe_bob.",
+	)
+	exportCanvasCmd.Flags().IntP(
+		"min-shared", "m", 1,
+		"How many people two movies need in common to be connected.",
+	)
+}
+
+// openExportOutput opens the output file, or stdout if there isn't one.
+func openExportOutput(output string) (io.WriteCloser, error) {
+	if output == "" {
+		return os.Stdout, nil
+	}
+	return os.Create(output)
+}
+
+func exportFeed(cmd *cobra.Command, args []string) {
+	limit, err := cmd.Flags().GetInt("limit")
+	if err != nil {
+		log.Panicf("Error obtaining limit: %v", err)
+	}
+	if limit < 1 {
+		log.Panicf("limit must be > 0, got %v", limit)
+	}
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		log.Panicf("Error obtaining output: %v", err)
+	}
+	options := FeedOptions{}
+	if options.Title, err = cmd.Flags().GetString("title"); err != nil {
+		log.Panicf("Error obtaining title: %v", err)
+	}
+	if options.Author, err = cmd.Flags().GetString("author"); err != nil {
+		log.Panicf("Error obtaining author: %v", err)
+	}
+	if options.Url, err = cmd.Flags().GetString("url"); err != nil {
+		log.Panicf("Error obtaining url: %v", err)
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	watches, err := queries.GetRecentWatches(ctx, int64(limit))
+	if err != nil {
+		log.Panicf("Error getting recent watches: %v", err)
+	}
+
+	writer, err := openExportOutput(output)
+	if err != nil {
+		log.Panicf("Error opening %v: %v", output, err)
+	}
+	defer writer.Close()
+	if err := WriteAtomFeed(writer, CreateAtomFeed(watches, &options)); err != nil {
+		log.Panicf("Error writing feed: %v", err)
+	}
+}
+
+func exportICal(cmd *cobra.Command, args []string) {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		log.Panicf("Error obtaining output: %v", err)
+	}
+	name, err := cmd.Flags().GetString("name")
+	if err != nil {
+		log.Panicf("Error obtaining name: %v", err)
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	watches, err := queries.GetWatchesForCalendar(ctx)
+	if err != nil {
+		log.Panicf("Error getting movie watches: %v", err)
+	}
+	events := make([]*ICalEvent, len(watches))
+	exported := time.Now()
+	for ii := range watches {
+		if events[ii], err = CreateICalEvent(&watches[ii], exported); err != nil {
+			log.Panicf("Error creating event: %v", err)
+		}
+	}
+
+	writer, err := openExportOutput(output)
+	if err != nil {
+		log.Panicf("Error opening %v: %v", output, err)
+	}
+	defer writer.Close()
+	if err := WriteICalendar(writer, name, events); err != nil {
+		log.Panicf("Error writing calendar: %v", err)
+	}
+	if output != "" {
+		log.Printf("Wrote %v events to %v.", len(events), output)
+	}
+}
+
+func exportCanvas(cmd *cobra.Command, args []string) {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		log.Panicf("Error obtaining output: %v", err)
+	}
+	filters := CanvasFilters{}
+	if filters.Year, err = cmd.Flags().GetInt("year"); err != nil {
+		log.Panicf("Error obtaining year: %v", err)
+	}
+	if filters.Genre, err = cmd.Flags().GetString("genre"); err != nil {
+		log.Panicf("Error obtaining genre: %v", err)
+	}
+	if filters.Flag, err = cmd.Flags().GetString("flag"); err != nil {
+		log.Panicf("Error obtaining flag: %v", err)
+	}
+	filters.MinSharedCredits, err = cmd.Flags().GetInt("min-shared")
+	if err != nil {
+		log.Panicf("Error obtaining min-shared: %v", err)
+	}
+	if filters.MinSharedCredits < 1 {
+		log.Panicf("min-shared must be > 0, got %v", filters.MinSharedCredits)
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		log.Panicf("Error loading genre taxonomy: %v", err)
+	}
+	if filters.Genre != "" {
+		filters.Genre = taxonomy.Canonical(filters.Genre)
+	}
+
+	movies, err := LoadCanvasMovies(ctx, queries)
+	if err != nil {
+		log.Panicf("Error loading movies: %v", err)
+	}
+	canvas, err := CreateCanvas(movies, &filters)
+	if err != nil {
+		log.Panicf("Error creating canvas: %v", err)
+	}
+
+	writer, err := openExportOutput(output)
+	if err != nil {
+		log.Panicf("Error opening %v: %v", output, err)
+	}
+	defer writer.Close()
+	if err := WriteCanvas(writer, canvas); err != nil {
+		log.Panicf("Error writing canvas: %v", err)
+	}
+	if output != "" {
+		log.Printf(
+			"Wrote %v movies and %v connections to %v.",
+			len(canvas.Nodes), len(canvas.Edges), output,
+		)
+	}
+}
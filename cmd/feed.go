@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/timothyrenner/movies-app/database"
+)
+
+const DEFAULT_FEED_SIZE = 20
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type AtomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type AtomEntry struct {
+	Id         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Links      []AtomLink     `xml:"link"`
+	Categories []AtomCategory `xml:"category"`
+	Summary    *AtomText      `xml:"summary,omitempty"`
+	Content    *AtomText      `xml:"content,omitempty"`
+}
+
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  AtomPerson  `xml:"author"`
+	Links   []AtomLink  `xml:"link"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+type FeedOptions struct {
+	Title  string
+	Author string
+	// Where the feed itself lives, if known. It doubles as the feed id.
+	Url string
+}
+
+// Watches only have a date, so entries are dated midnight UTC.
+func atomDate(watched string) string {
+	return watched + "T00:00:00Z"
+}
+
+func CreateAtomEntry(watch *database.GetRecentWatchesRow) AtomEntry {
+	title := fmt.Sprintf("%v (%v)", watch.Title, watch.Year)
+	category := "first-time"
+	watchedOn := fmt.Sprintf("Watched on %v.", watch.Service)
+	if watch.FirstTime == 0 {
+		title += " (rewatch)"
+		category = "rewatch"
+		watchedOn = fmt.Sprintf("Rewatched on %v.", watch.Service)
+	}
+
+	paragraphs := make([]string, 0)
+	if watch.Plot.Valid {
+		paragraphs = append(paragraphs, watch.Plot.String)
+	}
+	paragraphs = append(paragraphs, watchedOn)
+	if watch.Review.Valid {
+		if watch.Liked.Int64 != 0 {
+			paragraphs = append(paragraphs, "Liked it.")
+		} else {
+			paragraphs = append(paragraphs, "Didn't like it.")
+		}
+		for _, line := range strings.Split(watch.Review.String, "\n") {
+			if strings.TrimSpace(line) != "" {
+				paragraphs = append(paragraphs, line)
+			}
+		}
+	}
+	var content strings.Builder
+	for ii := range paragraphs {
+		content.WriteString("<p>")
+		content.WriteString(html.EscapeString(paragraphs[ii]))
+		content.WriteString("</p>")
+	}
+
+	entry := AtomEntry{
+		Id:      "urn:uuid:" + watch.Uuid,
+		Title:   title,
+		Updated: atomDate(watch.Watched),
+		Links: []AtomLink{
+			{Href: watch.ImdbLink, Rel: "alternate", Type: "text/html"},
+		},
+		Categories: []AtomCategory{{Term: category}},
+		Content:    &AtomText{Type: "html", Body: content.String()},
+	}
+	if watch.Plot.Valid {
+		entry.Summary = &AtomText{Type: "text", Body: watch.Plot.String}
+	}
+	return entry
+}
+
+// CreateAtomFeed expects the watches newest first, like GetRecentWatches
+// returns them.
+func CreateAtomFeed(
+	watches []database.GetRecentWatchesRow, options *FeedOptions,
+) *AtomFeed {
+	feed := AtomFeed{
+		Id:      options.Url,
+		Title:   options.Title,
+		Author:  AtomPerson{Name: options.Author},
+		Updated: "1970-01-01T00:00:00Z",
+		Entries: make([]AtomEntry, len(watches)),
+	}
+	if feed.Id == "" {
+		feed.Id = "urn:movies-app:watches"
+	}
+	if feed.Author.Name == "" {
+		feed.Author.Name = options.Title
+	}
+	if options.Url != "" {
+		feed.Links = append(feed.Links, AtomLink{
+			Href: options.Url, Rel: "self", Type: "application/atom+xml",
+		})
+	}
+	if len(watches) > 0 {
+		feed.Updated = atomDate(watches[0].Watched)
+	}
+	for ii := range watches {
+		feed.Entries[ii] = CreateAtomEntry(&watches[ii])
+	}
+	return &feed
+}
+
+func WriteAtomFeed(writer io.Writer, feed *AtomFeed) error {
+	feedBytes, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding feed: %v", err)
+	}
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return fmt.Errorf("error writing feed: %v", err)
+	}
+	if _, err := writer.Write(feedBytes); err != nil {
+		return fmt.Errorf("error writing feed: %v", err)
+	}
+	if _, err := io.WriteString(writer, "\n"); err != nil {
+		return fmt.Errorf("error writing feed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestCreateAtomEntry(t *testing.T) {
+	watch := database.GetRecentWatchesRow{
+		Uuid:      "abc",
+		Watched:   "2022-10-31",
+		Service:   "Shudder",
+		FirstTime: 0,
+		Title:     "Tenebrae",
+		ImdbID:    "tt0084777",
+		ImdbLink:  "https://www.imdb.com/title/tt0084777/",
+		Year:      1982,
+		Plot:      sql.NullString{String: "A writer is stalked.", Valid: true},
+		Review:    sql.NullString{String: "Razor <sharp>.", Valid: true},
+		Liked:     sql.NullInt64{Int64: 1, Valid: true},
+	}
+	truth := AtomEntry{
+		Id:      "urn:uuid:abc",
+		Title:   "Tenebrae (1982) (rewatch)",
+		Updated: "2022-10-31T00:00:00Z",
+		Links: []AtomLink{
+			{
+				Href: "https://www.imdb.com/title/tt0084777/",
+				Rel:  "alternate",
+				Type: "text/html",
+			},
+		},
+		Categories: []AtomCategory{{Term: "rewatch"}},
+		Summary:    &AtomText{Type: "text", Body: "A writer is stalked."},
+		Content: &AtomText{
+			Type: "html",
+			Body: "<p>A writer is stalked.</p><p>Rewatched on Shudder.</p>" +
+				"<p>Liked it.</p><p>Razor &lt;sharp&gt;.</p>",
+		},
+	}
+	answer := CreateAtomEntry(&watch)
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected %v, got %v", truth, answer)
+	}
+}
+
+func TestFeed(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	movieDetails, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	for _, watched := range []string{"2022-05-27", "2022-10-31", "2023-01-01"} {
+		movieWatch := sampleMovieWatchPage()
+		movieWatch.Watched = watched
+		if err := queries.InsertMovieWatch(
+			ctx, *CreateInsertMovieWatchParams(movieWatch, movieDetails.Movie),
+		); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+
+	watches, err := queries.GetRecentWatches(ctx, 2)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	var body bytes.Buffer
+	if err := WriteAtomFeed(
+		&body, CreateAtomFeed(watches, &FeedOptions{Title: "Movie Diary"}),
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	feed := AtomFeed{}
+	if err := xml.Unmarshal(body.Bytes(), &feed); err != nil {
+		t.Fatalf("Error parsing feed: %v", err)
+	}
+	if feed.Updated != "2023-01-01T00:00:00Z" || feed.Author.Name != "Movie Diary" {
+		t.Errorf("Unexpected feed %v", feed)
+	}
+	updated := make([]string, len(feed.Entries))
+	for ii := range feed.Entries {
+		updated[ii] = feed.Entries[ii].Updated
+	}
+	updatedTruth := []string{"2023-01-01T00:00:00Z", "2022-10-31T00:00:00Z"}
+	if !cmp.Equal(updatedTruth, updated) {
+		t.Errorf("Expected %v, got %v", updatedTruth, updated)
+	}
+
+	handler := NewApiHandler(queries)
+	recorder := getJson(t, handler, "/feed.xml?limit=1", nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v", recorder.Code)
+	}
+	if recorder.Header().Get("Content-Type") != "application/atom+xml" {
+		t.Errorf(
+			"Expected an Atom content type, got %v",
+			recorder.Header().Get("Content-Type"),
+		)
+	}
+	served := AtomFeed{}
+	if err := xml.Unmarshal(recorder.Body.Bytes(), &served); err != nil {
+		t.Fatalf("Error parsing feed: %v", err)
+	}
+	if len(served.Entries) != 1 || served.Id != "http://example.com/feed.xml" {
+		t.Errorf("Unexpected feed %v", served)
+	}
+}
+
+func TestGetRecentWatchesReviews(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	movieDetails, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	insertReview := func(review string, movieWatchUuid string) {
+		params := CreateInsertMovieReviewParams(
+			&MovieReviewPage{MovieTitle: "Tenebrae", Review: review},
+			movieDetails.Movie, movieWatchUuid,
+		)
+		if err := queries.InsertReview(ctx, *params); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+	// The review of the movie goes in first, so the fallback can't just be
+	// the latest one.
+	insertReview("Argento's sleekest giallo.", "")
+	reviews := map[string]string{
+		"2022-05-27": "Razor sharp.",
+		"2022-10-31": "Even better at midnight.",
+	}
+	for _, watched := range []string{"2022-05-27", "2022-10-31", "2023-01-01"} {
+		movieWatch := sampleMovieWatchPage()
+		movieWatch.Watched = watched
+		params := CreateInsertMovieWatchParams(movieWatch, movieDetails.Movie)
+		if err := queries.InsertMovieWatch(ctx, *params); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		if review, ok := reviews[watched]; ok {
+			insertReview(review, params.Uuid)
+		}
+	}
+
+	watches, err := queries.GetRecentWatches(ctx, 3)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	answer := make(map[string]string)
+	for ii := range watches {
+		answer[watches[ii].Watched] = watches[ii].Review.String
+	}
+	truth := map[string]string{
+		"2022-05-27": "Razor sharp.",
+		"2022-10-31": "Even better at midnight.",
+		"2023-01-01": "Argento's sleekest giallo.",
+	}
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected %v, got %v", truth, answer)
+	}
+}
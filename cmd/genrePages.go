@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"text/template"
+
+	"github.com/timothyrenner/movies-app/database"
+)
+
+var GENRE_TEMPLATE = `
+# {{.Name}}
+
+## Data
+name:: {{.Name}}
+slug:: {{.Slug}}
+movies:: {{len .Movies}}
+watches:: {{.WatchCount}}
+last_watched:: {{with .LastWatched}}[[{{.}}]]{{end}}
+
+## Movies
+{{range .Movies}}- [[{{.FileTitle}} ({{.ImdbId}})]] ({{.Year}}): watched {{len .Watched}} {{if eq (len .Watched) 1}}time{{else}}times{{end}}{{range .Watched}} [[{{.}}]]{{end}}
+{{end}}
+## Tags
+#genre
+#{{.Slug}}
+
+## Notes
+{{.Notes}}`
+
+type GenreMovie struct {
+	Title     string
+	FileTitle string
+	ImdbId    string
+	Year      int
+	Watched   []string
+}
+
+type GenrePage struct {
+	Name   string
+	Slug   string
+	Movies []GenreMovie
+	Notes  string
+}
+
+func (p *GenrePage) WatchCount() int {
+	watchCount := 0
+	for ii := range p.Movies {
+		watchCount += len(p.Movies[ii].Watched)
+	}
+	return watchCount
+}
+
+// LastWatched is the most recent watch date of any movie in the genre, or an
+// empty string if none of them have been watched.
+func (p *GenrePage) LastWatched() string {
+	lastWatched := ""
+	for ii := range p.Movies {
+		for jj := range p.Movies[ii].Watched {
+			if p.Movies[ii].Watched[jj] > lastWatched {
+				lastWatched = p.Movies[ii].Watched[jj]
+			}
+		}
+	}
+	return lastWatched
+}
+
+func CreateGenrePage(
+	name string,
+	slug string,
+	movies []database.GetMoviesForGenreRow,
+	watchDates map[string][]string,
+	vaultNames VaultNames,
+) *GenrePage {
+	page := GenrePage{
+		Name: name, Slug: slug, Movies: make([]GenreMovie, len(movies)),
+	}
+	for ii := range movies {
+		page.Movies[ii] = GenreMovie{
+			Title:     movies[ii].Title,
+			FileTitle: vaultNames.FileTitle(movies[ii].ImdbID, movies[ii].Title),
+			ImdbId:    movies[ii].ImdbID,
+			Year:      int(movies[ii].Year),
+			Watched:   watchDates[movies[ii].Uuid],
+		}
+	}
+	return &page
+}
+
+// GetGenreNames returns the unique genres for the movies, in the order
+// they're first seen.
+func GetGenreNames(
+	ctx context.Context, queries *database.Queries, movieUuids []string,
+) ([]string, error) {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for ii := range movieUuids {
+		genres, err := queries.GetGenreNamesForMovie(ctx, movieUuids[ii])
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error getting genres for %v: %v", movieUuids[ii], err,
+			)
+		}
+		for jj := range genres {
+			if !seen[genres[jj]] {
+				seen[genres[jj]] = true
+				names = append(names, genres[jj])
+			}
+		}
+	}
+	return names, nil
+}
+
+// WriteGenrePage renders the index page for a genre and its slug into
+// genresDir. Watch dates are cached by movie uuid across calls, same as
+// WritePersonPage.
+func WriteGenrePage(
+	ctx context.Context,
+	queries *database.Queries,
+	vaultNames VaultNames,
+	genreTemplate *template.Template,
+	genresDir string,
+	name string,
+	slug string,
+	watchDates map[string][]string,
+) error {
+	movies, err := queries.GetMoviesForGenre(ctx, name)
+	if err != nil {
+		return fmt.Errorf("error getting movies for genre %v: %v", name, err)
+	}
+	for ii := range movies {
+		if _, ok := watchDates[movies[ii].Uuid]; ok {
+			continue
+		}
+		watched, err := queries.GetWatchDatesForMovie(ctx, movies[ii].Uuid)
+		if err != nil {
+			return fmt.Errorf(
+				"error getting watch dates for %v: %v", movies[ii].Uuid, err,
+			)
+		}
+		watchDates[movies[ii].Uuid] = watched
+	}
+
+	page := CreateGenrePage(name, slug, movies, watchDates, vaultNames)
+
+	filePath := path.Join(genresDir, fmt.Sprintf("%v.md", cleanTitle(name)))
+	notes, err := ReadPreservedNotes(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading notes for %v: %v", name, err)
+	}
+	page.Notes = notes
+
+	rendered, err := renderPage(genreTemplate, page)
+	if err != nil {
+		return fmt.Errorf("error rendering genre page %v: %v", filePath, err)
+	}
+	return WriteFileAtomically(filePath, rendered)
+}
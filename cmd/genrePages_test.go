@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestCreateGenrePage(t *testing.T) {
+	movies := []database.GetMoviesForGenreRow{
+		{Uuid: "a", Title: "Suspiria", ImdbID: "tt0076786", Year: 1977},
+		{Uuid: "b", Title: "Tenebrae", ImdbID: "tt0084777", Year: 1982},
+	}
+	watchDates := map[string][]string{
+		"a": {"2021-10-31"},
+		"b": {"2022-05-27", "2022-10-31"},
+	}
+
+	// Links go by the saved file title, not the title.
+	vaultNames := VaultNames{"tt0084777": "Tenebrae (2)"}
+
+	answer := CreateGenrePage("Horror", "horror", movies, watchDates, vaultNames)
+	truth := &GenrePage{
+		Name: "Horror",
+		Slug: "horror",
+		Movies: []GenreMovie{
+			{
+				Title:     "Suspiria",
+				FileTitle: "Suspiria",
+				ImdbId:    "tt0076786",
+				Year:      1977,
+				Watched:   []string{"2021-10-31"},
+			}, {
+				Title:     "Tenebrae",
+				FileTitle: "Tenebrae (2)",
+				ImdbId:    "tt0084777",
+				Year:      1982,
+				Watched:   []string{"2022-05-27", "2022-10-31"},
+			},
+		},
+	}
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected \n%v, got \n%v", truth, answer)
+	}
+	if answer.WatchCount() != 3 {
+		t.Errorf("Expected 3 watches, got %v", answer.WatchCount())
+	}
+	if answer.LastWatched() != "2022-10-31" {
+		t.Errorf("Expected 2022-10-31, got %v", answer.LastWatched())
+	}
+}
+
+func TestWriteGenrePage(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	moviePage := sampleMoviePage()
+	moviePage.Genres = []string{"Horror", "Science Fiction"}
+	movieDetails, err := InsertMovieDetails(db, ctx, queries, moviePage, nil)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if err := queries.InsertMovieWatch(
+		ctx,
+		*CreateInsertMovieWatchParams(sampleMovieWatchPage(), movieDetails.Movie),
+	); err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+
+	genresDir, err := os.MkdirTemp(".", "test_genres")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(genresDir)
+
+	vaultNames, err := LoadVaultNames(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	genreTemplate := template.Must(template.New("genre").Parse(GENRE_TEMPLATE))
+	if err := WriteGenrePage(
+		ctx, queries, vaultNames, genreTemplate, genresDir, "Science Fiction",
+		"science-fiction", make(map[string][]string),
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	pageText, err := os.ReadFile(path.Join(genresDir, "Science Fiction.md"))
+	if err != nil {
+		t.Fatalf("Error reading page: %v", err)
+	}
+	lines := []string{
+		"slug:: science-fiction",
+		"watches:: 1",
+		"last_watched:: [[2022-05-27]]",
+		"- [[Tenebrae (tt0084777)]] (1982): watched 1 time [[2022-05-27]]",
+		"#science-fiction",
+	}
+	for ii := range lines {
+		if !strings.Contains(string(pageText), lines[ii]) {
+			t.Errorf(
+				"Expected page to contain %v, got \n%v",
+				lines[ii], string(pageText),
+			)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/timothyrenner/movies-app/database"
+)
+
+var slugSeparatorRegex = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// GenreSlug turns a genre name into something that's safe to use as an
+// Obsidian tag, e.g. "Science Fiction" becomes "science-fiction".
+func GenreSlug(name string) string {
+	slug := slugSeparatorRegex.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(slug, "-")
+}
+
+type GenreTaxonomy struct {
+	// Lower cased names and aliases to canonical names.
+	canonical map[string]string
+	// Canonical names to slugs.
+	slugs map[string]string
+}
+
+func NewGenreTaxonomy() *GenreTaxonomy {
+	return &GenreTaxonomy{
+		canonical: make(map[string]string),
+		slugs:     make(map[string]string),
+	}
+}
+
+func (t *GenreTaxonomy) AddGenre(name string, slug string) {
+	t.canonical[strings.ToLower(name)] = name
+	t.slugs[name] = slug
+}
+
+func (t *GenreTaxonomy) AddAlias(alias string, name string) {
+	t.canonical[strings.ToLower(alias)] = name
+}
+
+// LoadGenreTaxonomy reads the taxonomy from the genre and genre_alias tables.
+// The add-genre-taxonomy migration seeds them with OMDB's genres.
+func LoadGenreTaxonomy(
+	ctx context.Context, queries *database.Queries,
+) (*GenreTaxonomy, error) {
+	genres, err := queries.GetAllGenres(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting genres: %v", err)
+	}
+	aliases, err := queries.GetAllGenreAliases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting genre aliases: %v", err)
+	}
+
+	taxonomy := NewGenreTaxonomy()
+	genreNames := make(map[int64]string)
+	for ii := range genres {
+		taxonomy.AddGenre(genres[ii].Name, genres[ii].Slug)
+		genreNames[genres[ii].ID] = genres[ii].Name
+	}
+	for ii := range aliases {
+		name, ok := genreNames[aliases[ii].GenreID]
+		if !ok {
+			return nil, fmt.Errorf(
+				"alias %v points to missing genre %v",
+				aliases[ii].Alias, aliases[ii].GenreID,
+			)
+		}
+		taxonomy.AddAlias(aliases[ii].Alias, name)
+	}
+	return taxonomy, nil
+}
+
+// Canonical returns the canonical name for a genre, or the trimmed genre
+// itself if the taxonomy doesn't know about it.
+func (t *GenreTaxonomy) Canonical(genre string) string {
+	genre = strings.TrimSpace(genre)
+	if name, ok := t.canonical[strings.ToLower(genre)]; ok {
+		return name
+	}
+	return genre
+}
+
+// Normalize maps genres to their canonical names, dropping empties and
+// duplicates.
+func (t *GenreTaxonomy) Normalize(genres []string) []string {
+	normalized := make([]string, 0, len(genres))
+	seen := make(map[string]bool)
+	for ii := range genres {
+		name := t.Canonical(genres[ii])
+		if name == "" || name == "N/A" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// Slug is the slug for the genre's canonical name, or a new one if the
+// taxonomy doesn't know about it.
+func (t *GenreTaxonomy) Slug(genre string) string {
+	name := t.Canonical(genre)
+	if slug, ok := t.slugs[name]; ok {
+		return slug
+	}
+	return GenreSlug(name)
+}
+
+// Tags are the tag-safe slugs for the genres.
+func (t *GenreTaxonomy) Tags(genres []string) []string {
+	tags := make([]string, len(genres))
+	for ii := range genres {
+		tags[ii] = t.Slug(genres[ii])
+	}
+	return tags
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestGenreSlug(t *testing.T) {
+	truth := map[string]string{
+		"Science Fiction": "science-fiction",
+		"Sci-Fi":          "sci-fi",
+		"Film Noir":       "film-noir",
+		" Reality TV ":    "reality-tv",
+		"Horror":          "horror",
+	}
+	for name, slug := range truth {
+		if answer := GenreSlug(name); answer != slug {
+			t.Errorf("Expected %v for %v, got %v", slug, name, answer)
+		}
+	}
+}
+
+func sampleGenreTaxonomy() *GenreTaxonomy {
+	taxonomy := NewGenreTaxonomy()
+	for _, name := range []string{
+		"Film Noir", "Horror", "Mystery", "Science Fiction", "Thriller",
+	} {
+		taxonomy.AddGenre(name, GenreSlug(name))
+	}
+	taxonomy.AddAlias("Sci-Fi", "Science Fiction")
+	taxonomy.AddAlias("Film-Noir", "Film Noir")
+	return taxonomy
+}
+
+func TestGenreTaxonomyNormalize(t *testing.T) {
+	taxonomy := sampleGenreTaxonomy()
+	answer := taxonomy.Normalize(
+		[]string{"Horror", "Sci-Fi", "sci-fi", "Film-Noir", "N/A", "Giallo"},
+	)
+	truth := []string{"Horror", "Science Fiction", "Film Noir", "Giallo"}
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected %v, got %v", truth, answer)
+	}
+	if slug := taxonomy.Slug("Sci-Fi"); slug != "science-fiction" {
+		t.Errorf("Expected science-fiction, got %v", slug)
+	}
+	tags := taxonomy.Tags([]string{"Sci-Fi", "Giallo"})
+	if !cmp.Equal([]string{"science-fiction", "giallo"}, tags) {
+		t.Errorf("Expected science-fiction and giallo, got %v", tags)
+	}
+}
+
+func TestLoadGenreTaxonomy(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	// The migration seeds OMDB's genres and the aliases it uses for them.
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	truth := map[string]string{
+		"Horror":     "Horror",
+		"sci-fi":     "Science Fiction",
+		"SciFi":      "Science Fiction",
+		"Film-Noir":  "Film Noir",
+		"Reality-TV": "Reality TV",
+		"Western":    "Western",
+	}
+	for genre, name := range truth {
+		if answer := taxonomy.Canonical(genre); answer != name {
+			t.Errorf("Expected %v for %v, got %v", name, genre, answer)
+		}
+	}
+	if slug := taxonomy.Slug("Game-Show"); slug != "game-show" {
+		t.Errorf("Expected game-show, got %v", slug)
+	}
+}
+
+func TestRenameAndAliasGenre(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	moviePage := sampleMoviePage()
+	moviePage.Genres = []string{"Horror", "Giallo"}
+	movieDetails, err := InsertMovieDetails(db, ctx, queries, moviePage, nil)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	if err := queries.InsertGenre(ctx, database.InsertGenreParams{
+		Name: "Italian Thriller", Slug: "italian-thriller",
+	}); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := AliasGenre(ctx, queries, "Giallo", "Italian Thriller"); err == nil {
+		t.Errorf("Expected an error aliasing an existing genre")
+	}
+	if err := RenameGenre(ctx, queries, "Giallo", "Gialli"); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := AliasGenre(ctx, queries, "Gialli", "Italian Thriller"); err == nil {
+		t.Errorf("Expected an error aliasing an existing genre")
+	}
+
+	genres, err := queries.GetGenreNamesForMovie(ctx, movieDetails.Movie)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	genresTruth := []string{"Horror", "Gialli"}
+	if !cmp.Equal(genresTruth, genres) {
+		t.Errorf("Expected %v, got %v", genresTruth, genres)
+	}
+
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if name := taxonomy.Canonical("giallo"); name != "Gialli" {
+		t.Errorf("Expected Gialli, got %v", name)
+	}
+	if slug := taxonomy.Slug("Giallo"); slug != "gialli" {
+		t.Errorf("Expected gialli, got %v", slug)
+	}
+}
+
+func TestAliasGenreCollision(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	moviePage := sampleMoviePage()
+	moviePage.Genres = []string{"Horror", "Thriller"}
+	movieDetails, err := InsertMovieDetails(db, ctx, queries, moviePage, nil)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	// From before the taxonomy, so it isn't a genre of its own.
+	if err := queries.InsertMovieGenre(ctx, database.InsertMovieGenreParams{
+		Uuid: "legacy", MovieUuid: movieDetails.Movie, Name: "Thrillers",
+	}); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	if err := AliasGenre(ctx, queries, "Thrillers", "Thriller"); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	genres, err := queries.GetGenreNamesForMovie(ctx, movieDetails.Movie)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	genresTruth := []string{"Horror", "Thriller"}
+	if !cmp.Equal(genresTruth, genres) {
+		t.Errorf("Expected %v, got %v", genresTruth, genres)
+	}
+
+	// And the table won't take a second one.
+	if err := queries.InsertMovieGenre(ctx, database.InsertMovieGenreParams{
+		Uuid: "again", MovieUuid: movieDetails.Movie, Name: "Horror",
+	}); err == nil {
+		t.Error("Expected an error inserting a genre twice")
+	}
+}
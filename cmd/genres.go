@@ -0,0 +1,216 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// genresCmd represents the genres command
+var genresCmd = &cobra.Command{
+	Use:   "genres",
+	Short: "Lists and manages the genre taxonomy.",
+}
+
+var genresListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists genres with their slugs, aliases and movie counts.",
+	Run:   genresList,
+	Args:  cobra.NoArgs,
+}
+
+var genresAliasCmd = &cobra.Command{
+	Use:   "alias <alias> <genre>",
+	Short: "Maps an alias onto a canonical genre.",
+	Run:   genresAlias,
+	Args:  cobra.ExactArgs(2),
+}
+
+var genresRenameCmd = &cobra.Command{
+	Use:   "rename <genre> <new-name>",
+	Short: "Renames a genre, keeping the old name as an alias.",
+	Run:   genresRename,
+	Args:  cobra.ExactArgs(2),
+}
+
+func init() {
+	rootCmd.AddCommand(genresCmd)
+	genresCmd.AddCommand(genresListCmd)
+	genresCmd.AddCommand(genresAliasCmd)
+	genresCmd.AddCommand(genresRenameCmd)
+}
+
+func genresList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	genres, err := queries.ListGenres(ctx)
+	if err != nil {
+		log.Panicf("Error listing genres: %v", err)
+	}
+	aliases, err := queries.GetAllGenreAliases(ctx)
+	if err != nil {
+		log.Panicf("Error getting genre aliases: %v", err)
+	}
+	genreAliases := make(map[int64][]string)
+	for ii := range aliases {
+		genreAliases[aliases[ii].GenreID] = append(
+			genreAliases[aliases[ii].GenreID], aliases[ii].Alias,
+		)
+	}
+	for ii := range genres {
+		line := fmt.Sprintf(
+			"%v\t#%v\t%v movies", genres[ii].Name, genres[ii].Slug,
+			genres[ii].NumMovies,
+		)
+		if len(genreAliases[genres[ii].ID]) > 0 {
+			line = fmt.Sprintf(
+				"%v\taka %v", line,
+				strings.Join(genreAliases[genres[ii].ID], "; "),
+			)
+		}
+		fmt.Println(line)
+	}
+}
+
+// renameMovieGenres files every movie under from as to instead. Movies that
+// are already under to just lose from, rather than getting to twice.
+func renameMovieGenres(
+	ctx context.Context, queries *database.Queries, from string, to string,
+) error {
+	if err := queries.DeleteCollidingMovieGenres(
+		ctx, database.DeleteCollidingMovieGenresParams{Name: from, Name_2: to},
+	); err != nil {
+		return err
+	}
+	return queries.RenameMovieGenres(ctx, database.RenameMovieGenresParams{
+		Name: to, Name_2: from,
+	})
+}
+
+// AliasGenre maps alias onto the genre, moving any movies already filed
+// under the alias over to the genre.
+func AliasGenre(
+	ctx context.Context, queries *database.Queries, alias string, genre string,
+) error {
+	genreID, err := queries.FindGenreByName(ctx, genre)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no genre named %v", genre)
+	} else if err != nil {
+		return fmt.Errorf("error finding genre %v: %v", genre, err)
+	}
+	if _, err := queries.FindGenreByName(ctx, alias); err == nil {
+		return fmt.Errorf("%v is already a genre, rename it instead", alias)
+	} else if err != sql.ErrNoRows {
+		return fmt.Errorf("error finding genre %v: %v", alias, err)
+	}
+	if err := queries.InsertGenreAlias(ctx, database.InsertGenreAliasParams{
+		Alias: alias, GenreID: genreID,
+	}); err != nil {
+		return fmt.Errorf("error inserting alias %v: %v", alias, err)
+	}
+	if err := renameMovieGenres(ctx, queries, alias, genre); err != nil {
+		return fmt.Errorf("error updating movies for %v: %v", alias, err)
+	}
+	return nil
+}
+
+func genresAlias(cmd *cobra.Command, args []string) {
+	alias := strings.TrimSpace(args[0])
+	genre := strings.TrimSpace(args[1])
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Panicf("Error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	if err := AliasGenre(ctx, queries.WithTx(tx), alias, genre); err != nil {
+		log.Panicf("Error aliasing %v to %v: %v", alias, genre, err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Panicf("Error committing transaction: %v", err)
+	}
+	log.Printf("Aliased %v to %v.", alias, genre)
+}
+
+// RenameGenre renames the genre and its slug, updates the movies filed under
+// it and keeps the old name around as an alias.
+func RenameGenre(
+	ctx context.Context, queries *database.Queries, genre string, name string,
+) error {
+	genreID, err := queries.FindGenreByName(ctx, genre)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no genre named %v", genre)
+	} else if err != nil {
+		return fmt.Errorf("error finding genre %v: %v", genre, err)
+	}
+	if _, err := queries.FindGenreByName(ctx, name); err == nil {
+		return fmt.Errorf("%v is already a genre", name)
+	} else if err != sql.ErrNoRows {
+		return fmt.Errorf("error finding genre %v: %v", name, err)
+	}
+	if err := queries.UpdateGenre(ctx, database.UpdateGenreParams{
+		Name: name, Slug: GenreSlug(name), ID: genreID,
+	}); err != nil {
+		return fmt.Errorf("error renaming genre %v: %v", genre, err)
+	}
+	if err := renameMovieGenres(ctx, queries, genre, name); err != nil {
+		return fmt.Errorf("error updating movies for %v: %v", genre, err)
+	}
+	if err := queries.InsertGenreAlias(ctx, database.InsertGenreAliasParams{
+		Alias: genre, GenreID: genreID,
+	}); err != nil {
+		return fmt.Errorf("error inserting alias %v: %v", genre, err)
+	}
+	return nil
+}
+
+func genresRename(cmd *cobra.Command, args []string) {
+	genre := strings.TrimSpace(args[0])
+	name := strings.TrimSpace(args[1])
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Panicf("Error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	if err := RenameGenre(ctx, queries.WithTx(tx), genre, name); err != nil {
+		log.Panicf("Error renaming %v to %v: %v", genre, name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Panicf("Error committing transaction: %v", err)
+	}
+	log.Printf(
+		"Renamed %v to %v. Rebuild the vault with --force to update pages.",
+		genre, name,
+	)
+}
@@ -48,6 +48,10 @@ func ParseReleased(releasedString string) (string, error) {
 }
 
 func SplitOnCommaAndTrim(toSplit string) []string {
+	// Otherwise an empty field is a list with one empty name in it.
+	if strings.TrimSpace(toSplit) == "" {
+		return nil
+	}
 	splitStrings := strings.Split(toSplit, ",")
 	stringSlice := make([]string, len(splitStrings))
 	for ii := range stringSlice {
@@ -58,3 +62,35 @@ func SplitOnCommaAndTrim(toSplit string) []string {
 	}
 	return stringSlice
 }
+
+var ratingRegex = regexp.MustCompile(`^\s*([0-9]+(?:\.[0-9]+)?)\s*(?:%|/\s*[0-9]+(?:\.[0-9]+)?)?\s*$`)
+
+// ParseRatingValue pulls the numeric part out of an OMDB rating value, so
+// "7.0/10" is 7.0, "77%" is 77 and "83/100" is 83. The scale is left alone,
+// which means values are only comparable within a single source.
+func ParseRatingValue(ratingString string) (float64, error) {
+	ratingMatch := ratingRegex.FindStringSubmatch(ratingString)
+	if ratingMatch == nil {
+		return 0, fmt.Errorf("unable to parse rating %v", ratingString)
+	}
+	rating, err := strconv.ParseFloat(ratingMatch[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf(
+			"error converting match %v to float: %v", ratingMatch[1], err,
+		)
+	}
+	return rating, nil
+}
+
+var trailingParentheticalRegex = regexp.MustCompile(`\s+\([^()]*\)$`)
+
+// NormalizePersonName strips the things OMDB (and SplitOnCommaAndTrim) leave
+// on credited names, like "(uncredited)", "(novel)" and stray brackets, so
+// the same person gets the same name across movies.
+func NormalizePersonName(name string) string {
+	name = strings.ReplaceAll(name, "[", "")
+	name = strings.ReplaceAll(name, "]", "")
+	name = strings.TrimSpace(name)
+	name = trailingParentheticalRegex.ReplaceAllString(name, "")
+	return strings.Join(strings.Fields(name), " ")
+}
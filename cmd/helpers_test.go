@@ -72,4 +72,45 @@ func TestSplitOnCommaAndTrim(t *testing.T) {
 	if !cmp.Equal(truth, answer) {
 		t.Errorf("Expected %v, got %v", truth, answer)
 	}
+
+	if answer := SplitOnCommaAndTrim(" "); answer != nil {
+		t.Errorf("Expected nil, got %v", answer)
+	}
+}
+
+func TestParseRatingValue(t *testing.T) {
+	ratings := map[string]float64{
+		"7.0/10": 7.0,
+		"77%":    77,
+		"83/100": 83,
+	}
+	for rating, truth := range ratings {
+		answer, err := ParseRatingValue(rating)
+		if err != nil {
+			t.Errorf("Encountered error: %v", err)
+		}
+		if !cmp.Equal(truth, answer) {
+			t.Errorf("Expected %v, got %v", truth, answer)
+		}
+	}
+
+	if _, err := ParseRatingValue("N/A"); err == nil {
+		t.Errorf("Expected error, got nil.")
+	}
+}
+
+func TestNormalizePersonName(t *testing.T) {
+	names := map[string]string{
+		"Charles Band":              "Charles Band",
+		"Charles Band (uncredited)": "Charles Band",
+		"Stephen King (novel)":      "Stephen King",
+		"David DeCoteau]":           "David DeCoteau",
+		"  Barbara   Crampton ":     "Barbara Crampton",
+	}
+	for name, truth := range names {
+		answer := NormalizePersonName(name)
+		if !cmp.Equal(truth, answer) {
+			t.Errorf("Expected %v, got %v", truth, answer)
+		}
+	}
 }
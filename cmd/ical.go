@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// Lines longer than this many octets get folded, per RFC 5545.
+const ICAL_LINE_LENGTH = 75
+
+// How DTSTAMP and LAST-MODIFIED are written, always in UTC.
+const ICAL_DATETIME_FORMAT = "20060102T150405Z"
+
+type ICalEvent struct {
+	Uid string
+	// When the calendar was exported.
+	Stamp string
+	// When the watch was last changed, so calendars that already have the
+	// event know to take the new one.
+	LastModified string
+	Start        string
+	End          string
+	Summary      string
+	Description  string
+	Url          string
+}
+
+// EscapeICalText escapes a TEXT value.
+func EscapeICalText(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\\\")
+	text = strings.ReplaceAll(text, ";", "\\;")
+	text = strings.ReplaceAll(text, ",", "\\,")
+	text = strings.ReplaceAll(text, "\r\n", "\\n")
+	text = strings.ReplaceAll(text, "\n", "\\n")
+	return text
+}
+
+// FoldICalLine splits a content line into CRLF terminated lines of at most
+// ICAL_LINE_LENGTH octets, continuing each with a space. It never splits a
+// UTF-8 character.
+func FoldICalLine(line string) string {
+	var folded strings.Builder
+	limit := ICAL_LINE_LENGTH
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut])
+		folded.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts against the continuation line.
+		limit = ICAL_LINE_LENGTH - 1
+	}
+	folded.WriteString(line)
+	folded.WriteString("\r\n")
+	return folded.String()
+}
+
+// CreateICalEvent makes an all day event for the watch, stamped with when
+// it was exported. The UID comes from the watch uuid so importing again
+// updates the event rather than adding another one.
+func CreateICalEvent(
+	watch *database.GetWatchesForCalendarRow, exported time.Time,
+) (*ICalEvent, error) {
+	watched, err := ParseWatchedDate(watch.Watched)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error parsing watched date %v for %v: %v",
+			watch.Watched, watch.Title, err,
+		)
+	}
+
+	description := []string{fmt.Sprintf("Service: %v", watch.Service)}
+	if watch.FirstTime != 0 {
+		description = append(description, "First time")
+	} else {
+		description = append(description, "Rewatch")
+	}
+	if watch.RuntimeMinutes.Valid {
+		description = append(
+			description,
+			fmt.Sprintf("Runtime: %v min", watch.RuntimeMinutes.Int64),
+		)
+	}
+	if watch.Notes.Valid && strings.TrimSpace(watch.Notes.String) != "" {
+		description = append(
+			description, "", strings.TrimSpace(watch.Notes.String),
+		)
+	}
+
+	return &ICalEvent{
+		Uid:   fmt.Sprintf("%v@movies-app", watch.Uuid),
+		Stamp: exported.UTC().Format(ICAL_DATETIME_FORMAT),
+		LastModified: time.Unix(watch.UpdatedDatetime, 0).UTC().Format(
+			ICAL_DATETIME_FORMAT,
+		),
+		Start:       watched.Format("20060102"),
+		End:         watched.AddDate(0, 0, 1).Format("20060102"),
+		Summary:     fmt.Sprintf("%v (%v)", watch.Title, watch.Year),
+		Description: strings.Join(description, "\n"),
+		Url:         watch.ImdbLink,
+	}, nil
+}
+
+func WriteICalendar(
+	writer io.Writer, name string, events []*ICalEvent,
+) error {
+	buffered := bufio.NewWriter(writer)
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//movies-app//watch history//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:" + EscapeICalText(name),
+	}
+	for _, event := range events {
+		lines = append(
+			lines,
+			"BEGIN:VEVENT",
+			"UID:"+event.Uid,
+			"DTSTAMP:"+event.Stamp,
+			"LAST-MODIFIED:"+event.LastModified,
+			"DTSTART;VALUE=DATE:"+event.Start,
+			"DTEND;VALUE=DATE:"+event.End,
+			"SUMMARY:"+EscapeICalText(event.Summary),
+			"DESCRIPTION:"+EscapeICalText(event.Description),
+		)
+		if event.Url != "" {
+			lines = append(lines, "URL:"+event.Url)
+		}
+		lines = append(lines, "TRANSP:TRANSPARENT", "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for ii := range lines {
+		if _, err := buffered.WriteString(FoldICalLine(lines[ii])); err != nil {
+			return fmt.Errorf("error writing calendar: %v", err)
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("error writing calendar: %v", err)
+	}
+	return nil
+}
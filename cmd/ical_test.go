@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestEscapeICalText(t *testing.T) {
+	truth := `Tenebrae\, again\; with a back\\slash\nand a new line`
+	answer := EscapeICalText("Tenebrae, again; with a back\\slash\nand a new line")
+	if truth != answer {
+		t.Errorf("Expected %v, got %v", truth, answer)
+	}
+}
+
+func TestFoldICalLine(t *testing.T) {
+	short := "SUMMARY:Tenebrae (1982)"
+	if answer := FoldICalLine(short); answer != short+"\r\n" {
+		t.Errorf("Expected %v unfolded, got %v", short, answer)
+	}
+
+	// Every line fits in 75 octets, multi-byte characters stay whole and
+	// unfolding gives back the original.
+	long := "DESCRIPTION:" + strings.Repeat("Ténèbres ", 20)
+	folded := FoldICalLine(long)
+	lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+	for ii := range lines {
+		if len(lines[ii]) > ICAL_LINE_LENGTH {
+			t.Errorf("Line %v is %v octets", ii, len(lines[ii]))
+		}
+		if !strings.HasPrefix(lines[ii], " ") && ii > 0 {
+			t.Errorf("Expected line %v to start with a space", ii)
+		}
+		if strings.ContainsRune(lines[ii], '�') {
+			t.Errorf("Line %v split a character", ii)
+		}
+	}
+	unfolded := strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", "")
+	if unfolded != long {
+		t.Errorf("Expected %v, got %v", long, unfolded)
+	}
+}
+
+func TestCreateICalEvent(t *testing.T) {
+	watch := database.GetWatchesForCalendarRow{
+		Uuid:            "abc",
+		Watched:         "2022-12-31",
+		Service:         "Shudder",
+		FirstTime:       0,
+		Notes:           sql.NullString{String: "Still great.", Valid: true},
+		CreatedDatetime: 1672531200,
+		UpdatedDatetime: 1672617600,
+		Title:           "Tenebrae",
+		Year:            1982,
+		RuntimeMinutes:  sql.NullInt64{Int64: 101, Valid: true},
+		ImdbLink:        "https://www.imdb.com/title/tt0084777/",
+	}
+	truth := &ICalEvent{
+		Uid:          "abc@movies-app",
+		Stamp:        "20230201T120000Z",
+		LastModified: "20230102T000000Z",
+		Start:        "20221231",
+		End:          "20230101",
+		Summary:      "Tenebrae (1982)",
+		Description:  "Service: Shudder\nRewatch\nRuntime: 101 min\n\nStill great.",
+		Url:          "https://www.imdb.com/title/tt0084777/",
+	}
+	exported := time.Date(2023, 2, 1, 12, 0, 0, 0, time.UTC)
+	answer, err := CreateICalEvent(&watch, exported)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected %v, got %v", truth, answer)
+	}
+
+	var calendar bytes.Buffer
+	if err := WriteICalendar(&calendar, "Movie Diary", []*ICalEvent{answer}); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	for _, line := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:abc@movies-app\r\n",
+		"DTSTAMP:20230201T120000Z\r\n",
+		"LAST-MODIFIED:20230102T000000Z\r\n",
+		"DTSTART;VALUE=DATE:20221231\r\n",
+		"DTEND;VALUE=DATE:20230101\r\n",
+		"DESCRIPTION:Service: Shudder\\nRewatch\\nRuntime: 101 min\\n\\nStill great.\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(calendar.String(), line) {
+			t.Errorf("Expected %q in %v", line, calendar.String())
+		}
+	}
+}
+
+func TestGetWatchesForCalendarUpdated(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	movieUuids, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	params := CreateInsertMovieWatchParams(
+		sampleMovieWatchPage(), movieUuids.Movie,
+	)
+	if err := queries.InsertMovieWatch(ctx, *params); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	// Back when it was created, so a change shows up.
+	if _, err := db.Exec(
+		"UPDATE movie_watch SET created_datetime = 0 WHERE uuid = ?",
+		params.Uuid,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	// Saving it again without changes leaves it alone.
+	if err := queries.InsertMovieWatch(ctx, *params); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	watches, err := queries.GetWatchesForCalendar(ctx)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(watches) != 1 || watches[0].UpdatedDatetime != 0 {
+		t.Errorf("Expected an unchanged watch, got %v", watches)
+	}
+
+	params.Service = "Criterion Channel"
+	if err := queries.InsertMovieWatch(ctx, *params); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	watches, err = queries.GetWatchesForCalendar(ctx)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(watches) != 1 || watches[0].UpdatedDatetime == 0 {
+		t.Errorf("Expected an updated watch, got %v", watches)
+	}
+}
@@ -0,0 +1,724 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+var LIST_TEMPLATE = `
+# {{.Name}}
+
+## Data
+name:: {{.Name}}
+description:: {{.Description}}
+
+## List
+{{range .Items}}{{.Position}}. [[{{.FileTitle}} ({{.ImdbId}})]]{{with .Comment}} - {{.}}{{end}}
+{{end}}
+## Tags
+#list
+`
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Manages ordered lists of movies, like rankings.",
+	Long: `Manages ordered lists of movies, like rankings.
+
+Lists live in the database and in Lists/<name>.md in the vault. With --vault
+the page is rewritten on every change, and list update reads an edited page
+back into the database.`,
+}
+
+var listCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Creates an empty list.",
+	Run:   listCreate,
+	Args:  cobra.ExactArgs(1),
+}
+
+var listAddCmd = &cobra.Command{
+	Use:   "add <name> <title or imdb id>",
+	Short: "Adds a movie to a list, at the end unless there's a position.",
+	Run:   listAdd,
+	Args:  cobra.ExactArgs(2),
+}
+
+var listMoveCmd = &cobra.Command{
+	Use:   "move <name> <title or imdb id> <position>",
+	Short: "Moves a movie to another position in a list.",
+	Run:   listMove,
+	Args:  cobra.ExactArgs(3),
+}
+
+var listRemoveCmd = &cobra.Command{
+	Use:   "remove <name> <title or imdb id>",
+	Short: "Removes a movie from a list.",
+	Run:   listRemove,
+	Args:  cobra.ExactArgs(2),
+}
+
+var listShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Shows a list, or all the lists if there's no name.",
+	Run:   listShow,
+	Args:  cobra.RangeArgs(0, 1),
+}
+
+var listUpdateCmd = &cobra.Command{
+	Use:   "update <list page>",
+	Short: "Updates a list in the database from an Obsidian page.",
+	Run:   listUpdate,
+	Args:  cobra.ExactArgs(1),
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	listCmd.AddCommand(listCreateCmd)
+	listCmd.AddCommand(listAddCmd)
+	listCmd.AddCommand(listMoveCmd)
+	listCmd.AddCommand(listRemoveCmd)
+	listCmd.AddCommand(listShowCmd)
+	listCmd.AddCommand(listUpdateCmd)
+
+	listCmd.PersistentFlags().StringP(
+		"vault", "v", "", "The vault to write the list page into.",
+	)
+	listCreateCmd.Flags().StringP(
+		"description", "d", "", "What the list is about.",
+	)
+	listAddCmd.Flags().IntP(
+		"position", "p", 0, "Where to put it, 1 being the top. 0 is the end.",
+	)
+	listAddCmd.Flags().StringP(
+		"comment", "c", "", "Why it's on the list.",
+	)
+}
+
+type ListPageItem struct {
+	Position  int64
+	Title     string
+	FileTitle string
+	ImdbId    string
+	Comment   string
+}
+
+type ListPage struct {
+	Name        string
+	Description string
+	Items       []ListPageItem
+}
+
+func CreateListPage(
+	list *database.List,
+	items []database.GetListItemsRow,
+	vaultNames VaultNames,
+) *ListPage {
+	page := ListPage{
+		Name:        list.Name,
+		Description: list.Description.String,
+		Items:       make([]ListPageItem, len(items)),
+	}
+	for ii := range items {
+		page.Items[ii] = ListPageItem{
+			// Positions are kept contiguous, but this way the page is right
+			// even if they aren't.
+			Position:  int64(ii + 1),
+			Title:     items[ii].Title,
+			FileTitle: vaultNames.FileTitle(items[ii].ImdbID, items[ii].Title),
+			ImdbId:    items[ii].ImdbID,
+			Comment:   items[ii].Comment.String,
+		}
+	}
+	return &page
+}
+
+func ListPageFileName(name string) string {
+	return fmt.Sprintf("%v.md", cleanTitle(name))
+}
+
+type ListParser struct {
+	DataExtractor  *regexp.Regexp
+	ItemsExtractor *regexp.Regexp
+	ItemExtractor  *regexp.Regexp
+	MovieExtractor *regexp.Regexp
+}
+
+func CreateListParser() (*ListParser, error) {
+	parser := ListParser{}
+
+	dataExtractor, err := regexp.Compile(`## Data\n((?:.|\n)*)\n## List`)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling regex for data: %v", err)
+	}
+	parser.DataExtractor = dataExtractor
+
+	itemsExtractor, err := regexp.Compile(`(?s)## List\n(.*?)(?:\n## Tags|$)`)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling regex for list: %v", err)
+	}
+	parser.ItemsExtractor = itemsExtractor
+
+	itemExtractor, err := regexp.Compile(`^\s*\d+\.\s+(.*)$`)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling regex for list item: %v", err)
+	}
+	parser.ItemExtractor = itemExtractor
+
+	movieExtractor, err := regexp.Compile(MOVIE_LINK_PATTERN)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling regex for movie: %v", err)
+	}
+	parser.MovieExtractor = movieExtractor
+
+	return &parser, nil
+}
+
+// ParsePage reads a list page. The order is the order of the numbered items
+// on the page, not the numbers themselves, since that's how Obsidian shows
+// them after lines get moved around.
+func (p *ListParser) ParsePage(fileName string) (*ListPage, error) {
+	pageText, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %v: %v", fileName, err)
+	}
+
+	page := ListPage{Items: make([]ListPageItem, 0)}
+
+	dataMatch := p.DataExtractor.FindSubmatch(pageText)
+	if len(dataMatch) != 2 {
+		return nil, fmt.Errorf(
+			"expected 2 matches for list data, got %v", len(dataMatch),
+		)
+	}
+	dataLines := strings.Split(string(dataMatch[1]), "\n")
+	for ii := range dataLines {
+		splitLine := strings.Split(dataLines[ii], "::")
+		tag := splitLine[0]
+		var data string
+		if len(splitLine) > 1 {
+			data = strings.Join(splitLine[1:], "::")
+			data = strings.TrimSpace(data)
+		}
+
+		switch tag {
+		case "name":
+			page.Name = data
+		case "description":
+			page.Description = data
+		}
+	}
+	if page.Name == "" {
+		return nil, fmt.Errorf("list page %v has no name", fileName)
+	}
+
+	itemsMatch := p.ItemsExtractor.FindSubmatch(pageText)
+	if len(itemsMatch) != 2 {
+		return nil, fmt.Errorf(
+			"expected 2 matches for list items, got %v", len(itemsMatch),
+		)
+	}
+	itemLines := strings.Split(string(itemsMatch[1]), "\n")
+	for ii := range itemLines {
+		itemMatch := p.ItemExtractor.FindStringSubmatch(itemLines[ii])
+		if itemMatch == nil {
+			continue
+		}
+		movieMatch := p.MovieExtractor.FindStringSubmatchIndex(itemMatch[1])
+		if movieMatch == nil {
+			return nil, fmt.Errorf(
+				"expected a movie link in list item %v", itemLines[ii],
+			)
+		}
+		comment := strings.TrimSpace(itemMatch[1][movieMatch[1]:])
+		comment = strings.TrimLeft(comment, "-:")
+		page.Items = append(page.Items, ListPageItem{
+			Position:  int64(len(page.Items) + 1),
+			Title:     itemMatch[1][movieMatch[2]:movieMatch[3]],
+			FileTitle: itemMatch[1][movieMatch[2]:movieMatch[3]],
+			ImdbId:    itemMatch[1][movieMatch[4]:movieMatch[5]],
+			Comment:   strings.TrimSpace(comment),
+		})
+	}
+	return &page, nil
+}
+
+// FindList gets the list by name, with an error that says so if there
+// isn't one.
+func FindList(
+	ctx context.Context, queries *database.Queries, name string,
+) (*database.List, error) {
+	list, err := queries.FindList(ctx, name)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no list named %v", name)
+	} else if err != nil {
+		return nil, fmt.Errorf("error finding list %v: %v", name, err)
+	}
+	return &list, nil
+}
+
+// FindListMovie finds the movie uuid for an IMDB ID or a title. The movie
+// has to be in the database already, and the title has to be unique.
+func FindListMovie(
+	ctx context.Context, queries *database.Queries, query string,
+) (string, error) {
+	query = strings.TrimSpace(query)
+	if imdbIdRegex.MatchString(query) {
+		movieUuid, err := queries.FindMovie(ctx, query)
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("%v isn't in the database", query)
+		} else if err != nil {
+			return "", fmt.Errorf("error finding movie %v: %v", query, err)
+		}
+		return movieUuid, nil
+	}
+	movies, err := queries.FindMoviesByTitle(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("error finding movie %v: %v", query, err)
+	}
+	if len(movies) == 0 {
+		return "", fmt.Errorf("%v isn't in the database", query)
+	}
+	if len(movies) > 1 {
+		candidates := make([]string, len(movies))
+		for ii := range movies {
+			candidates[ii] = fmt.Sprintf(
+				"%v (%v)", movies[ii].ImdbID, movies[ii].Year,
+			)
+		}
+		return "", fmt.Errorf(
+			"more than one movie titled %v, use the IMDB ID: %v",
+			query, strings.Join(candidates, ", "),
+		)
+	}
+	return movies[0].Uuid, nil
+}
+
+// getListOrder returns the movie uuids on the list, in order.
+func getListOrder(
+	ctx context.Context, queries *database.Queries, listID int64,
+) ([]string, error) {
+	items, err := queries.GetListItems(ctx, listID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting list items: %v", err)
+	}
+	movieUuids := make([]string, len(items))
+	for ii := range items {
+		movieUuids[ii] = items[ii].MovieUuid
+	}
+	return movieUuids, nil
+}
+
+// setListOrder renumbers the list items from 1 in the given order.
+func setListOrder(
+	ctx context.Context,
+	queries *database.Queries,
+	listID int64,
+	movieUuids []string,
+) error {
+	for ii := range movieUuids {
+		if err := queries.UpdateListItemPosition(
+			ctx, database.UpdateListItemPositionParams{
+				Position:  int64(ii + 1),
+				ListID:    listID,
+				MovieUuid: movieUuids[ii],
+			},
+		); err != nil {
+			return fmt.Errorf("error updating list position: %v", err)
+		}
+	}
+	return nil
+}
+
+// insertAt puts the uuid at the 1-based position, or at the end if the
+// position is 0 or past the end.
+func insertAt(movieUuids []string, movieUuid string, position int) []string {
+	if position < 1 || position > len(movieUuids) {
+		return append(movieUuids, movieUuid)
+	}
+	movieUuids = append(movieUuids, "")
+	copy(movieUuids[position:], movieUuids[position-1:])
+	movieUuids[position-1] = movieUuid
+	return movieUuids
+}
+
+func removeFrom(movieUuids []string, movieUuid string) ([]string, bool) {
+	for ii := range movieUuids {
+		if movieUuids[ii] == movieUuid {
+			return append(movieUuids[:ii], movieUuids[ii+1:]...), true
+		}
+	}
+	return movieUuids, false
+}
+
+func AddToList(
+	ctx context.Context,
+	queries *database.Queries,
+	listID int64,
+	movieUuid string,
+	position int,
+	comment string,
+) error {
+	order, err := getListOrder(ctx, queries, listID)
+	if err != nil {
+		return err
+	}
+	if _, found := removeFrom(order, movieUuid); found {
+		return fmt.Errorf("it's already on the list, move it instead")
+	}
+	order = insertAt(order, movieUuid, position)
+	if err := queries.InsertListItem(ctx, database.InsertListItemParams{
+		ListID:    listID,
+		MovieUuid: movieUuid,
+		Position:  int64(len(order)),
+		Comment:   textToNullString(comment),
+	}); err != nil {
+		return fmt.Errorf("error inserting list item: %v", err)
+	}
+	return setListOrder(ctx, queries, listID, order)
+}
+
+func MoveInList(
+	ctx context.Context,
+	queries *database.Queries,
+	listID int64,
+	movieUuid string,
+	position int,
+) error {
+	order, err := getListOrder(ctx, queries, listID)
+	if err != nil {
+		return err
+	}
+	order, found := removeFrom(order, movieUuid)
+	if !found {
+		return fmt.Errorf("it isn't on the list")
+	}
+	return setListOrder(
+		ctx, queries, listID, insertAt(order, movieUuid, position),
+	)
+}
+
+func RemoveFromList(
+	ctx context.Context,
+	queries *database.Queries,
+	listID int64,
+	movieUuid string,
+) error {
+	order, err := getListOrder(ctx, queries, listID)
+	if err != nil {
+		return err
+	}
+	order, found := removeFrom(order, movieUuid)
+	if !found {
+		return fmt.Errorf("it isn't on the list")
+	}
+	if err := queries.DeleteListItem(ctx, database.DeleteListItemParams{
+		ListID: listID, MovieUuid: movieUuid,
+	}); err != nil {
+		return fmt.Errorf("error deleting list item: %v", err)
+	}
+	return setListOrder(ctx, queries, listID, order)
+}
+
+// UpdateListFromPage replaces the list with what's on the page, creating it
+// if it's new.
+func UpdateListFromPage(
+	ctx context.Context, queries *database.Queries, page *ListPage,
+) error {
+	list, err := queries.FindList(ctx, page.Name)
+	if err == sql.ErrNoRows {
+		list.ID, err = queries.InsertList(ctx, database.InsertListParams{
+			Name: page.Name, Description: textToNullString(page.Description),
+		})
+		if err != nil {
+			return fmt.Errorf("error creating list %v: %v", page.Name, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("error finding list %v: %v", page.Name, err)
+	} else if err := queries.UpdateListDescription(
+		ctx, database.UpdateListDescriptionParams{
+			Description: textToNullString(page.Description), ID: list.ID,
+		},
+	); err != nil {
+		return fmt.Errorf("error updating list %v: %v", page.Name, err)
+	}
+
+	if err := queries.DeleteListItems(ctx, list.ID); err != nil {
+		return fmt.Errorf("error clearing list %v: %v", page.Name, err)
+	}
+	seen := make(map[string]bool)
+	for ii := range page.Items {
+		item := &page.Items[ii]
+		if seen[item.ImdbId] {
+			return fmt.Errorf("%v is on the list twice", item.Title)
+		}
+		seen[item.ImdbId] = true
+		movieUuid, err := FindListMovie(ctx, queries, item.ImdbId)
+		if err != nil {
+			return err
+		}
+		if err := queries.InsertListItem(ctx, database.InsertListItemParams{
+			ListID:    list.ID,
+			MovieUuid: movieUuid,
+			Position:  item.Position,
+			Comment:   textToNullString(item.Comment),
+		}); err != nil {
+			return fmt.Errorf("error inserting %v: %v", item.Title, err)
+		}
+	}
+	return nil
+}
+
+// WriteListPage rewrites Lists/<name>.md in the vault.
+func WriteListPage(
+	ctx context.Context,
+	queries *database.Queries,
+	vaultNames VaultNames,
+	listTemplate *template.Template,
+	listsDir string,
+	list *database.List,
+) error {
+	items, err := queries.GetListItems(ctx, list.ID)
+	if err != nil {
+		return fmt.Errorf("error getting items for %v: %v", list.Name, err)
+	}
+	filePath := path.Join(listsDir, ListPageFileName(list.Name))
+	return WritePage(
+		listTemplate, filePath, CreateListPage(list, items, vaultNames),
+	)
+}
+
+// WriteListPages writes the pages for all the lists into the vault.
+func WriteListPages(
+	ctx context.Context, queries *database.Queries, vaultDir string,
+) error {
+	listTemplate, err := template.New("list").Parse(LIST_TEMPLATE)
+	if err != nil {
+		return fmt.Errorf("unable to parse list template: %v", err)
+	}
+	listsDir := path.Join(vaultDir, "Lists")
+	if err := os.Mkdir(listsDir, 0755); err != nil &&
+		!errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("error creating %v: %v", listsDir, err)
+	}
+	lists, err := queries.GetLists(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting lists: %v", err)
+	}
+	vaultNames, err := LoadVaultNames(ctx, queries)
+	if err != nil {
+		return err
+	}
+	for ii := range lists {
+		if err := WriteListPage(
+			ctx, queries, vaultNames, listTemplate, listsDir, &lists[ii],
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openList opens the database and gets the vault flag for the list
+// commands.
+func openList(cmd *cobra.Command) (*sql.DB, *database.Queries, string) {
+	vaultDir, err := cmd.Flags().GetString("vault")
+	if err != nil {
+		log.Panicf("Error obtaining vault: %v", err)
+	}
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	return db, database.New(db), vaultDir
+}
+
+// changeList runs the change in a transaction and rewrites the list pages
+// if there's a vault.
+func changeList(
+	cmd *cobra.Command,
+	name string,
+	change func(context.Context, *database.Queries, *database.List) error,
+) {
+	ctx := context.Background()
+	db, queries, vaultDir := openList(cmd)
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Panicf("Error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	qtx := queries.WithTx(tx)
+	list, err := FindList(ctx, qtx, name)
+	if err != nil {
+		log.Panicf("Error getting list: %v", err)
+	}
+	if err := change(ctx, qtx, list); err != nil {
+		log.Panicf("Error changing %v: %v", name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Panicf("Error committing transaction: %v", err)
+	}
+
+	if vaultDir != "" {
+		if err := WriteListPages(ctx, queries, vaultDir); err != nil {
+			log.Panicf("Error writing list pages: %v", err)
+		}
+	}
+}
+
+func listCreate(cmd *cobra.Command, args []string) {
+	name := strings.TrimSpace(args[0])
+	description, err := cmd.Flags().GetString("description")
+	if err != nil {
+		log.Panicf("Error obtaining description: %v", err)
+	}
+
+	ctx := context.Background()
+	db, queries, vaultDir := openList(cmd)
+	defer db.Close()
+
+	if _, err := queries.InsertList(ctx, database.InsertListParams{
+		Name: name, Description: textToNullString(description),
+	}); err != nil {
+		log.Panicf("Error creating list %v: %v", name, err)
+	}
+	log.Printf("Created list %v.", name)
+	if vaultDir != "" {
+		if err := WriteListPages(ctx, queries, vaultDir); err != nil {
+			log.Panicf("Error writing list pages: %v", err)
+		}
+	}
+}
+
+func listAdd(cmd *cobra.Command, args []string) {
+	position, err := cmd.Flags().GetInt("position")
+	if err != nil {
+		log.Panicf("Error obtaining position: %v", err)
+	}
+	if position < 0 {
+		log.Panicf("position must be >= 0, got %v", position)
+	}
+	comment, err := cmd.Flags().GetString("comment")
+	if err != nil {
+		log.Panicf("Error obtaining comment: %v", err)
+	}
+	changeList(cmd, args[0], func(
+		ctx context.Context, queries *database.Queries, list *database.List,
+	) error {
+		movieUuid, err := FindListMovie(ctx, queries, args[1])
+		if err != nil {
+			return err
+		}
+		return AddToList(ctx, queries, list.ID, movieUuid, position, comment)
+	})
+}
+
+func listMove(cmd *cobra.Command, args []string) {
+	var position int
+	if _, err := fmt.Sscan(args[2], &position); err != nil || position < 1 {
+		log.Panicf("position must be a number >= 1, got %v", args[2])
+	}
+	changeList(cmd, args[0], func(
+		ctx context.Context, queries *database.Queries, list *database.List,
+	) error {
+		movieUuid, err := FindListMovie(ctx, queries, args[1])
+		if err != nil {
+			return err
+		}
+		return MoveInList(ctx, queries, list.ID, movieUuid, position)
+	})
+}
+
+func listRemove(cmd *cobra.Command, args []string) {
+	changeList(cmd, args[0], func(
+		ctx context.Context, queries *database.Queries, list *database.List,
+	) error {
+		movieUuid, err := FindListMovie(ctx, queries, args[1])
+		if err != nil {
+			return err
+		}
+		return RemoveFromList(ctx, queries, list.ID, movieUuid)
+	})
+}
+
+func listShow(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	db, queries, _ := openList(cmd)
+	defer db.Close()
+
+	if len(args) == 0 {
+		lists, err := queries.GetLists(ctx)
+		if err != nil {
+			log.Panicf("Error getting lists: %v", err)
+		}
+		for ii := range lists {
+			fmt.Printf("%v\t%v\n", lists[ii].Name, lists[ii].Description.String)
+		}
+		return
+	}
+
+	list, err := FindList(ctx, queries, args[0])
+	if err != nil {
+		log.Panicf("Error getting list: %v", err)
+	}
+	items, err := queries.GetListItems(ctx, list.ID)
+	if err != nil {
+		log.Panicf("Error getting items for %v: %v", list.Name, err)
+	}
+	fmt.Println(list.Name)
+	if list.Description.Valid {
+		fmt.Println(list.Description.String)
+	}
+	for ii := range items {
+		line := fmt.Sprintf(
+			"%v. %v (%v) %v", ii+1, items[ii].Title, items[ii].Year,
+			items[ii].ImdbID,
+		)
+		if items[ii].Comment.Valid {
+			line = fmt.Sprintf("%v - %v", line, items[ii].Comment.String)
+		}
+		fmt.Println(line)
+	}
+}
+
+func listUpdate(cmd *cobra.Command, args []string) {
+	listFile := args[0]
+
+	parser, err := CreateListParser()
+	if err != nil {
+		log.Panicf("Error creating list parser: %v", err)
+	}
+	page, err := parser.ParsePage(listFile)
+	if err != nil {
+		log.Panicf("Error parsing list page: %v", err)
+	}
+
+	ctx := context.Background()
+	db, queries, _ := openList(cmd)
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Panicf("Error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	if err := UpdateListFromPage(ctx, queries.WithTx(tx), page); err != nil {
+		log.Panicf("Error updating list %v: %v", page.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Panicf("Error committing transaction: %v", err)
+	}
+	log.Printf("List %v updated with %v movies.", page.Name, len(page.Items))
+}
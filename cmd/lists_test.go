@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestInsertAt(t *testing.T) {
+	tests := []struct {
+		position int
+		truth    []string
+	}{
+		{0, []string{"a", "b", "c", "x"}},
+		{1, []string{"x", "a", "b", "c"}},
+		{2, []string{"a", "x", "b", "c"}},
+		{3, []string{"a", "b", "x", "c"}},
+		{4, []string{"a", "b", "c", "x"}},
+		{10, []string{"a", "b", "c", "x"}},
+	}
+	for _, test := range tests {
+		answer := insertAt([]string{"a", "b", "c"}, "x", test.position)
+		if !cmp.Equal(test.truth, answer) {
+			t.Errorf(
+				"Expected %v at %v, got %v", test.truth, test.position, answer,
+			)
+		}
+	}
+}
+
+func TestParseListPage(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "Best Godzilla Films.md")
+	if err := os.WriteFile(fileName, []byte(`
+# Best Godzilla Films
+
+## Data
+name:: Best Godzilla Films
+description:: Kings of the monsters.
+
+## List
+2. [[Shin Godzilla (tt4262980)]] - Bureaucracy is the real monster
+1. [[Godzilla (tt0047034)]]
+3. [[Godzilla vs Hedorah (tt0067148)]]: smog monster
+
+## Tags
+#list
+`), 0644); err != nil {
+		t.Fatalf("Error writing page: %v", err)
+	}
+
+	parser, err := CreateListParser()
+	if err != nil {
+		t.Fatalf("Error creating parser: %v", err)
+	}
+	answer, err := parser.ParsePage(fileName)
+	if err != nil {
+		t.Fatalf("Error parsing page: %v", err)
+	}
+	// The order on the page wins over the numbers.
+	truth := ListPage{
+		Name:        "Best Godzilla Films",
+		Description: "Kings of the monsters.",
+		Items: []ListPageItem{
+			{
+				Position:  1,
+				Title:     "Shin Godzilla",
+				FileTitle: "Shin Godzilla",
+				ImdbId:    "tt4262980",
+				Comment:   "Bureaucracy is the real monster",
+			},
+			{
+				Position:  2,
+				Title:     "Godzilla",
+				FileTitle: "Godzilla",
+				ImdbId:    "tt0047034",
+			},
+			{
+				Position:  3,
+				Title:     "Godzilla vs Hedorah",
+				FileTitle: "Godzilla vs Hedorah",
+				ImdbId:    "tt0067148",
+				Comment:   "smog monster",
+			},
+		},
+	}
+	if !cmp.Equal(truth, *answer) {
+		t.Errorf("Expected %v, got %v", truth, *answer)
+	}
+}
+
+func TestLists(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	vaultDir := t.TempDir()
+
+	movieUuids := make(map[string]string)
+	for _, movie := range []struct {
+		title  string
+		imdbId string
+	}{
+		{"Tenebrae", "tt0084777"},
+		{"Suspiria", "tt0076786"},
+		{"Deep Red", "tt0073582"},
+	} {
+		moviePage := sampleMoviePage()
+		moviePage.Title = movie.title
+		moviePage.ImdbLink = "https://www.imdb.com/title/" + movie.imdbId + "/"
+		movieDetails, err := InsertMovieDetails(db, ctx, queries, moviePage, nil)
+		if err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		movieUuids[movie.imdbId] = movieDetails.Movie
+	}
+
+	listID, err := queries.InsertList(ctx, database.InsertListParams{
+		Name: "Argento Ranked",
+	})
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	for _, query := range []string{"tenebrae", "tt0076786"} {
+		movieUuid, err := FindListMovie(ctx, queries, query)
+		if err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		if err := AddToList(ctx, queries, listID, movieUuid, 0, ""); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+	if err := AddToList(
+		ctx, queries, listID, movieUuids["tt0073582"], 1, "The best one.",
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := AddToList(
+		ctx, queries, listID, movieUuids["tt0073582"], 0, "",
+	); err == nil {
+		t.Errorf("Expected an error adding a movie twice")
+	}
+	if _, err := FindListMovie(ctx, queries, "Inferno"); err == nil {
+		t.Errorf("Expected an error for a movie that isn't in the database")
+	}
+
+	getOrder := func() []string {
+		items, err := queries.GetListItems(ctx, listID)
+		if err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		order := make([]string, len(items))
+		for ii := range items {
+			if items[ii].Position != int64(ii+1) {
+				t.Errorf("Expected position %v, got %v", ii+1, items[ii].Position)
+			}
+			order[ii] = items[ii].ImdbID
+		}
+		return order
+	}
+	orderTruth := []string{"tt0073582", "tt0084777", "tt0076786"}
+	if order := getOrder(); !cmp.Equal(orderTruth, order) {
+		t.Errorf("Expected %v, got %v", orderTruth, order)
+	}
+
+	if err := MoveInList(
+		ctx, queries, listID, movieUuids["tt0076786"], 2,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	orderTruth = []string{"tt0073582", "tt0076786", "tt0084777"}
+	if order := getOrder(); !cmp.Equal(orderTruth, order) {
+		t.Errorf("Expected %v, got %v", orderTruth, order)
+	}
+
+	if err := RemoveFromList(
+		ctx, queries, listID, movieUuids["tt0076786"],
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	orderTruth = []string{"tt0073582", "tt0084777"}
+	if order := getOrder(); !cmp.Equal(orderTruth, order) {
+		t.Errorf("Expected %v, got %v", orderTruth, order)
+	}
+
+	// Round trip through the vault, reordering on the page.
+	if err := WriteListPages(ctx, queries, vaultDir); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	pagePath := path.Join(vaultDir, "Lists", "Argento Ranked.md")
+	pageBytes, err := os.ReadFile(pagePath)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	pageText := string(pageBytes)
+	deepRed := "1. [[Deep Red (tt0073582)]] - The best one.\n"
+	tenebrae := "2. [[Tenebrae (tt0084777)]]\n"
+	if !strings.Contains(pageText, deepRed+tenebrae) {
+		t.Fatalf("Unexpected list page %v", pageText)
+	}
+	pageText = strings.Replace(pageText, deepRed+tenebrae, tenebrae+deepRed, 1)
+	if err := os.WriteFile(pagePath, []byte(pageText), 0644); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	parser, err := CreateListParser()
+	if err != nil {
+		t.Fatalf("Error creating parser: %v", err)
+	}
+	page, err := parser.ParsePage(pagePath)
+	if err != nil {
+		t.Fatalf("Error parsing page: %v", err)
+	}
+	if err := UpdateListFromPage(ctx, queries, page); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	orderTruth = []string{"tt0084777", "tt0073582"}
+	if order := getOrder(); !cmp.Equal(orderTruth, order) {
+		t.Errorf("Expected %v, got %v", orderTruth, order)
+	}
+	items, err := queries.GetListItems(ctx, listID)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if items[1].Comment.String != "The best one." {
+		t.Errorf("Expected the comment to survive, got %v", items[1].Comment)
+	}
+}
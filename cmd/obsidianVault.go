@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -11,29 +12,47 @@ import (
 	"github.com/timothyrenner/movies-app/database"
 )
 
+// A wiki-link to a movie page, like [[Tenebrae (tt0084777)]], capturing the
+// title and the IMDB ID. Anything that reads movie links out of the vault
+// should use this so they all agree on what a title can contain, which is
+// anything cleanTitle leaves in.
+const MOVIE_LINK_PATTERN = `\[\[([^\[\]|#^\n]+) \((tt\d{7,8})\)\]\]`
+
 type MovieWatchParser struct {
-	DataExtractor  *regexp.Regexp
-	NotesExtractor *regexp.Regexp
-	TitleExtractor *regexp.Regexp
+	HeaderExtractor *regexp.Regexp
+	DataExtractor   *regexp.Regexp
+	NotesExtractor  *regexp.Regexp
+	TitleExtractor  *regexp.Regexp
 }
 
 func CreateMovieWatchParser() (*MovieWatchParser, error) {
 	parser := MovieWatchParser{}
 	// Time for some regex fu.
 	// But not too much.
-	dataExtractor, err := regexp.Compile(`## Data\n((?:.|\n)*)\n## Tags`)
+	// The header has the full title, the link only has the file title.
+	headerExtractor, err := regexp.Compile(
+		`(?m)^# (.+): \d{4}-\d{2}-\d{2}\r?$`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling regex for header: %v", err)
+	}
+	parser.HeaderExtractor = headerExtractor
+
+	// Headings only count at the start of a line, so the notes can say
+	// anything.
+	dataExtractor, err := regexp.Compile(`(?s)\n## Data\n(.*?)\n## Tags`)
 	if err != nil {
 		return nil, fmt.Errorf("error compiling regex for data: %v", err)
 	}
 	parser.DataExtractor = dataExtractor
 
-	titleExtractor, err := regexp.Compile(`\[\[([a-zA-Z0-9:\-/()', ]+) \(tt\d{7,8}\)\]\]`)
+	titleExtractor, err := regexp.Compile(MOVIE_LINK_PATTERN)
 	if err != nil {
 		return nil, fmt.Errorf("error compiling regex for title: %v", err)
 	}
 	parser.TitleExtractor = titleExtractor
 
-	notesExtractor, err := regexp.Compile(`(?s)## Notes(.*)$`)
+	notesExtractor, err := regexp.Compile(`(?s)\n## Notes(.*)$`)
 	if err != nil {
 		return nil, fmt.Errorf("error compiling regex for notes: %v", err)
 	}
@@ -75,17 +94,34 @@ func (p *MovieWatchParser) ParsePage(fileName string) (*MovieWatchPage, error) {
 		switch tag {
 		case "name":
 			titleMatch := p.TitleExtractor.FindSubmatch([]byte(data))
-			if len(titleMatch) != 2 {
+			if len(titleMatch) != 3 {
 				return nil, fmt.Errorf(
-					"should be one title submatch for %v, got %v",
+					"should be title and IMDB ID submatches for %v, got %v",
 					data, len(titleMatch),
 				)
 			}
-			page.Title = string(titleMatch[1])
+			page.FileTitle = cleanTitle(string(titleMatch[1]))
 		case "watched":
 			watched := strings.Trim(data, "]")
 			watched = strings.Trim(watched, "[")
 			page.Watched = watched
+		case "watched_at":
+			if data == "" {
+				continue
+			}
+			watchedAt, err := ParseWatchedAt(data)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"error parsing watched at %v: %v", data, err,
+				)
+			}
+			page.WatchedAt = watchedAt
+		case "rating":
+			rating, err := ParseStarRating(data)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing rating: %v", err)
+			}
+			page.Rating = rating
 		case "imdb_link":
 			page.ImdbLink = data
 		case "imdb_id":
@@ -159,7 +195,23 @@ func (p *MovieWatchParser) ParsePage(fileName string) (*MovieWatchPage, error) {
 		}
 	}
 
-	page.FileTitle = cleanTitle(page.Title)
+	// Pages without the usual header fall back on the file title.
+	page.Title = page.FileTitle
+	if headerMatch := p.HeaderExtractor.FindSubmatch(pageText); headerMatch != nil {
+		page.Title = strings.TrimSpace(string(headerMatch[1]))
+	}
+
+	// watched is derived from watched_at when there is one.
+	if page.WatchedAt != nil {
+		if page.Watched == "" {
+			page.Watched = page.WatchedAt.Date()
+		} else if page.Watched != page.WatchedAt.Date() {
+			return nil, fmt.Errorf(
+				"watched %v doesn't match watched_at %v",
+				page.Watched, page.WatchedAt,
+			)
+		}
+	}
 
 	notesMatch := p.NotesExtractor.FindSubmatch(pageText)
 	if len(notesMatch) != 2 {
@@ -167,7 +219,7 @@ func (p *MovieWatchParser) ParsePage(fileName string) (*MovieWatchPage, error) {
 			"expected 2 matches for notes, got %v", len(notesMatch),
 		)
 	}
-	page.Notes = string(notesMatch[1])
+	page.Notes = strings.TrimSpace(string(notesMatch[1]))
 
 	return &page, nil
 }
@@ -178,9 +230,11 @@ var MOVIE_WATCH_TEMPLATE = `
 ## Data
 name:: [[{{.FileTitle}} ({{.ImdbId}})]]
 watched:: [[{{.Watched}}]]
-imdb_link:: {{.ImdbLink}}
+{{with .WatchedAt}}watched_at:: {{.}}
+{{end}}imdb_link:: {{.ImdbLink}}
 imdb_id:: {{.ImdbId}}
 service:: {{.Service}}
+rating:: {{if .Rating}}{{.Rating}}{{end}}
 first_time:: {{.FirstTime}}
 joe_bob:: {{.JoeBob}}
 slasher:: {{.Slasher}}
@@ -213,12 +267,33 @@ type MovieWatchPage struct {
 	WallpaperFu bool
 	Service     string
 	Notes       string
+	// Optional, when the time of the watch is known.
+	WatchedAt *WatchedAt
+	// Stars out of five, 0 if unrated.
+	Rating float64
 }
 
-func CreateMovieWatchPage(row *database.GetAllMovieWatchesRow) *MovieWatchPage {
+// CreateMovieWatchPage is the page for a watch in the database, named with
+// the movie's file title from vaultNames.
+func CreateMovieWatchPage(
+	row *database.GetAllMovieWatchesRow, vaultNames VaultNames,
+) *MovieWatchPage {
+	var watchedAt *WatchedAt
+	if row.WatchedAt.Valid {
+		var err error
+		watchedAt, err = NewWatchedAt(row.WatchedAt.String, row.Timezone.String)
+		if err != nil {
+			// It was checked on the way in, so this is someone editing the
+			// database by hand.
+			log.Printf(
+				"Unable to parse watched_at for %v on %v: %v",
+				row.MovieTitle, row.Watched, err,
+			)
+		}
+	}
 	return &MovieWatchPage{
 		Title:       row.MovieTitle,
-		FileTitle:   cleanTitle(row.MovieTitle),
+		FileTitle:   vaultNames.FileTitle(row.ImdbID, row.MovieTitle),
 		Watched:     row.Watched,
 		ImdbLink:    row.ImdbLink,
 		ImdbId:      row.ImdbID,
@@ -231,24 +306,33 @@ func CreateMovieWatchPage(row *database.GetAllMovieWatchesRow) *MovieWatchPage {
 		Slasher:     row.Slasher != 0,
 		WallpaperFu: row.WallpaperFu != 0,
 		Service:     row.Service,
-		Notes:       row.Notes.String,
+		Notes:       strings.TrimSpace(row.Notes.String),
+		WatchedAt:   watchedAt,
+		Rating:      row.Rating.Float64,
 	}
 }
 
 type MovieParser struct {
 	DataExtractor   *regexp.Regexp
+	TagsExtractor   *regexp.Regexp
 	ImdbIDExtractor *regexp.Regexp
 }
 
 func CreateMovieParser() (*MovieParser, error) {
 	parser := MovieParser{}
 
-	dataExtractor, err := regexp.Compile(`## Data\n((?:.|\n)*)\n## Tags`)
+	dataExtractor, err := regexp.Compile(`(?s)\n## Data\n(.*?)\n## Tags`)
 	if err != nil {
 		return nil, fmt.Errorf("error compiling regex for data: %v", err)
 	}
 	parser.DataExtractor = dataExtractor
 
+	tagsExtractor, err := regexp.Compile(`(?s)\n## Tags\n(.*?)(?:\n## |$)`)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling regex for tags: %v", err)
+	}
+	parser.TagsExtractor = tagsExtractor
+
 	return &parser, nil
 }
 
@@ -287,13 +371,17 @@ func (p *MovieParser) ParsePage(fileName string) (*MoviePage, error) {
 		case "":
 			// Do nothing, this is a blank line.
 		case "genre":
-			page.Genres = SplitOnCommaAndTrim(data)
+			page.Genres = splitLinkNames(data)
 		case "director":
-			page.Directors = SplitOnCommaAndTrim(data)
+			page.Directors = splitLinkNames(data)
 		case "actor":
-			page.Actors = SplitOnCommaAndTrim(data)
+			page.Actors = splitLinkNames(data)
 		case "writer":
-			page.Writers = SplitOnCommaAndTrim(data)
+			page.Writers = splitLinkNames(data)
+		case "review":
+			page.Reviews = append(
+				page.Reviews, strings.TrimSuffix(strings.TrimPrefix(data, "[["), "]]"),
+			)
 		case "year":
 			year, err := strconv.Atoi(data)
 			if err != nil {
@@ -307,6 +395,9 @@ func (p *MovieParser) ParsePage(fileName string) (*MoviePage, error) {
 		case "released":
 			page.Released = data
 		case "runtime_minutes":
+			if data == "" {
+				continue
+			}
 			runtimeMinutes, err := strconv.Atoi(data)
 			if err != nil {
 				return nil, fmt.Errorf(
@@ -375,6 +466,15 @@ func (p *MovieParser) ParsePage(fileName string) (*MoviePage, error) {
 		}
 	}
 
+	// Everything but #movie is a genre.
+	if tagsMatch := p.TagsExtractor.FindSubmatch(pageText); len(tagsMatch) == 2 {
+		for _, tag := range strings.Fields(string(tagsMatch[1])) {
+			if strings.HasPrefix(tag, "#") && tag != "#movie" {
+				page.GenreTags = append(page.GenreTags, tag[1:])
+			}
+		}
+	}
+
 	return &page, nil
 }
 
@@ -384,14 +484,15 @@ var MOVIE_TEMPLATE = `
 title:: {{.Title}}
 imdb_link:: {{.ImdbLink}}
 {{$sep := ""}}
-genre:: {{range $elem := .Genres}}{{$sep}}[[{{$elem}}]]{{$sep = ", "}}{{end}}
-director:: {{$sep = ""}}{{range $elem := .Directors}}{{$sep}}[[{{$elem}}]]{{$sep = ", "}}{{end}}
-actor:: {{$sep = ""}}{{range $elem := .Actors}}{{$sep}}[[{{$elem}}]]{{$sep = ", "}}{{end}}
-writer:: {{$sep = ""}}{{range $elem := .Writers}}{{$sep}}[[{{$elem}}]]{{$sep = ", "}}{{end}}
-year:: {{.Year}}
+genre:: {{range $elem := .GenreLinks}}{{$sep}}[[{{$elem}}]]{{$sep = ", "}}{{end}}
+director:: {{$sep = ""}}{{range $elem := .DirectorLinks}}{{$sep}}[[{{$elem}}]]{{$sep = ", "}}{{end}}
+actor:: {{$sep = ""}}{{range $elem := .ActorLinks}}{{$sep}}[[{{$elem}}]]{{$sep = ", "}}{{end}}
+writer:: {{$sep = ""}}{{range $elem := .WriterLinks}}{{$sep}}[[{{$elem}}]]{{$sep = ", "}}{{end}}
+{{range .Reviews}}review:: [[{{.}}]]
+{{end}}year:: {{.Year}}
 rated:: {{.Rating}}
 released:: {{.Released}}
-runtime_minutes:: {{if .RuntimeMinutes}} {{.RuntimeMinutes}} {{end}}
+runtime_minutes:: {{if .RuntimeMinutes}}{{.RuntimeMinutes}}{{end}}
 plot:: {{.Plot}}
 country:: {{.Country}}
 language:: {{.Language}}
@@ -406,7 +507,7 @@ wallpaper_fu:: {{.WallpaperFu}}
 
 ## Tags
 #movie
-{{$sep = ""}}{{range $elem := .Genres}}{{$sep}}#{{$elem}}{{$sep = "\n"}}{{end}}
+{{$sep = ""}}{{range $elem := .GenreTags}}{{$sep}}#{{$elem}}{{$sep = "\n"}}{{end}}
 `
 
 type MoviePage struct {
@@ -431,19 +532,34 @@ type MoviePage struct {
 	Beast          bool
 	Godzilla       bool
 	WallpaperFu    bool
+	// The names of the review pages, one review:: line each.
+	Reviews []string
+	// Not on the page itself, it's only kept in the database.
+	Poster string
+	// The tag-safe slugs for the genres, from the GenreTaxonomy.
+	GenreTags []string
 }
 
+// The genres and people link to pages named like their files, see pageLink.
+func (p *MoviePage) GenreLinks() []string    { return pageLinks(p.Genres) }
+func (p *MoviePage) DirectorLinks() []string { return pageLinks(p.Directors) }
+func (p *MoviePage) ActorLinks() []string    { return pageLinks(p.Actors) }
+func (p *MoviePage) WriterLinks() []string   { return pageLinks(p.Writers) }
+
 func CreateMoviePageFromRow(
 	row *database.Movie,
 	genres []string,
 	directors []string,
 	writers []string,
 	actors []string,
+	reviews []string,
+	taxonomy *GenreTaxonomy,
 ) *MoviePage {
 	return &MoviePage{
 		Title:          row.Title,
 		ImdbLink:       row.ImdbLink,
 		Genres:         genres,
+		GenreTags:      taxonomy.Tags(genres),
 		Directors:      directors,
 		Actors:         actors,
 		Writers:        writers,
@@ -462,11 +578,147 @@ func CreateMoviePageFromRow(
 		Beast:          row.Beast != 0,
 		Godzilla:       row.Godzilla != 0,
 		WallpaperFu:    row.WallpaperFu != 0,
+		Reviews:        reviews,
+		Poster:         row.Poster.String,
 	}
 }
 
+// GetMoviePage builds the movie's page from the database.
+func GetMoviePage(
+	ctx context.Context,
+	queries *database.Queries,
+	taxonomy *GenreTaxonomy,
+	movieUuid string,
+) (*MoviePage, error) {
+	movieRow, err := queries.GetMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting movie %v: %v", movieUuid, err)
+	}
+	// People go by their names in the people table, so the links reach
+	// their pages after they're merged or renamed.
+	credits, err := queries.GetCreditsForMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error getting credits for %v: %v", movieRow.Title, err,
+		)
+	}
+	creditNames := make(map[string][]string)
+	for ii := range credits {
+		creditNames[credits[ii].Role] = append(
+			creditNames[credits[ii].Role], credits[ii].Name,
+		)
+	}
+	directors := creditNames[DIRECTOR_CREDIT_ROLE]
+	writers := creditNames[WRITER_CREDIT_ROLE]
+	actors := creditNames[ACTOR_CREDIT_ROLE]
+	genres, err := queries.GetGenreNamesForMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error getting genres for %v: %v", movieRow.Title, err,
+		)
+	}
+	reviewRows, err := queries.GetReviewsForMovie(ctx, movieRow.ImdbID)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error getting reviews for %v: %v", movieRow.Title, err,
+		)
+	}
+	fileTitle, err := GetMovieFileTitle(ctx, queries, movieUuid)
+	if err != nil {
+		return nil, err
+	}
+	vaultNames := VaultNames{movieRow.ImdbID: fileTitle}
+	var reviews []string
+	for ii := range reviewRows {
+		reviewRow := database.GetReviewRow(reviewRows[ii])
+		reviews = append(
+			reviews,
+			ReviewPageName(CreateMovieReviewPage(&reviewRow, vaultNames)),
+		)
+	}
+	return CreateMoviePageFromRow(
+		&movieRow, genres, directors, writers, actors, reviews, taxonomy,
+	), nil
+}
+
+// LoadMoviePages builds the page for every movie in the database, keyed by
+// the movie uuid. It's GetMoviePage for all of them at once, in a handful of
+// queries instead of six per movie.
+func LoadMoviePages(
+	ctx context.Context,
+	queries *database.Queries,
+	taxonomy *GenreTaxonomy,
+	vaultNames VaultNames,
+) (map[string]*MoviePage, error) {
+	movies, err := queries.GetAllMovies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting movies: %v", err)
+	}
+	genres, err := queries.GetAllMovieGenreNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting genres: %v", err)
+	}
+	genreNames := make(map[string][]string)
+	for ii := range genres {
+		genreNames[genres[ii].MovieUuid] = append(
+			genreNames[genres[ii].MovieUuid], genres[ii].Name,
+		)
+	}
+	credits, err := queries.GetAllMovieCredits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting credits: %v", err)
+	}
+	// By role, then movie uuid.
+	creditNames := map[string]map[string][]string{
+		DIRECTOR_CREDIT_ROLE: make(map[string][]string),
+		WRITER_CREDIT_ROLE:   make(map[string][]string),
+		ACTOR_CREDIT_ROLE:    make(map[string][]string),
+	}
+	for ii := range credits {
+		names := creditNames[credits[ii].Role]
+		if names == nil {
+			continue
+		}
+		names[credits[ii].MovieUuid] = append(
+			names[credits[ii].MovieUuid], credits[ii].Name,
+		)
+	}
+	directorNames := creditNames[DIRECTOR_CREDIT_ROLE]
+	writerNames := creditNames[WRITER_CREDIT_ROLE]
+	actorNames := creditNames[ACTOR_CREDIT_ROLE]
+	reviews, err := queries.GetAllReviews(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting reviews: %v", err)
+	}
+	reviewNames := make(map[string][]string)
+	for ii := range reviews {
+		reviewRow := database.GetReviewRow(reviews[ii])
+		reviewNames[reviews[ii].MovieUuid] = append(
+			reviewNames[reviews[ii].MovieUuid],
+			ReviewPageName(CreateMovieReviewPage(&reviewRow, vaultNames)),
+		)
+	}
+
+	pages := make(map[string]*MoviePage, len(movies))
+	for ii := range movies {
+		movieUuid := movies[ii].Uuid
+		pages[movieUuid] = CreateMoviePageFromRow(
+			&movies[ii],
+			genreNames[movieUuid],
+			directorNames[movieUuid],
+			writerNames[movieUuid],
+			actorNames[movieUuid],
+			reviewNames[movieUuid],
+			taxonomy,
+		)
+	}
+	return pages, nil
+}
+
 func CreateMoviePage(
-	omdbResponse *OmdbMovieResponse, movieWatch *MovieWatchPage,
+	omdbResponse *OmdbMovieResponse,
+	movieWatch *MovieWatchPage,
+	taxonomy *GenreTaxonomy,
 ) (*MoviePage, error) {
 	year, err := strconv.Atoi(omdbResponse.Year)
 	if err != nil {
@@ -486,7 +738,9 @@ func CreateMoviePage(
 		log.Printf("Unable to parse %v, setting to null", omdbResponse.Runtime)
 	}
 
-	genres := SplitOnCommaAndTrim(omdbResponse.Genre)
+	genres := taxonomy.Normalize(
+		SplitOnCommaAndTrim(omdbResponse.Genre),
+	)
 
 	directors := SplitOnCommaAndTrim(omdbResponse.Director)
 
@@ -498,6 +752,7 @@ func CreateMoviePage(
 		Title:          omdbResponse.Title,
 		ImdbLink:       fmt.Sprintf("https://www.imdb.com/title/%v/", omdbResponse.ImdbID),
 		Genres:         genres,
+		GenreTags:      taxonomy.Tags(genres),
 		Directors:      directors,
 		Writers:        writers,
 		Actors:         actors,
@@ -516,12 +771,41 @@ func CreateMoviePage(
 		Beast:          movieWatch.Beast,
 		Godzilla:       movieWatch.Godzilla,
 		WallpaperFu:    movieWatch.WallpaperFu,
+		Poster:         omdbResponse.Poster,
 	}, nil
 }
 
+var REVIEW_TEMPLATE = `# Review: {{.MovieTitle}}
+movie:: [[{{.MovieTitle}} ({{.ImdbId}})]]
+{{with .Watched}}watch:: [[{{.}} {{$.MovieTitle}}]]
+{{end}}liked:: {{.Liked}}
+rating:: {{if .Rating}}{{.Rating}}{{end}}
+{{with .Uuid}}review_id:: {{.}}
+{{end}}
+## Review
+{{.Review}}
+`
+
+// CreateMovieReviewPage is the page for a review in the database. The
+// title is the movie page's file title from vaultNames so the link resolves.
+func CreateMovieReviewPage(
+	row *database.GetReviewRow, vaultNames VaultNames,
+) *MovieReviewPage {
+	return &MovieReviewPage{
+		Uuid:       row.Uuid,
+		MovieTitle: vaultNames.FileTitle(row.ImdbID, row.MovieTitle),
+		ImdbId:     row.ImdbID,
+		Watched:    row.Watched.String,
+		Liked:      row.Liked != 0,
+		Review:     strings.TrimSpace(row.Review),
+		Rating:     row.Rating.Float64,
+	}
+}
+
 type MovieReviewParser struct {
 	DataExtractor   *regexp.Regexp
 	TitleExtractor  *regexp.Regexp
+	WatchExtractor  *regexp.Regexp
 	ReviewExtractor *regexp.Regexp
 }
 
@@ -529,23 +813,28 @@ func CreateMovieReviewParser() (*MovieReviewParser, error) {
 	parser := MovieReviewParser{}
 
 	dataExtractor, err := regexp.Compile(
-		`# Review:.*\n((?:.|\n)*)\n## Review`,
+		`(?s)# Review:[^\n]*\n(.*?)\n## Review`,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error compiling regex for data: %v", err)
 	}
 	parser.DataExtractor = dataExtractor
 
-	titleExtractor, err := regexp.Compile(
-		`\[\[([a-zA-z0-9:\-/' ]+) \((tt\d{7,8})\)\]\]`,
-	)
+	titleExtractor, err := regexp.Compile(MOVIE_LINK_PATTERN)
 	if err != nil {
 		return nil, fmt.Errorf("error compiling regex for movie: %v", err)
 	}
 	parser.TitleExtractor = titleExtractor
 
+	// Watch pages are named for the date they were watched.
+	watchExtractor, err := regexp.Compile(`\[\[(\d{4}-\d{2}-\d{2}) .+\]\]`)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling regex for watch: %v", err)
+	}
+	parser.WatchExtractor = watchExtractor
+
 	reviewExtractor, err := regexp.Compile(
-		`(?s)## Review(.*)$`,
+		`(?s)\n## Review(.*)$`,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error compiling regex for notes: %v", err)
@@ -555,10 +844,16 @@ func CreateMovieReviewParser() (*MovieReviewParser, error) {
 }
 
 type MovieReviewPage struct {
+	// Empty until the review is in the database.
+	Uuid       string
 	MovieTitle string
 	ImdbId     string
-	Liked      bool
-	Review     string
+	// The date of the watch the review is for, empty if it's for the movie.
+	Watched string
+	Liked   bool
+	Review  string
+	// Stars out of five for the movie overall, 0 if unrated.
+	Rating float64
 }
 
 func (p *MovieReviewParser) ParseMovieReviewPage(filename string) (
@@ -597,6 +892,17 @@ func (p *MovieReviewParser) ParseMovieReviewPage(filename string) (
 			}
 			page.MovieTitle = string(titleMatch[1])
 			page.ImdbId = string(titleMatch[2])
+		case "watch":
+			if data == "" {
+				continue
+			}
+			watchMatch := p.WatchExtractor.FindStringSubmatch(data)
+			if len(watchMatch) != 2 {
+				return nil, fmt.Errorf("expected %v to link a watch", data)
+			}
+			page.Watched = watchMatch[1]
+		case "review_id":
+			page.Uuid = data
 		case "liked":
 			liked, err := strconv.ParseBool(data)
 			if err != nil {
@@ -605,6 +911,12 @@ func (p *MovieReviewParser) ParseMovieReviewPage(filename string) (
 				)
 			}
 			page.Liked = liked
+		case "rating":
+			rating, err := ParseStarRating(data)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing rating: %v", err)
+			}
+			page.Rating = rating
 		}
 	}
 
@@ -614,7 +926,7 @@ func (p *MovieReviewParser) ParseMovieReviewPage(filename string) (
 			"expected 2 matches for review, got %v", matchLen,
 		)
 	}
-	page.Review = string(reviewMatch[1])
+	page.Review = strings.TrimSpace(string(reviewMatch[1]))
 
 	return &page, nil
 }
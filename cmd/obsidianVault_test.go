@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"bytes"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"path"
+	"regexp"
+	"strings"
 	"testing"
+	"text/template"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -149,14 +158,12 @@ func TestParseWatchPage(t *testing.T) {
 		Zombies:     false,
 		Godzilla:    false,
 		WallpaperFu: true,
-		Notes: `
-"Don't be afraid, it's only friendly fire"
+		Notes: `"Don't be afraid, it's only friendly fire"
 "I must be batting 750 with the bereaved" - army dude who notifies widows
 "Must be awful lonely being dead"
 Prevert uncle sam on stilts
 Literally the worst national anthem rendition in existence.
-A fuckin sack race half marathon obstacle course
-		`,
+A fuckin sack race half marathon obstacle course`,
 	}
 	if !cmp.Equal(truth, *answer) {
 		t.Errorf("expected \n%v, got \n%v", truth, *answer)
@@ -172,6 +179,7 @@ func TestParseMoviePage(t *testing.T) {
 		Title:          "XTRO",
 		ImdbLink:       "https://www.imdb.com/title/tt0086610/",
 		Genres:         []string{"Horror", "Sci-Fi"},
+		GenreTags:      []string{"Horror", "Sci-Fi"},
 		Directors:      []string{"Harry Bromley Davenport"},
 		Actors:         []string{"Philip Sayer", "Bernice Stegers", "Danny Brainin"},
 		Writers:        []string{"Harry Bromley Davenport", "Iain Cassie", "Michel Parry"},
@@ -226,6 +234,7 @@ func TestCreateMoviePage(t *testing.T) {
 		Title:          "Tenebrae",
 		ImdbLink:       "https://www.imdb.com/title/tt0084777/",
 		Genres:         []string{"Horror", "Mystery", "Thriller"},
+		GenreTags:      []string{"horror", "mystery", "thriller"},
 		Directors:      []string{"Dario Argento"},
 		Actors:         []string{"Anthony Franciosa", "Giuliano Gemma", "John Saxon"},
 		Writers:        []string{"Dario Argento"},
@@ -244,9 +253,12 @@ func TestCreateMoviePage(t *testing.T) {
 		Beast:          false,
 		Godzilla:       false,
 		WallpaperFu:    false,
+		Poster:         omdbResponse.Poster,
 	}
 
-	answer, err := CreateMoviePage(omdbResponse, movieWatch)
+	answer, err := CreateMoviePage(
+		omdbResponse, movieWatch, sampleGenreTaxonomy(),
+	)
 	if err != nil {
 		t.Errorf("Encountered error: %v", err)
 	}
@@ -274,14 +286,595 @@ func TestParseMovieReviewPage(t *testing.T) {
 		MovieTitle: "Uncle Sam",
 		ImdbId:     "tt0118025",
 		Liked:      true,
-		Review: `
-Look there's a zombie soldier dressed as Uncle Sam who blows people up with fireworks.
+		Review: `Look there's a zombie soldier dressed as Uncle Sam who blows people up with fireworks.
 Do you really want anything more in a movie?
 Oh you want a prevert peeping Tom not-zombie Uncle Sam on stilts too?
-We got you.
-		`,
+We got you.`,
 	}
 	if !cmp.Equal(truth, *answer) {
 		t.Errorf("Expected \n%v, got \n%v", truth, *answer)
 	}
 }
+
+func TestParseMovieReviewPageForWatch(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "Tenebrae (tt0084777) Review 2022-10-31.md")
+	if err := os.WriteFile(fileName, []byte(`# Review: Tenebrae
+movie:: [[Tenebrae (tt0084777)]]
+watch:: [[2022-10-31 Tenebrae]]
+liked:: true
+rating:: 4.5
+review_id:: 9a1f4a2e-4a5e-4c41-9f4e-1f0bfae3a0a1
+
+## Review
+Razor sharp.
+`), 0644); err != nil {
+		t.Fatalf("Error writing page: %v", err)
+	}
+
+	parser, err := CreateMovieReviewParser()
+	if err != nil {
+		t.Fatalf("Error creating parser: %v", err)
+	}
+	answer, err := parser.ParseMovieReviewPage(fileName)
+	if err != nil {
+		t.Fatalf("Error parsing page: %v", err)
+	}
+	truth := MovieReviewPage{
+		Uuid:       "9a1f4a2e-4a5e-4c41-9f4e-1f0bfae3a0a1",
+		MovieTitle: "Tenebrae",
+		ImdbId:     "tt0084777",
+		Watched:    "2022-10-31",
+		Liked:      true,
+		Review:     "Razor sharp.",
+		Rating:     4.5,
+	}
+	if !cmp.Equal(truth, *answer) {
+		t.Errorf("Expected \n%v, got \n%v", truth, *answer)
+	}
+}
+
+func TestReviewPageRoundTrip(t *testing.T) {
+	reviewTemplate, err := template.New("review").Parse(REVIEW_TEMPLATE)
+	if err != nil {
+		t.Fatalf("Error parsing template: %v", err)
+	}
+	parser, err := CreateMovieReviewParser()
+	if err != nil {
+		t.Fatalf("Error creating parser: %v", err)
+	}
+	tests := []MovieReviewPage{
+		{
+			MovieTitle: "Uncle Sam",
+			ImdbId:     "tt0118025",
+			Review:     "Fireworks.",
+		},
+		{
+			Uuid:       "9a1f4a2e-4a5e-4c41-9f4e-1f0bfae3a0a1",
+			MovieTitle: "Tenebrae",
+			ImdbId:     "tt0084777",
+			Watched:    "2022-10-31",
+			Liked:      true,
+			Review:     "Razor sharp.\n\n## Not a heading\nWhite walls.",
+			Rating:     4.5,
+		},
+		{
+			Uuid:       "2b7e0c7a-0d4f-4f5e-8f0a-3c1d2e4f5a6b",
+			MovieTitle: "Q The Winged Serpent",
+			ImdbId:     "tt0083629",
+			Liked:      true,
+			Review:     "Michael Moriarty, scatting.",
+			Rating:     3,
+		},
+	}
+	for _, truth := range tests {
+		fileName := path.Join(t.TempDir(), ReviewPageFileName(&truth))
+		file, err := os.Create(fileName)
+		if err != nil {
+			t.Fatalf("Error creating page: %v", err)
+		}
+		if err := reviewTemplate.Execute(file, truth); err != nil {
+			t.Fatalf("Error rendering page: %v", err)
+		}
+		file.Close()
+
+		answer, err := parser.ParseMovieReviewPage(fileName)
+		if err != nil {
+			t.Fatalf("Error parsing page: %v", err)
+		}
+		if !cmp.Equal(truth, *answer) {
+			t.Errorf("Expected \n%v, got \n%v", truth, *answer)
+		}
+	}
+}
+
+func TestMoviePageReviewLinks(t *testing.T) {
+	movieTemplate, err := template.New("movie").Parse(MOVIE_TEMPLATE)
+	if err != nil {
+		t.Fatalf("Error parsing template: %v", err)
+	}
+	parser, err := CreateMovieParser()
+	if err != nil {
+		t.Fatalf("Error creating parser: %v", err)
+	}
+	truth := sampleMoviePage()
+	truth.Reviews = []string{
+		"Tenebrae (tt0084777) Review",
+		"Tenebrae (tt0084777) Review 2022-10-31",
+	}
+	fileName := path.Join(t.TempDir(), "Tenebrae (tt0084777).md")
+	file, err := os.Create(fileName)
+	if err != nil {
+		t.Fatalf("Error creating page: %v", err)
+	}
+	if err := movieTemplate.Execute(file, truth); err != nil {
+		t.Fatalf("Error rendering page: %v", err)
+	}
+	file.Close()
+
+	answer, err := parser.ParsePage(fileName)
+	if err != nil {
+		t.Fatalf("Error parsing page: %v", err)
+	}
+	if !cmp.Equal(*truth, *answer) {
+		t.Errorf("Expected \n%v, got \n%v", *truth, *answer)
+	}
+}
+
+var updateGolden = flag.Bool(
+	"update", false, "rewrite the golden files in testdata/vault",
+)
+
+// renderAndParse writes the page with the template and reads it back with
+// the parse function.
+func renderAndParse[T any](
+	t *testing.T,
+	pageTemplate string,
+	page *T,
+	parse func(string) (*T, error),
+) *T {
+	t.Helper()
+	parsedTemplate, err := template.New("page").Parse(pageTemplate)
+	if err != nil {
+		t.Fatalf("Error parsing template: %v", err)
+	}
+	var body bytes.Buffer
+	if err := parsedTemplate.Execute(&body, page); err != nil {
+		t.Fatalf("Error executing template: %v", err)
+	}
+	fileName := path.Join(t.TempDir(), "page.md")
+	if err := os.WriteFile(fileName, body.Bytes(), 0644); err != nil {
+		t.Fatalf("Error writing page: %v", err)
+	}
+	answer, err := parse(fileName)
+	if err != nil {
+		t.Fatalf("Error parsing page %q: %v", body.String(), err)
+	}
+	return answer
+}
+
+// isPageLine is whether the value can be written on one line of a page and
+// read back the same.
+func isPageLine(value string) bool {
+	return utf8.ValidString(value) &&
+		!strings.ContainsAny(value, "\r\n") &&
+		value == strings.TrimSpace(value)
+}
+
+// fuzzNames splits the fuzzed value into the names for a list field on a
+// movie page, false if they can't survive the trip.
+func fuzzNames(value string) ([]string, bool) {
+	if value == "" {
+		return nil, true
+	}
+	names := strings.Split(value, ";")
+	for ii := range names {
+		if names[ii] == "" || !isPageLine(names[ii]) ||
+			strings.ContainsAny(names[ii], ",[]") {
+			return nil, false
+		}
+	}
+	return names, true
+}
+
+var fuzzTimezones = []string{
+	"", "UTC", "America/Chicago", "Asia/Kolkata", "Australia/Lord_Howe",
+}
+
+func FuzzMovieWatchPageRoundTrip(f *testing.F) {
+	f.Add(
+		"Uncle Sam", "Shudder", "Literally the worst national anthem.",
+		uint8(0b10101001), uint8(7), uint32(118025), int64(1656720000),
+		uint8(2),
+	)
+	f.Add(
+		"Mother!", "", "", uint8(0), uint8(0), uint32(5109784),
+		int64(1667269800), uint8(len(fuzzTimezones)),
+	)
+	f.Add(
+		"Tetsuo: The Iron Man", "Criterion Channel",
+		"## Tags\nname:: [[Nope (tt0000000)]]\n\n## Notes\nmore",
+		uint8(255), uint8(10), uint32(96251), int64(-86400), uint8(4),
+	)
+	parser, err := CreateMovieWatchParser()
+	if err != nil {
+		f.Fatalf("Error creating parser: %v", err)
+	}
+	f.Fuzz(func(
+		t *testing.T,
+		title string,
+		service string,
+		notes string,
+		flags uint8,
+		rating uint8,
+		imdbNumber uint32,
+		watchedUnix int64,
+		timezone uint8,
+	) {
+		if !isPageLine(title) || cleanTitle(title) == "" ||
+			!isPageLine(service) || !utf8.ValidString(notes) ||
+			notes != strings.TrimSpace(notes) {
+			t.Skip()
+		}
+		imdbId := fmt.Sprintf("tt%07d", imdbNumber%100000000)
+		// Keep it to four digit years.
+		watchedTime := time.Unix(watchedUnix%100000000000, 0).UTC()
+		page := MovieWatchPage{
+			Title:       title,
+			FileTitle:   cleanTitle(title),
+			Watched:     watchedTime.Format(WATCHED_DATE_LAYOUT),
+			ImdbLink:    fmt.Sprintf("https://www.imdb.com/title/%v/", imdbId),
+			ImdbId:      imdbId,
+			FirstTime:   flags&1 != 0,
+			JoeBob:      flags&2 != 0,
+			CallFelissa: flags&4 != 0,
+			Beast:       flags&8 != 0,
+			Godzilla:    flags&16 != 0,
+			Zombies:     flags&32 != 0,
+			Slasher:     flags&64 != 0,
+			WallpaperFu: flags&128 != 0,
+			Service:     service,
+			Notes:       notes,
+			Rating:      float64(rating%11) / 2,
+		}
+		if int(timezone) < len(fuzzTimezones) {
+			location := time.UTC
+			if fuzzTimezones[timezone] != "" {
+				location, err = time.LoadLocation(fuzzTimezones[timezone])
+				if err != nil {
+					t.Fatalf("Error loading timezone: %v", err)
+				}
+			}
+			page.WatchedAt, err = NewWatchedAt(
+				watchedTime.In(location).Format(time.RFC3339),
+				fuzzTimezones[timezone],
+			)
+			if err != nil {
+				t.Fatalf("Error creating watched at: %v", err)
+			}
+			page.Watched = page.WatchedAt.Date()
+		}
+
+		answer := renderAndParse(t, MOVIE_WATCH_TEMPLATE, &page, parser.ParsePage)
+		if !cmp.Equal(page, *answer) {
+			t.Errorf("Expected %v, got %v", page, *answer)
+		}
+	})
+}
+
+func FuzzMoviePageRoundTrip(f *testing.F) {
+	f.Add(
+		"Tenebrae", "Horror;Mystery", "Dario Argento", "Anthony Franciosa;John Saxon",
+		"Dario Argento", "Tenebrae (tt0084777) Review 2022-10-31",
+		"An American author in Rome.", "R", 1982, 101, uint8(0b101),
+	)
+	f.Add(
+		"Dr. Jekyll & Sister Hyde", "", "", "", "", "", "", "", 1971, 0,
+		uint8(0),
+	)
+	f.Add(
+		"Häxan", "Documentary", "Benjamin Christensen", "", "", "",
+		"title:: Nope ## Tags", "Not Rated", 1922, -1, uint8(255),
+	)
+	f.Add(
+		"Tetsuo: The Iron Man", "Sci-Fi/Horror", "Shin'ya Tsukamoto",
+		"Tomorowo Taguchi", "Shin'ya Tsukamoto", "", "", "", 1989, 67,
+		uint8(0),
+	)
+	parser, err := CreateMovieParser()
+	if err != nil {
+		f.Fatalf("Error creating parser: %v", err)
+	}
+	f.Fuzz(func(
+		t *testing.T,
+		title string,
+		genres string,
+		directors string,
+		actors string,
+		writers string,
+		reviews string,
+		plot string,
+		rated string,
+		year int,
+		runtimeMinutes int,
+		flags uint8,
+	) {
+		if !isPageLine(title) || !isPageLine(plot) || !isPageLine(rated) {
+			t.Skip()
+		}
+		page := MoviePage{
+			Title:          title,
+			ImdbLink:       "https://www.imdb.com/title/tt0084777/",
+			Year:           year,
+			RuntimeMinutes: runtimeMinutes,
+			Rating:         rated,
+			Released:       "1984-02-17",
+			Plot:           plot,
+			Country:        "Italy",
+			Language:       "Italian, Spanish",
+			BoxOffice:      "N/A",
+			Production:     "N/A",
+			CallFelissa:    flags&1 != 0,
+			Slasher:        flags&2 != 0,
+			Zombies:        flags&4 != 0,
+			Beast:          flags&8 != 0,
+			Godzilla:       flags&16 != 0,
+			WallpaperFu:    flags&32 != 0,
+		}
+		var ok [5]bool
+		page.Genres, ok[0] = fuzzNames(genres)
+		page.Directors, ok[1] = fuzzNames(directors)
+		page.Actors, ok[2] = fuzzNames(actors)
+		page.Writers, ok[3] = fuzzNames(writers)
+		page.Reviews, ok[4] = fuzzNames(reviews)
+		for ii := range ok {
+			if !ok[ii] {
+				t.Skip()
+			}
+		}
+
+		answer := renderAndParse(t, MOVIE_TEMPLATE, &page, parser.ParsePage)
+		if !cmp.Equal(page, *answer) {
+			t.Errorf("Expected %v, got %v", page, *answer)
+		}
+	})
+}
+
+func FuzzMovieLinkPattern(f *testing.F) {
+	for _, title := range []string{
+		"Tenebrae", "Mother!", "Dr. Jekyll & Sister Hyde", "Häxan",
+		"Tetsuo: The Iron Man", "V/H/S 94", "Alien (tt0078748)",
+		"[[Brackets]] | #hash ^caret", " Grizzly 2: Revenge ",
+	} {
+		f.Add(title)
+	}
+	linkRegex := regexp.MustCompile(MOVIE_LINK_PATTERN)
+	f.Fuzz(func(t *testing.T, title string) {
+		fileTitle := cleanTitle(title)
+		if fileTitle == "" {
+			t.Skip()
+		}
+		if again := cleanTitle(fileTitle); again != fileTitle {
+			t.Errorf("Expected cleaning %q again to be a no-op, got %q", fileTitle, again)
+		}
+		link := fmt.Sprintf("name:: [[%v (tt0084777)]]", fileTitle)
+		match := linkRegex.FindStringSubmatch(link)
+		if len(match) != 3 {
+			t.Fatalf("Expected %q to match the movie link pattern", link)
+		}
+		if match[1] != fileTitle || match[2] != "tt0084777" {
+			t.Errorf(
+				"Expected %q and tt0084777, got %q and %q",
+				fileTitle, match[1], match[2],
+			)
+		}
+	})
+}
+
+// The fixture vault, with the titles that have tripped up the parsers.
+var goldenWatchPages = []MovieWatchPage{
+	{
+		Title:     "Mother!",
+		FileTitle: "Mother!",
+		Watched:   "2022-10-31",
+		ImdbLink:  "https://www.imdb.com/title/tt5109784/",
+		ImdbId:    "tt5109784",
+		FirstTime: true,
+		Beast:     true,
+		Service:   "Paramount+",
+		Notes:     "The house is alive.\n\n## Not a heading\nname:: [[Nope (tt0000000)]]",
+		WatchedAt: mustWatchedAt("2022-10-31T21:30:00-05:00", "America/Chicago"),
+		Rating:    3.5,
+	},
+	{
+		Title:       "Tetsuo: The Iron Man",
+		FileTitle:   "Tetsuo The Iron Man",
+		Watched:     "2023-01-14",
+		ImdbLink:    "https://www.imdb.com/title/tt0096251/",
+		ImdbId:      "tt0096251",
+		JoeBob:      true,
+		WallpaperFu: true,
+		Service:     "Criterion Channel",
+	},
+	{
+		Title:     "Häxan",
+		FileTitle: "Häxan",
+		Watched:   "2022-10-01",
+		ImdbLink:  "https://www.imdb.com/title/tt0013257/",
+		ImdbId:    "tt0013257",
+		Service:   "Kanopy",
+		Notes:     "Witchcraft Through the Ages, with the Burroughs narration.",
+		Rating:    4,
+	},
+}
+
+var goldenMoviePages = []MoviePage{
+	{
+		Title:          "Dr. Jekyll & Sister Hyde",
+		ImdbLink:       "https://www.imdb.com/title/tt0068502/",
+		Genres:         []string{"Horror", "Sci-Fi"},
+		GenreTags:      []string{"horror", "science-fiction"},
+		Directors:      []string{"Roy Ward Baker"},
+		Actors:         []string{"Ralph Bates", "Martine Beswick"},
+		Writers:        []string{"Brian Clemens", "Robert Louis Stevenson"},
+		Year:           1971,
+		RuntimeMinutes: 97,
+		Rating:         "R",
+		Released:       "1972-02-01",
+		Plot:           "Dr. Jekyll's elixir of life turns him into a woman.",
+		Country:        "United Kingdom",
+		Language:       "English",
+		BoxOffice:      "N/A",
+		Production:     "N/A",
+		Beast:          true,
+		Reviews:        []string{"Dr. Jekyll & Sister Hyde (tt0068502) Review"},
+	},
+	{
+		Title:      "Der Golem, wie er in die Welt kam",
+		ImdbLink:   "https://www.imdb.com/title/tt0011237/",
+		Genres:     []string{"Fantasy", "Horror"},
+		GenreTags:  []string{"fantasy", "horror"},
+		Directors:  []string{"Carl Boese", "Paul Wegener"},
+		Year:       1920,
+		Rating:     "Not Rated",
+		Released:   "1921-06-19",
+		Country:    "Germany",
+		Language:   "None, German",
+		BoxOffice:  "N/A",
+		Production: "N/A",
+	},
+	{
+		Title:      "Don't Look Now",
+		ImdbLink:   "https://www.imdb.com/title/tt0069995/",
+		Genres:     []string{"Drama", "Horror", "Mystery"},
+		GenreTags:  []string{"drama", "horror", "mystery"},
+		Directors:  []string{"Nicolas Roeg"},
+		Actors:     []string{"Julie Christie", "Donald Sutherland"},
+		Writers:    []string{"Daphne Du Maurier", "Allan Scott", "Chris Bryant"},
+		Year:       1973,
+		Rating:     "R",
+		Released:   "1973-12-09",
+		Plot:       "A married couple grieving their daughter go to Venice.",
+		Country:    "United Kingdom, Italy",
+		Language:   "English, Italian, French",
+		BoxOffice:  "N/A",
+		Production: "N/A",
+		Reviews: []string{
+			"Don't Look Now (tt0069995) Review 2022-10-29",
+			"Don't Look Now (tt0069995) Review 2023-10-28",
+		},
+		RuntimeMinutes: 110,
+	},
+}
+
+var goldenReviewPages = []MovieReviewPage{
+	{
+		Uuid:       "0f5a4b42-3bd4-4c8b-9bde-1ec0a3a0f1a4",
+		MovieTitle: "Dr. Jekyll & Sister Hyde",
+		ImdbId:     "tt0068502",
+		Liked:      true,
+		Review:     "Better than it has any right to be.\n\n## Review\nStill the review.",
+		Rating:     4,
+	},
+	{
+		MovieTitle: "Don't Look Now",
+		ImdbId:     "tt0069995",
+		Watched:    "2022-10-29",
+		Liked:      true,
+		Review:     "liked:: false is what a lesser movie would get.",
+	},
+}
+
+func mustWatchedAt(timestamp string, timezone string) *WatchedAt {
+	watchedAt, err := NewWatchedAt(timestamp, timezone)
+	if err != nil {
+		log.Panicf("Error creating watched at: %v", err)
+	}
+	return watchedAt
+}
+
+// checkGoldenPage renders the page and compares it to the golden file, then
+// parses the golden file and compares it to the page.
+func checkGoldenPage[T any](
+	t *testing.T,
+	pageTemplate string,
+	page *T,
+	fileName string,
+	parse func(string) (*T, error),
+) {
+	t.Helper()
+	parsedTemplate, err := template.New("page").Parse(pageTemplate)
+	if err != nil {
+		t.Fatalf("Error parsing template: %v", err)
+	}
+	var body bytes.Buffer
+	if err := parsedTemplate.Execute(&body, page); err != nil {
+		t.Fatalf("Error executing template: %v", err)
+	}
+	if *updateGolden {
+		if err := os.WriteFile(fileName, body.Bytes(), 0644); err != nil {
+			t.Fatalf("Error writing %v: %v", fileName, err)
+		}
+	}
+	golden, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Error reading %v: %v", fileName, err)
+	}
+	if !bytes.Equal(golden, body.Bytes()) {
+		t.Errorf("Expected %v to be\n%s\ngot\n%s", fileName, golden, body.Bytes())
+	}
+	answer, err := parse(fileName)
+	if err != nil {
+		t.Fatalf("Error parsing %v: %v", fileName, err)
+	}
+	if !cmp.Equal(*page, *answer) {
+		t.Errorf("Expected %v, got %v", *page, *answer)
+	}
+}
+
+func TestGoldenVault(t *testing.T) {
+	vaultDir := path.Join("testdata", "vault")
+
+	watchParser, err := CreateMovieWatchParser()
+	if err != nil {
+		t.Fatalf("Error creating parser: %v", err)
+	}
+	for ii := range goldenWatchPages {
+		page := &goldenWatchPages[ii]
+		checkGoldenPage(
+			t, MOVIE_WATCH_TEMPLATE, page,
+			path.Join(vaultDir, "Watches", MovieWatchPageFileName(page)),
+			watchParser.ParsePage,
+		)
+	}
+
+	movieParser, err := CreateMovieParser()
+	if err != nil {
+		t.Fatalf("Error creating parser: %v", err)
+	}
+	for ii := range goldenMoviePages {
+		page := &goldenMoviePages[ii]
+		imdbId := strings.TrimSuffix(
+			strings.TrimPrefix(page.ImdbLink, "https://www.imdb.com/title/"), "/",
+		)
+		checkGoldenPage(
+			t, MOVIE_TEMPLATE, page,
+			path.Join(
+				vaultDir, "Movies", MoviePageFileName(cleanTitle(page.Title), imdbId),
+			),
+			movieParser.ParsePage,
+		)
+	}
+
+	reviewParser, err := CreateMovieReviewParser()
+	if err != nil {
+		t.Fatalf("Error creating parser: %v", err)
+	}
+	for ii := range goldenReviewPages {
+		page := &goldenReviewPages[ii]
+		checkGoldenPage(
+			t, REVIEW_TEMPLATE, page,
+			path.Join(vaultDir, "Reviews", ReviewPageFileName(page)),
+			reviewParser.ParseMovieReviewPage,
+		)
+	}
+}
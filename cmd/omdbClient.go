@@ -43,6 +43,24 @@ type OmdbMovieResponse struct {
 	Response   string   `json:"Response"`
 }
 
+type OmdbSearchResult struct {
+	Title  string `json:"Title"`
+	Year   string `json:"Year"`
+	ImdbID string `json:"imdbID"`
+	Type   string `json:"Type"`
+	Poster string `json:"Poster"`
+}
+
+type OmdbSearchResponse struct {
+	Search       []OmdbSearchResult `json:"Search"`
+	TotalResults string             `json:"totalResults"`
+	Response     string             `json:"Response"`
+	Error        string             `json:"Error"`
+}
+
+// What OMDB says when a search comes up empty.
+const OMDB_NOT_FOUND = "Movie not found!"
+
 type Rating struct {
 	Source string `json:"Source"`
 	Value  string `json:"Value"`
@@ -58,31 +76,65 @@ func NewOmdbClient(key string) *OmdbClient {
 	return &client
 }
 
-func (c *OmdbClient) GetMovie(movieId string) (*OmdbMovieResponse, error) {
-	params := url.Values{}
+// get makes the request and unmarshals the response into target.
+func (c *OmdbClient) get(params url.Values, target interface{}) error {
 	params.Set("apikey", c.key)
-	params.Set("i", movieId)
 
 	url := fmt.Sprintf("%v/?%v", c.rootUrl, params.Encode())
 
 	request, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
+		return fmt.Errorf("error creating request: %v", err)
 	}
 
 	resp, err := c.client.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("error making API request: %v", err)
+		return fmt.Errorf("error making API request: %v", err)
 	}
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %v", err)
+		return fmt.Errorf("error reading response body: %v", err)
 	}
 
+	if err = json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("error unmarshalling response: %v", err)
+	}
+	return nil
+}
+
+func (c *OmdbClient) GetMovie(movieId string) (*OmdbMovieResponse, error) {
+	params := url.Values{}
+	params.Set("i", movieId)
+
 	var movieResponse OmdbMovieResponse
-	if err = json.Unmarshal(body, &movieResponse); err != nil {
-		return nil, fmt.Errorf("error unmarshalling response: %v", err)
+	if err := c.get(params, &movieResponse); err != nil {
+		return nil, err
 	}
 	return &movieResponse, nil
 }
+
+// SearchMovies searches OMDB for movies by title, optionally narrowed to a
+// year. Only the first page of results comes back, which is plenty for
+// picking a movie out.
+func (c *OmdbClient) SearchMovies(
+	title string, year string,
+) (*OmdbSearchResponse, error) {
+	params := url.Values{}
+	params.Set("s", title)
+	params.Set("type", "movie")
+	if year != "" {
+		params.Set("y", year)
+	}
+
+	var searchResponse OmdbSearchResponse
+	if err := c.get(params, &searchResponse); err != nil {
+		return nil, err
+	}
+	// No results comes back as an error, but it isn't one.
+	if searchResponse.Response == "False" &&
+		searchResponse.Error != OMDB_NOT_FOUND {
+		return nil, fmt.Errorf("error searching OMDB: %v", searchResponse.Error)
+	}
+	return &searchResponse, nil
+}
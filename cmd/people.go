@@ -0,0 +1,271 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// peopleCmd represents the people command
+var peopleCmd = &cobra.Command{
+	Use:   "people",
+	Short: "Lists and cleans up the people credited on movies.",
+}
+
+var peopleListCmd = &cobra.Command{
+	Use:   "list [pattern]",
+	Short: "Lists people, optionally only those matching a SQL LIKE pattern.",
+	Run:   peopleList,
+	Args:  cobra.RangeArgs(0, 1),
+}
+
+var peopleMergeCmd = &cobra.Command{
+	Use:   "merge <from-id> <into-id>",
+	Short: "Merges one person into another, keeping the old name as an alias.",
+	Run:   peopleMerge,
+	Args:  cobra.ExactArgs(2),
+}
+
+var peopleRenameCmd = &cobra.Command{
+	Use:   "rename <id> <name>",
+	Short: "Renames a person, keeping the old name as an alias.",
+	Run:   peopleRename,
+	Args:  cobra.ExactArgs(2),
+}
+
+func init() {
+	rootCmd.AddCommand(peopleCmd)
+	peopleCmd.AddCommand(peopleListCmd)
+	peopleCmd.AddCommand(peopleMergeCmd)
+	peopleCmd.AddCommand(peopleRenameCmd)
+
+	peopleRenameCmd.Flags().StringP(
+		"imdb-id", "i", "", "The IMDB ID (nm...) for the person.",
+	)
+}
+
+func parsePersonID(arg string) int64 {
+	personID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		log.Panicf("Error parsing person id %v: %v", arg, err)
+	}
+	return personID
+}
+
+func peopleList(cmd *cobra.Command, args []string) {
+	pattern := "%"
+	if len(args) > 0 {
+		pattern = args[0]
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	people, err := queries.ListPeople(ctx, pattern)
+	if err != nil {
+		log.Panicf("Error listing people: %v", err)
+	}
+	for ii := range people {
+		aliases, err := queries.GetAliasesForPerson(ctx, people[ii].ID)
+		if err != nil {
+			log.Panicf(
+				"Error getting aliases for %v: %v", people[ii].Name, err,
+			)
+		}
+		line := fmt.Sprintf(
+			"%v\t%v\t%v credits", people[ii].ID, people[ii].Name,
+			people[ii].NumCredits,
+		)
+		if people[ii].ImdbID.Valid {
+			line = fmt.Sprintf("%v\t%v", line, people[ii].ImdbID.String)
+		}
+		if len(aliases) > 0 {
+			line = fmt.Sprintf(
+				"%v\taka %v", line, strings.Join(aliases, "; "),
+			)
+		}
+		fmt.Println(line)
+	}
+}
+
+func MergePeople(
+	ctx context.Context, queries *database.Queries, fromID int64, intoID int64,
+) error {
+	if fromID == intoID {
+		return fmt.Errorf("can't merge person %v into themselves", fromID)
+	}
+	from, err := queries.GetPerson(ctx, fromID)
+	if err != nil {
+		return fmt.Errorf("error getting person %v: %v", fromID, err)
+	}
+	into, err := queries.GetPerson(ctx, intoID)
+	if err != nil {
+		return fmt.Errorf("error getting person %v: %v", intoID, err)
+	}
+
+	// Credits the other person already has for the same movie and role are
+	// left behind by the update and cleaned up by the delete.
+	if err := queries.ReassignCreditsForPerson(
+		ctx, database.ReassignCreditsForPersonParams{
+			PersonID: intoID, PersonID_2: fromID,
+		},
+	); err != nil {
+		return fmt.Errorf("error reassigning credits: %v", err)
+	}
+	if err := queries.DeleteCreditsForPerson(ctx, fromID); err != nil {
+		return fmt.Errorf("error deleting credits: %v", err)
+	}
+	if err := queries.ReassignAliasesForPerson(
+		ctx, database.ReassignAliasesForPersonParams{
+			PersonID: intoID, PersonID_2: fromID,
+		},
+	); err != nil {
+		return fmt.Errorf("error reassigning aliases: %v", err)
+	}
+	if err := queries.DeletePerson(ctx, fromID); err != nil {
+		return fmt.Errorf("error deleting person %v: %v", fromID, err)
+	}
+	if err := queries.InsertPersonAlias(
+		ctx, database.InsertPersonAliasParams{
+			Alias: from.Name, PersonID: intoID,
+		},
+	); err != nil {
+		return fmt.Errorf("error inserting alias %v: %v", from.Name, err)
+	}
+	if !into.ImdbID.Valid && from.ImdbID.Valid {
+		if err := queries.UpdatePersonImdbID(
+			ctx, database.UpdatePersonImdbIDParams{
+				ImdbID: from.ImdbID, ID: intoID,
+			},
+		); err != nil {
+			return fmt.Errorf("error updating imdb id: %v", err)
+		}
+	}
+	return nil
+}
+
+func peopleMerge(cmd *cobra.Command, args []string) {
+	fromID := parsePersonID(args[0])
+	intoID := parsePersonID(args[1])
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Panicf("Error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	if err := MergePeople(ctx, queries.WithTx(tx), fromID, intoID); err != nil {
+		log.Panicf("Error merging %v into %v: %v", fromID, intoID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Panicf("Error committing transaction: %v", err)
+	}
+	log.Printf("Merged %v into %v.", fromID, intoID)
+}
+
+func RenamePerson(
+	ctx context.Context,
+	queries *database.Queries,
+	personID int64,
+	name string,
+	imdbID string,
+) error {
+	person, err := queries.GetPerson(ctx, personID)
+	if err != nil {
+		return fmt.Errorf("error getting person %v: %v", personID, err)
+	}
+	if person.Name != name {
+		otherID, err := queries.FindPersonByName(ctx, name)
+		if err == nil {
+			return fmt.Errorf(
+				"%v is already person %v, merge them instead", name, otherID,
+			)
+		} else if err != sql.ErrNoRows {
+			return fmt.Errorf("error finding person %v: %v", name, err)
+		}
+		aliasID, err := queries.FindPersonByAlias(ctx, name)
+		if err == nil && aliasID != personID {
+			return fmt.Errorf(
+				"%v is an alias of person %v, merge them instead", name, aliasID,
+			)
+		} else if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("error finding alias %v: %v", name, err)
+		}
+		if err := queries.UpdatePersonName(
+			ctx, database.UpdatePersonNameParams{Name: name, ID: personID},
+		); err != nil {
+			return fmt.Errorf("error renaming person %v: %v", personID, err)
+		}
+		if err := queries.InsertPersonAlias(
+			ctx, database.InsertPersonAliasParams{
+				Alias: person.Name, PersonID: personID,
+			},
+		); err != nil {
+			return fmt.Errorf("error inserting alias %v: %v", person.Name, err)
+		}
+	}
+	if imdbID != "" {
+		if err := queries.UpdatePersonImdbID(
+			ctx, database.UpdatePersonImdbIDParams{
+				ImdbID: textToNullString(imdbID), ID: personID,
+			},
+		); err != nil {
+			return fmt.Errorf("error updating imdb id: %v", err)
+		}
+	}
+	return nil
+}
+
+func peopleRename(cmd *cobra.Command, args []string) {
+	personID := parsePersonID(args[0])
+	name := strings.TrimSpace(args[1])
+
+	imdbID, err := cmd.Flags().GetString("imdb-id")
+	if err != nil {
+		log.Panicf("Error obtaining imdb-id: %v", err)
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Panicf("Error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	if err := RenamePerson(
+		ctx, queries.WithTx(tx), personID, name, imdbID,
+	); err != nil {
+		log.Panicf("Error renaming %v: %v", personID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Panicf("Error committing transaction: %v", err)
+	}
+	log.Printf("Renamed %v to %v.", personID, name)
+}
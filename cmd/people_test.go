@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestResolvePersonID(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	bandID, err := ResolvePersonID(ctx, queries, "Charles Band")
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	uncreditedID, err := ResolvePersonID(ctx, queries, "Charles Band (uncredited)")
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if bandID != uncreditedID {
+		t.Errorf("Expected %v, got %v", bandID, uncreditedID)
+	}
+
+	aliases, err := queries.GetAliasesForPerson(ctx, bandID)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	aliasesTruth := []string{"Charles Band (uncredited)"}
+	if !cmp.Equal(aliasesTruth, aliases) {
+		t.Errorf("Expected %v, got %v", aliasesTruth, aliases)
+	}
+}
+
+func TestInsertMovieCredits(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	if _, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	); err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+
+	people, err := queries.ListPeople(ctx, "%")
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	namesTruth := map[string]int64{
+		"Anthony Franciosa": 1,
+		"Dario Argento":     2,
+		"Giuliano Gemma":    1,
+		"John Saxon":        1,
+	}
+	if len(people) != len(namesTruth) {
+		t.Errorf("Expected %v people, got %v", len(namesTruth), len(people))
+	}
+	for ii := range people {
+		if namesTruth[people[ii].Name] != people[ii].NumCredits {
+			t.Errorf(
+				"Expected %v credits for %v, got %v",
+				namesTruth[people[ii].Name], people[ii].Name,
+				people[ii].NumCredits,
+			)
+		}
+	}
+}
+
+func TestMergeAndRenamePeople(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	moviePage := sampleMoviePage()
+	moviePage.Actors = []string{"Dario Argento", "D. Argento"}
+	if _, err := InsertMovieDetails(db, ctx, queries, moviePage, nil); err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	argentoID, err := queries.FindPersonByName(ctx, "Dario Argento")
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	shortID, err := queries.FindPersonByName(ctx, "D. Argento")
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+
+	if err := MergePeople(ctx, queries, shortID, argentoID); err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	// The actor credit for the merged person collides with the existing one.
+	people, err := queries.ListPeople(ctx, "%Argento")
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if len(people) != 1 || people[0].NumCredits != 3 {
+		t.Errorf("Expected one Argento with 3 credits, got %v", people)
+	}
+	mergedID, err := ResolvePersonID(ctx, queries, "D. Argento")
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if mergedID != argentoID {
+		t.Errorf("Expected %v, got %v", argentoID, mergedID)
+	}
+
+	if err := RenamePerson(
+		ctx, queries, argentoID, "Dario Argento Sr.", "nm0000783",
+	); err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	person, err := queries.GetPerson(ctx, argentoID)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if person.Name != "Dario Argento Sr." || person.ImdbID.String != "nm0000783" {
+		t.Errorf("Expected renamed person, got %v", person)
+	}
+	// Someone else's alias isn't free either.
+	if _, err := ResolvePersonID(ctx, queries, "John Saxon (uncredited)"); err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if err := RenamePerson(
+		ctx, queries, argentoID, "John Saxon (uncredited)", "",
+	); err == nil {
+		t.Error("Expected an error renaming to another person's alias")
+	}
+	renamedID, err := ResolvePersonID(ctx, queries, "Dario Argento")
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if renamedID != argentoID {
+		t.Errorf("Expected %v, got %v", argentoID, renamedID)
+	}
+}
+
+func TestPersonMigrationMatchesResolvePersonID(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	// Back to before the person tables, with credits in the old tables.
+	if err := m.Migrate(20221127030435); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	names := []string{
+		"Jean-Claude (JC) Van Damme (uncredited)",
+		"Jean-Claude",
+		"[Charles Band]",
+		"Charles  Band",
+		"Charles\tBand (producer)",
+		" Stephen King (novel) ",
+		"Stephen King(novel)",
+		"Roger Corman (executive (uncredited))",
+		"(uncredited)",
+	}
+	// One movie each, so each credit is easy to find afterwards.
+	for ii, name := range names {
+		if _, err := db.Exec(
+			"INSERT INTO movie_actor (uuid, movie_uuid, name) VALUES (?, ?, ?)",
+			fmt.Sprintf("actor-%v", ii), fmt.Sprintf("movie-%v", ii), name,
+		); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	queries := database.New(db)
+	ctx := context.Background()
+	migrated := make(map[string]int64)
+	for ii, name := range names {
+		var personID int64
+		if err := db.QueryRow(
+			"SELECT person_id FROM movie_credit WHERE movie_uuid = ?",
+			fmt.Sprintf("movie-%v", ii),
+		).Scan(&personID); err != nil {
+			t.Fatalf("Encountered error finding %q: %v", name, err)
+		}
+		migrated[name] = personID
+	}
+	for _, name := range names {
+		personID, err := ResolvePersonID(ctx, queries, name)
+		if err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		if personID != migrated[name] {
+			person, _ := queries.GetPerson(ctx, migrated[name])
+			t.Errorf(
+				"Expected %q to be %q like the migration, got %q",
+				name, person.Name, NormalizePersonName(name),
+			)
+		}
+	}
+	people, err := queries.ListPeople(ctx, "%")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(people) != 7 {
+		t.Errorf("Expected 7 people, got %v", people)
+	}
+}
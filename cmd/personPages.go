@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"text/template"
+
+	"github.com/timothyrenner/movies-app/database"
+)
+
+var PERSON_TEMPLATE = `
+# {{.Name}}
+
+## Data
+name:: {{.Name}}
+movies:: {{len .Movies}}
+watches:: {{.WatchCount}}
+
+## Movies
+{{range .Movies}}- [[{{.FileTitle}} ({{.ImdbId}})]] ({{.Year}}): {{$sep := ""}}{{range .Roles}}{{$sep}}{{.}}{{$sep = ", "}}{{end}}, watched {{len .Watched}} {{if eq (len .Watched) 1}}time{{else}}times{{end}}{{range .Watched}} [[{{.}}]]{{end}}
+{{end}}
+## Tags
+#person
+
+## Notes
+{{.Notes}}`
+
+// Everything after this line on a generated page belongs to the user and is
+// carried over when the page is rebuilt.
+var preservedNotesExtractor = regexp.MustCompile(`(?s)\n## Notes\n(.*)$`)
+
+const (
+	DIRECTOR_ROLE = "Director"
+	WRITER_ROLE   = "Writer"
+	ACTOR_ROLE    = "Actor"
+)
+
+// The roles in movie_credit, as they're shown on pages.
+var CREDIT_ROLE_NAMES = map[string]string{
+	DIRECTOR_CREDIT_ROLE: DIRECTOR_ROLE,
+	WRITER_CREDIT_ROLE:   WRITER_ROLE,
+	ACTOR_CREDIT_ROLE:    ACTOR_ROLE,
+}
+
+// The order roles are listed in.
+var ROLE_ORDER = map[string]int{
+	DIRECTOR_ROLE: 0,
+	WRITER_ROLE:   1,
+	ACTOR_ROLE:    2,
+}
+
+type PersonMovie struct {
+	Title     string
+	FileTitle string
+	ImdbId    string
+	Year      int
+	Roles     []string
+	Watched   []string
+}
+
+type PersonPage struct {
+	Name   string
+	Movies []PersonMovie
+	Notes  string
+}
+
+func (p *PersonPage) WatchCount() int {
+	watchCount := 0
+	for ii := range p.Movies {
+		watchCount += len(p.Movies[ii].Watched)
+	}
+	return watchCount
+}
+
+// CreatePersonPage lists the movies the person is credited on, whatever
+// names they were credited under.
+func CreatePersonPage(
+	name string,
+	credits []database.GetCreditsForPersonRow,
+	watchDates map[string][]string,
+	vaultNames VaultNames,
+) *PersonPage {
+	page := PersonPage{Name: name, Movies: make([]PersonMovie, 0)}
+	movieIndex := make(map[string]int)
+	for ii := range credits {
+		credit := &credits[ii]
+		role := CREDIT_ROLE_NAMES[credit.Role]
+		if idx, ok := movieIndex[credit.Uuid]; ok {
+			page.Movies[idx].Roles = append(page.Movies[idx].Roles, role)
+			continue
+		}
+		movieIndex[credit.Uuid] = len(page.Movies)
+		page.Movies = append(page.Movies, PersonMovie{
+			Title:     credit.Title,
+			FileTitle: vaultNames.FileTitle(credit.ImdbID, credit.Title),
+			ImdbId:    credit.ImdbID,
+			Year:      int(credit.Year),
+			Roles:     []string{role},
+			Watched:   watchDates[credit.Uuid],
+		})
+	}
+	for ii := range page.Movies {
+		roles := page.Movies[ii].Roles
+		sort.SliceStable(roles, func(i, j int) bool {
+			return ROLE_ORDER[roles[i]] < ROLE_ORDER[roles[j]]
+		})
+	}
+	sort.SliceStable(page.Movies, func(i, j int) bool {
+		if page.Movies[i].Year != page.Movies[j].Year {
+			return page.Movies[i].Year < page.Movies[j].Year
+		}
+		return page.Movies[i].Title < page.Movies[j].Title
+	})
+	return &page
+}
+
+// ReadPreservedNotes returns whatever is under the notes heading of an
+// existing generated page, or an empty string if there's no page yet.
+func ReadPreservedNotes(filePath string) (string, error) {
+	pageText, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("error reading file %v: %v", filePath, err)
+	}
+	notesMatch := preservedNotesExtractor.FindSubmatch(pageText)
+	if len(notesMatch) != 2 {
+		return "", nil
+	}
+	return string(notesMatch[1]), nil
+}
+
+// GetCreditedPeople returns the IDs of everyone credited on the movies, in
+// the order they're first seen.
+func GetCreditedPeople(
+	ctx context.Context, queries *database.Queries, movieUuids []string,
+) ([]int64, error) {
+	people := make([]int64, 0)
+	seen := make(map[int64]bool)
+	for ii := range movieUuids {
+		credits, err := queries.GetCreditsForMovie(ctx, movieUuids[ii])
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error getting credits for %v: %v", movieUuids[ii], err,
+			)
+		}
+		for jj := range credits {
+			if !seen[credits[jj].ID] {
+				seen[credits[jj].ID] = true
+				people = append(people, credits[jj].ID)
+			}
+		}
+	}
+	return people, nil
+}
+
+// WritePersonPage renders the page for a person into peopleDir, named for
+// their name in the people table. Watch dates are cached by movie uuid
+// across calls since the same movies come up for lots of people.
+func WritePersonPage(
+	ctx context.Context,
+	queries *database.Queries,
+	vaultNames VaultNames,
+	personTemplate *template.Template,
+	peopleDir string,
+	personID int64,
+	watchDates map[string][]string,
+) error {
+	person, err := queries.GetPerson(ctx, personID)
+	if err != nil {
+		return fmt.Errorf("error getting person %v: %v", personID, err)
+	}
+	name := person.Name
+	credits, err := queries.GetCreditsForPerson(ctx, personID)
+	if err != nil {
+		return fmt.Errorf("error getting credits for %v: %v", name, err)
+	}
+	for ii := range credits {
+		if _, ok := watchDates[credits[ii].Uuid]; ok {
+			continue
+		}
+		watched, err := queries.GetWatchDatesForMovie(ctx, credits[ii].Uuid)
+		if err != nil {
+			return fmt.Errorf(
+				"error getting watch dates for %v: %v", credits[ii].Uuid, err,
+			)
+		}
+		watchDates[credits[ii].Uuid] = watched
+	}
+
+	page := CreatePersonPage(name, credits, watchDates, vaultNames)
+
+	filePath := path.Join(peopleDir, fmt.Sprintf("%v.md", cleanTitle(name)))
+	notes, err := ReadPreservedNotes(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading notes for %v: %v", name, err)
+	}
+	page.Notes = notes
+
+	rendered, err := renderPage(personTemplate, page)
+	if err != nil {
+		return fmt.Errorf("error rendering person page %v: %v", filePath, err)
+	}
+	return WriteFileAtomically(filePath, rendered)
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestCreatePersonPage(t *testing.T) {
+	credits := []database.GetCreditsForPersonRow{
+		{
+			Uuid: "b", Title: "Tenebrae", ImdbID: "tt0084777", Year: 1982,
+			Role: WRITER_CREDIT_ROLE,
+		},
+		{
+			Uuid: "b", Title: "Tenebrae", ImdbID: "tt0084777", Year: 1982,
+			Role: DIRECTOR_CREDIT_ROLE,
+		},
+		{
+			Uuid: "a", Title: "Suspiria", ImdbID: "tt0076786", Year: 1977,
+			Role: DIRECTOR_CREDIT_ROLE,
+		},
+	}
+	watchDates := map[string][]string{
+		"b": {"2022-05-27", "2022-10-31"},
+	}
+
+	answer := CreatePersonPage("Dario Argento", credits, watchDates, nil)
+	truth := &PersonPage{
+		Name: "Dario Argento",
+		Movies: []PersonMovie{
+			{
+				Title:     "Suspiria",
+				FileTitle: "Suspiria",
+				ImdbId:    "tt0076786",
+				Year:      1977,
+				Roles:     []string{"Director"},
+			}, {
+				Title:     "Tenebrae",
+				FileTitle: "Tenebrae",
+				ImdbId:    "tt0084777",
+				Year:      1982,
+				Roles:     []string{"Director", "Writer"},
+				Watched:   []string{"2022-05-27", "2022-10-31"},
+			},
+		},
+	}
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected \n%v, got \n%v", truth, answer)
+	}
+	if answer.WatchCount() != 2 {
+		t.Errorf("Expected 2 watches, got %v", answer.WatchCount())
+	}
+}
+
+func TestWritePersonPage(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	movieDetails, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if err := queries.InsertMovieWatch(
+		ctx,
+		*CreateInsertMovieWatchParams(sampleMovieWatchPage(), movieDetails.Movie),
+	); err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+
+	// Credited under another name, and merged into Dario Argento.
+	suspiria := sampleMoviePage()
+	suspiria.Title = "Suspiria"
+	suspiria.ImdbLink = "https://www.imdb.com/title/tt0076786/"
+	suspiria.Year = 1977
+	suspiria.Directors = []string{"D. Argento"}
+	suspiria.Writers = nil
+	suspiria.Actors = nil
+	suspiriaDetails, err := InsertMovieDetails(
+		db, ctx, queries, suspiria, nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	personID, err := queries.FindPersonByName(ctx, "Dario Argento")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	otherID, err := queries.FindPersonByName(ctx, "D. Argento")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := MergePeople(ctx, queries, otherID, personID); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	// The movie page links to the page the merged person has now.
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	moviePage, err := GetMoviePage(
+		ctx, queries, taxonomy, suspiriaDetails.Movie,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if !cmp.Equal([]string{"Dario Argento"}, moviePage.Directors) {
+		t.Errorf("Expected Dario Argento, got %v", moviePage.Directors)
+	}
+
+	peopleDir, err := os.MkdirTemp(".", "test_people")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(peopleDir)
+
+	vaultNames, err := LoadVaultNames(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	personTemplate := template.Must(template.New("person").Parse(PERSON_TEMPLATE))
+	watchDates := make(map[string][]string)
+	if err := WritePersonPage(
+		ctx, queries, vaultNames, personTemplate, peopleDir, personID,
+		watchDates,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	filePath := path.Join(peopleDir, "Dario Argento.md")
+	pageText, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Error reading page: %v", err)
+	}
+	for _, movieLine := range []string{
+		"- [[Suspiria (tt0076786)]] (1977): Director, watched 0 times",
+		"- [[Tenebrae (tt0084777)]] (1982): Director, Writer, watched 1 time [[2022-05-27]]",
+	} {
+		if !strings.Contains(string(pageText), movieLine) {
+			t.Errorf("Expected page to contain %v, got \n%v", movieLine, string(pageText))
+		}
+	}
+	people, err := GetCreditedPeople(
+		ctx, queries, []string{movieDetails.Movie},
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(people) == 0 || people[0] != personID {
+		t.Errorf("Expected %v first, got %v", personID, people)
+	}
+
+	// Add some notes and make sure they survive a rebuild.
+	notes := "Master of the giallo.\n"
+	if err := os.WriteFile(
+		filePath, append(pageText, []byte(notes)...), 0666,
+	); err != nil {
+		t.Fatalf("Error writing notes: %v", err)
+	}
+	if err := WritePersonPage(
+		ctx, queries, vaultNames, personTemplate, peopleDir, personID,
+		watchDates,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	answer, err := ReadPreservedNotes(filePath)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if !cmp.Equal(notes, answer) {
+		t.Errorf("Expected %v, got %v", notes, answer)
+	}
+}
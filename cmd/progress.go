@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const PROGRESS_BAR_WIDTH = 30
+
+// ProgressBar shows how far along a long-running command is, like
+//
+//	Writing pages [###############...............]  50/100
+//
+// It only draws on a terminal so it stays out of logs and pipes. A nil
+// ProgressBar does nothing, for callers that don't want one.
+type ProgressBar struct {
+	out   io.Writer
+	label string
+	total int
+	mu    sync.Mutex
+	done  int
+}
+
+// NewProgressBar makes a progress bar for total steps drawn on out, if out is
+// a terminal.
+func NewProgressBar(out *os.File, label string, total int) *ProgressBar {
+	bar := ProgressBar{label: label, total: total}
+	if stat, err := out.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+		bar.out = out
+	}
+	return &bar
+}
+
+// Add marks n more steps as done. It's safe to call from many goroutines.
+func (p *ProgressBar) Add(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	if p.out != nil {
+		fmt.Fprintf(p.out, "\r%v", p.render())
+	}
+}
+
+// Finish ends the line the bar is drawn on.
+func (p *ProgressBar) Finish() {
+	if p == nil || p.out == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintln(p.out)
+}
+
+func (p *ProgressBar) render() string {
+	filled := PROGRESS_BAR_WIDTH
+	if p.total > 0 && p.done < p.total {
+		filled = PROGRESS_BAR_WIDTH * p.done / p.total
+	}
+	width := len(fmt.Sprint(p.total))
+	return fmt.Sprintf(
+		"%v [%v%v] %*d/%d",
+		p.label,
+		strings.Repeat("#", filled),
+		strings.Repeat(".", PROGRESS_BAR_WIDTH-filled),
+		width, p.done, p.total,
+	)
+}
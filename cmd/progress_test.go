@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	bar := &ProgressBar{out: &out, label: "Writing pages", total: 12}
+	bar.Add(3)
+	bar.Add(9)
+	bar.Finish()
+
+	truth := "\rWriting pages [" + strings.Repeat("#", 7) +
+		strings.Repeat(".", 23) + "]  3/12" +
+		"\rWriting pages [" + strings.Repeat("#", 30) + "] 12/12\n"
+	if out.String() != truth {
+		t.Errorf("Expected %q, got %q", truth, out.String())
+	}
+
+	// Nil bars are for callers that don't want one.
+	var nilBar *ProgressBar
+	nilBar.Add(1)
+	nilBar.Finish()
+}
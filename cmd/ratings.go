@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+const MIN_STAR_RATING = 0.5
+const MAX_STAR_RATING = 5.0
+
+// ParseStarRating parses a rating:: field, 0.5 to 5 in half steps. An empty
+// field is unrated, which comes back as 0.
+func ParseStarRating(text string) (float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	rating, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a rating like 3.5, got %v", text)
+	}
+	if err := ValidateStarRating(rating); err != nil {
+		return 0, err
+	}
+	return rating, nil
+}
+
+// ValidateStarRating checks the rating is 0 (unrated) or 0.5 to 5 in half
+// steps.
+func ValidateStarRating(rating float64) error {
+	if rating == 0 {
+		return nil
+	}
+	if rating < MIN_STAR_RATING || rating > MAX_STAR_RATING ||
+		rating*2 != math.Trunc(rating*2) {
+		return fmt.Errorf(
+			"rating must be %v to %v in half steps, got %v",
+			MIN_STAR_RATING, MAX_STAR_RATING, rating,
+		)
+	}
+	return nil
+}
+
+// RoundStarRating rounds an average to the nearest half star.
+func RoundStarRating(rating float64) float64 {
+	return math.Round(rating*2) / 2
+}
+
+func starRatingToNull(rating float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: rating, Valid: rating != 0}
+}
+
+// AggregateReviewRating is the rating to store on a review: the one given,
+// or if there isn't one, the average of the movie's watch ratings.
+func AggregateReviewRating(
+	ctx context.Context,
+	queries *database.Queries,
+	movieUuid string,
+	rating float64,
+) (float64, error) {
+	if rating != 0 {
+		return rating, nil
+	}
+	watchRatings, err := queries.GetAverageWatchRatingForMovie(ctx, movieUuid)
+	if err != nil {
+		return 0, fmt.Errorf(
+			"error getting watch ratings for %v: %v", movieUuid, err,
+		)
+	}
+	if watchRatings.NumRatings == 0 {
+		return 0, nil
+	}
+	return RoundStarRating(watchRatings.AverageRating), nil
+}
+
+var ratingStatsCmd = &cobra.Command{
+	Use:   "rating-stats",
+	Short: "Prints average ratings by year, genre and director.",
+	Long: `Prints average ratings by year, genre and director, and how my
+ratings compare with IMDB's.
+
+IMDB ratings are halved to put them on the same five star scale.`,
+	Run:  ratingStats,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(ratingStatsCmd)
+
+	ratingStatsCmd.Flags().IntP(
+		"min-ratings", "m", 3,
+		"The fewest ratings a genre or director needs to be included.",
+	)
+	ratingStatsCmd.Flags().IntP(
+		"top", "n", 10,
+		"The number of genres, directors and disagreements to list.",
+	)
+}
+
+type RatingAverage struct {
+	Name          string  `json:"name"`
+	AverageRating float64 `json:"average_rating"`
+	NumRatings    int64   `json:"num_ratings"`
+}
+
+type RatingComparison struct {
+	Title      string  `json:"title"`
+	ImdbId     string  `json:"imdb_id"`
+	MyRating   float64 `json:"my_rating"`
+	ImdbRating float64 `json:"imdb_rating"`
+	// Mine minus IMDB's, in stars.
+	Difference float64 `json:"difference"`
+}
+
+type RatingStats struct {
+	ByYear     []RatingAverage `json:"by_year"`
+	ByGenre    []RatingAverage `json:"by_genre"`
+	ByDirector []RatingAverage `json:"by_director"`
+	// How much higher I rate things than IMDB does on average, in stars.
+	ImdbDifference float64 `json:"imdb_difference"`
+	NumCompared    int     `json:"num_compared"`
+	// The movies I like most and least relative to IMDB.
+	OverImdb  []RatingComparison `json:"over_imdb"`
+	UnderImdb []RatingComparison `json:"under_imdb"`
+}
+
+// CreateRatingComparisons puts IMDB's ratings out of ten on the five star
+// scale and sorts by the difference, biggest first.
+func CreateRatingComparisons(
+	rows []database.GetImdbRatingComparisonsRow,
+) []RatingComparison {
+	comparisons := make([]RatingComparison, 0, len(rows))
+	for ii := range rows {
+		imdbRating, err := ParseRatingValue(rows[ii].ImdbRating)
+		if err != nil {
+			log.Printf(
+				"Unable to parse IMDB rating %v for %v, skipping.",
+				rows[ii].ImdbRating, rows[ii].Title,
+			)
+			continue
+		}
+		comparison := RatingComparison{
+			Title:      rows[ii].Title,
+			ImdbId:     rows[ii].ImdbID,
+			MyRating:   rows[ii].MyRating,
+			ImdbRating: imdbRating / 2,
+		}
+		comparison.Difference = comparison.MyRating - comparison.ImdbRating
+		comparisons = append(comparisons, comparison)
+	}
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		return comparisons[i].Difference > comparisons[j].Difference
+	})
+	return comparisons
+}
+
+func GetRatingStats(
+	ctx context.Context, queries *database.Queries, minRatings int, top int,
+) (*RatingStats, error) {
+	stats := RatingStats{}
+
+	byYear, err := queries.GetAverageRatingsByYear(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting ratings by year: %v", err)
+	}
+	stats.ByYear = make([]RatingAverage, len(byYear))
+	for ii := range byYear {
+		stats.ByYear[ii] = RatingAverage{
+			Name:          byYear[ii].Year,
+			AverageRating: byYear[ii].AverageRating,
+			NumRatings:    byYear[ii].NumRatings,
+		}
+	}
+
+	byGenre, err := queries.GetAverageRatingsByGenre(ctx, int64(minRatings))
+	if err != nil {
+		return nil, fmt.Errorf("error getting ratings by genre: %v", err)
+	}
+	stats.ByGenre = make([]RatingAverage, 0, len(byGenre))
+	for ii := 0; ii < len(byGenre) && ii < top; ii++ {
+		stats.ByGenre = append(stats.ByGenre, RatingAverage{
+			Name:          byGenre[ii].Name,
+			AverageRating: byGenre[ii].AverageRating,
+			NumRatings:    byGenre[ii].NumRatings,
+		})
+	}
+
+	byDirector, err := queries.GetAverageRatingsByDirector(
+		ctx, int64(minRatings),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting ratings by director: %v", err)
+	}
+	stats.ByDirector = make([]RatingAverage, 0, len(byDirector))
+	for ii := 0; ii < len(byDirector) && ii < top; ii++ {
+		stats.ByDirector = append(stats.ByDirector, RatingAverage{
+			Name:          byDirector[ii].Name,
+			AverageRating: byDirector[ii].AverageRating,
+			NumRatings:    byDirector[ii].NumRatings,
+		})
+	}
+
+	comparisonRows, err := queries.GetImdbRatingComparisons(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting IMDB comparisons: %v", err)
+	}
+	comparisons := CreateRatingComparisons(comparisonRows)
+	stats.NumCompared = len(comparisons)
+	stats.OverImdb = make([]RatingComparison, 0)
+	stats.UnderImdb = make([]RatingComparison, 0)
+	for ii := range comparisons {
+		stats.ImdbDifference += comparisons[ii].Difference
+		if ii < top && comparisons[ii].Difference > 0 {
+			stats.OverImdb = append(stats.OverImdb, comparisons[ii])
+		}
+		under := comparisons[len(comparisons)-1-ii]
+		if ii < top && under.Difference < 0 {
+			stats.UnderImdb = append(stats.UnderImdb, under)
+		}
+	}
+	if stats.NumCompared > 0 {
+		stats.ImdbDifference /= float64(stats.NumCompared)
+	}
+	return &stats, nil
+}
+
+func printRatingAverages(heading string, averages []RatingAverage) {
+	fmt.Println(heading)
+	for ii := range averages {
+		fmt.Printf(
+			"  %v\t%.2f\t(%v ratings)\n",
+			averages[ii].Name, averages[ii].AverageRating, averages[ii].NumRatings,
+		)
+	}
+}
+
+func printRatingComparisons(heading string, comparisons []RatingComparison) {
+	fmt.Println(heading)
+	for ii := range comparisons {
+		fmt.Printf(
+			"  %v (%v)\tme %.1f\tIMDB %.2f\n",
+			comparisons[ii].Title, comparisons[ii].ImdbId,
+			comparisons[ii].MyRating, comparisons[ii].ImdbRating,
+		)
+	}
+}
+
+func ratingStats(cmd *cobra.Command, args []string) {
+	minRatings, err := cmd.Flags().GetInt("min-ratings")
+	if err != nil {
+		log.Panicf("Error obtaining min-ratings: %v", err)
+	}
+	top, err := cmd.Flags().GetInt("top")
+	if err != nil {
+		log.Panicf("Error obtaining top: %v", err)
+	}
+	if top < 1 {
+		log.Panicf("top must be > 0, got %v", top)
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	stats, err := GetRatingStats(ctx, queries, minRatings, top)
+	if err != nil {
+		log.Panicf("Error getting rating stats: %v", err)
+	}
+	printRatingAverages("By year:", stats.ByYear)
+	printRatingAverages("By genre:", stats.ByGenre)
+	printRatingAverages("By director:", stats.ByDirector)
+	fmt.Printf(
+		"Compared with IMDB: %+.2f stars on average over %v movies\n",
+		stats.ImdbDifference, stats.NumCompared,
+	)
+	printRatingComparisons("Rated over IMDB:", stats.OverImdb)
+	printRatingComparisons("Rated under IMDB:", stats.UnderImdb)
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestParseStarRating(t *testing.T) {
+	tests := []struct {
+		text  string
+		truth float64
+	}{
+		{"", 0},
+		{"0.5", 0.5},
+		{" 3.5 ", 3.5},
+		{"4", 4},
+		{"5.0", 5},
+	}
+	for _, test := range tests {
+		answer, err := ParseStarRating(test.text)
+		if err != nil {
+			t.Errorf("Error parsing %v: %v", test.text, err)
+		} else if answer != test.truth {
+			t.Errorf("Expected %v, got %v", test.truth, answer)
+		}
+	}
+	for _, text := range []string{"3.7", "5.5", "-1", "four"} {
+		if _, err := ParseStarRating(text); err == nil {
+			t.Errorf("Expected an error parsing %v", text)
+		}
+	}
+}
+
+func TestRoundStarRating(t *testing.T) {
+	tests := []struct {
+		average float64
+		truth   float64
+	}{
+		{3.25, 3.5},
+		{3.2, 3},
+		{4.75, 5},
+		{0.5, 0.5},
+	}
+	for _, test := range tests {
+		if answer := RoundStarRating(test.average); answer != test.truth {
+			t.Errorf(
+				"Expected %v for %v, got %v", test.truth, test.average, answer,
+			)
+		}
+	}
+}
+
+func TestWatchPageRating(t *testing.T) {
+	movieWatchTemplate, err := template.New("movie_watch").Parse(
+		MOVIE_WATCH_TEMPLATE,
+	)
+	if err != nil {
+		t.Fatalf("Error parsing template: %v", err)
+	}
+	parser, err := CreateMovieWatchParser()
+	if err != nil {
+		t.Fatalf("Error creating parser: %v", err)
+	}
+	fileName := path.Join(t.TempDir(), "watch.md")
+	for _, rating := range []float64{0, 3.5, 4} {
+		page := sampleMovieWatchPage()
+		page.Rating = rating
+		var body bytes.Buffer
+		if err := movieWatchTemplate.Execute(&body, page); err != nil {
+			t.Fatalf("Error executing template: %v", err)
+		}
+		if err := os.WriteFile(fileName, body.Bytes(), 0644); err != nil {
+			t.Fatalf("Error writing page: %v", err)
+		}
+		answer, err := parser.ParsePage(fileName)
+		if err != nil {
+			t.Fatalf("Error parsing page: %v", err)
+		}
+		if answer.Rating != rating {
+			t.Errorf("Expected %v, got %v", rating, answer.Rating)
+		}
+	}
+}
+
+func TestCreateRatingComparisons(t *testing.T) {
+	rows := []database.GetImdbRatingComparisonsRow{
+		{Title: "Tenebrae", ImdbID: "tt0084777", MyRating: 4.5, ImdbRating: "7.0/10"},
+		{Title: "Things", ImdbID: "tt0098463", MyRating: 5, ImdbRating: "2.2/10"},
+		{Title: "Suspiria", ImdbID: "tt0076786", MyRating: 3, ImdbRating: "7.0/10"},
+		{Title: "Broken", ImdbID: "tt0000000", MyRating: 3, ImdbRating: "N/A"},
+	}
+	truth := []RatingComparison{
+		{
+			Title: "Things", ImdbId: "tt0098463", MyRating: 5, ImdbRating: 1.1,
+			Difference: 3.9,
+		},
+		{
+			Title: "Tenebrae", ImdbId: "tt0084777", MyRating: 4.5, ImdbRating: 3.5,
+			Difference: 1,
+		},
+		{
+			Title: "Suspiria", ImdbId: "tt0076786", MyRating: 3, ImdbRating: 3.5,
+			Difference: -0.5,
+		},
+	}
+	answer := CreateRatingComparisons(rows)
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected %v, got %v", truth, answer)
+	}
+}
+
+func TestRatingStats(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	// Both credits are the same person, so they're averaged together.
+	moviePage := sampleMoviePage()
+	moviePage.Directors = []string{"Dario Argento", "Dario Argento (uncredited)"}
+	movieDetails, err := InsertMovieDetails(
+		db, ctx, queries, moviePage,
+		[]Rating{{Source: IMDB_RATING_SOURCE, Value: "7.0/10"}},
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	for _, watch := range []struct {
+		watched string
+		rating  float64
+	}{
+		{"2021-05-27", 3},
+		{"2022-05-27", 4},
+		{"2022-10-31", 4.5},
+		{"2022-12-31", 0},
+	} {
+		movieWatch := sampleMovieWatchPage()
+		movieWatch.Watched = watch.watched
+		movieWatch.Rating = watch.rating
+		if err := queries.InsertMovieWatch(
+			ctx, *CreateInsertMovieWatchParams(movieWatch, movieDetails.Movie),
+		); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+
+	// No rating on the review means it's the average of the watches.
+	rating, err := AggregateReviewRating(ctx, queries, movieDetails.Movie, 0)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if rating != 4 {
+		t.Errorf("Expected 4, got %v", rating)
+	}
+
+	stats, err := GetRatingStats(ctx, queries, 3, 10)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	byYearTruth := []RatingAverage{
+		{Name: "2021", AverageRating: 3, NumRatings: 1},
+		{Name: "2022", AverageRating: 4.25, NumRatings: 2},
+	}
+	if !cmp.Equal(byYearTruth, stats.ByYear) {
+		t.Errorf("Expected %v, got %v", byYearTruth, stats.ByYear)
+	}
+	byDirectorTruth := []RatingAverage{
+		{Name: "Dario Argento", AverageRating: 23.0 / 6, NumRatings: 3},
+	}
+	if !cmp.Equal(byDirectorTruth, stats.ByDirector) {
+		t.Errorf("Expected %v, got %v", byDirectorTruth, stats.ByDirector)
+	}
+	if len(stats.ByGenre) != 3 {
+		t.Errorf("Expected 3 genres, got %v", stats.ByGenre)
+	}
+	if stats.NumCompared != 1 || len(stats.OverImdb) != 1 ||
+		stats.OverImdb[0].MyRating != 23.0/6 {
+		t.Errorf("Unexpected comparison %v", stats)
+	}
+
+	// A rating on the review wins over the watches.
+	reviewParams := CreateInsertMovieReviewParams(
+		&MovieReviewPage{MovieTitle: "Tenebrae", Review: "Sharp.", Rating: 2.5},
+		movieDetails.Movie, "",
+	)
+	if err := queries.InsertReview(ctx, *reviewParams); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	stats, err = GetRatingStats(ctx, queries, 3, 10)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(stats.UnderImdb) != 1 || stats.UnderImdb[0].MyRating != 2.5 ||
+		stats.ImdbDifference != -1 {
+		t.Errorf("Unexpected comparison %v", stats)
+	}
+}
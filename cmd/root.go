@@ -16,6 +16,7 @@ import (
 var GRIST_KEY string
 var GRIST_DOCUMENT_ID string
 var OMDB_KEY string
+var API_TOKEN string
 var DB string = "./data/movies.db"
 
 // rootCmd represents the base command when called without any subcommands
@@ -45,4 +46,10 @@ func init() {
 		log.Println("Could not find OMDB_KEY in environment or .env.")
 	}
 	OMDB_KEY = omdbKey
+
+	// Only needed for the write API, so serve complains if it's missing.
+	API_TOKEN = os.Getenv("API_TOKEN")
+
+	VAULT_UNSAFE_CHARACTERS = os.Getenv("VAULT_UNSAFE_CHARACTERS")
+	VAULT_TEMPLATES_DIR = os.Getenv("VAULT_TEMPLATES_DIR")
 }
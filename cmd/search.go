@@ -0,0 +1,347 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Searches movie plots, watch notes and reviews.",
+	Long: `Searches movie titles and plots, watch notes and reviews.
+
+Queries use SQLite FTS5 syntax: quote words for a phrase ("razor scene"),
+end a word with * for a prefix (zomb*), and use OR / NOT between terms.`,
+	Run:  search,
+	Args: cobra.ExactArgs(1),
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().IntP("year", "y", 0, "Only movies released in this year.")
+	searchCmd.Flags().StringP("genre", "g", "", "Only movies in this genre.")
+	searchCmd.Flags().StringP(
+		"person", "p", "", "Only movies this person directed, wrote or acted in.",
+	)
+	searchCmd.Flags().StringSliceP(
+		"flag", "f", nil,
+		fmt.Sprintf(
+			"Only movies with this flag set, one of %v.",
+			strings.Join(SEARCH_FLAGS, ", "),
+		),
+	)
+	searchCmd.Flags().IntP("limit", "n", 20, "The number of results to show.")
+	searchCmd.Flags().Bool("json", false, "Print the results as JSON.")
+}
+
+// Where a search result came from.
+const (
+	MOVIE_RESULT  = "movie"
+	WATCH_RESULT  = "watch"
+	REVIEW_RESULT = "review"
+)
+
+var SEARCH_FLAGS = []string{
+	"call_felissa", "slasher", "zombies", "beast", "godzilla", "wallpaper_fu",
+}
+
+type SearchResult struct {
+	Kind      string  `json:"kind"`
+	Uuid      string  `json:"uuid"`
+	MovieUuid string  `json:"movie_uuid"`
+	Title     string  `json:"title"`
+	ImdbId    string  `json:"imdb_id"`
+	Year      int64   `json:"year"`
+	Watched   string  `json:"watched,omitempty"`
+	Snippet   string  `json:"snippet"`
+	Score     float64 `json:"score"`
+	flags     map[string]bool
+}
+
+type SearchFilter struct {
+	Year int64
+	// Movies that are allowed through, nil if any movie is.
+	MovieUuids map[string]bool
+	Flags      []string
+}
+
+func (f *SearchFilter) Keep(result *SearchResult) bool {
+	if f.Year != 0 && result.Year != f.Year {
+		return false
+	}
+	if f.MovieUuids != nil && !f.MovieUuids[result.MovieUuid] {
+		return false
+	}
+	for ii := range f.Flags {
+		if !result.flags[f.Flags[ii]] {
+			return false
+		}
+	}
+	return true
+}
+
+// restrictMovies narrows the movies the filter lets through to those that
+// are also in movieUuids.
+func (f *SearchFilter) restrictMovies(movieUuids []string) {
+	allowed := make(map[string]bool)
+	for ii := range movieUuids {
+		if f.MovieUuids == nil || f.MovieUuids[movieUuids[ii]] {
+			allowed[movieUuids[ii]] = true
+		}
+	}
+	f.MovieUuids = allowed
+}
+
+func CreateSearchFilter(
+	ctx context.Context,
+	queries *database.Queries,
+	taxonomy *GenreTaxonomy,
+	year int,
+	genre string,
+	person string,
+	flags []string,
+) (*SearchFilter, error) {
+	filter := SearchFilter{Year: int64(year)}
+	for ii := range flags {
+		flag := strings.ToLower(strings.TrimSpace(flags[ii]))
+		valid := false
+		for jj := range SEARCH_FLAGS {
+			valid = valid || flag == SEARCH_FLAGS[jj]
+		}
+		if !valid {
+			return nil, fmt.Errorf(
+				"unknown flag %v, expected one of %v",
+				flags[ii], strings.Join(SEARCH_FLAGS, ", "),
+			)
+		}
+		filter.Flags = append(filter.Flags, flag)
+	}
+
+	if genre != "" {
+		name := taxonomy.Canonical(genre)
+		movies, err := queries.GetMoviesForGenre(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting movies for %v: %v", name, err)
+		}
+		movieUuids := make([]string, len(movies))
+		for ii := range movies {
+			movieUuids[ii] = movies[ii].Uuid
+		}
+		filter.restrictMovies(movieUuids)
+	}
+
+	if person != "" {
+		personID, err := FindPersonID(ctx, queries, person)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("no person named %v", person)
+		} else if err != nil {
+			return nil, fmt.Errorf("error finding person %v: %v", person, err)
+		}
+		movieUuids, err := queries.GetMovieUuidsForPerson(ctx, personID)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error getting movies for %v: %v", person, err,
+			)
+		}
+		filter.restrictMovies(movieUuids)
+	}
+	return &filter, nil
+}
+
+func searchFlags(
+	callFelissa, slasher, zombies, beast, godzilla, wallpaperFu int64,
+) map[string]bool {
+	return map[string]bool{
+		"call_felissa": callFelissa != 0,
+		"slasher":      slasher != 0,
+		"zombies":      zombies != 0,
+		"beast":        beast != 0,
+		"godzilla":     godzilla != 0,
+		"wallpaper_fu": wallpaperFu != 0,
+	}
+}
+
+// Search runs the query against movies, watch notes and reviews and returns
+// the results the filter keeps, best first. Scores are negated bm25 ranks, so
+// higher is better; titles count double in the movie ranks.
+func Search(
+	ctx context.Context,
+	queries *database.Queries,
+	query string,
+	filter *SearchFilter,
+) ([]SearchResult, error) {
+	results := make([]SearchResult, 0)
+
+	movies, err := queries.SearchMovieText(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error searching movies: %v", err)
+	}
+	for ii := range movies {
+		results = append(results, SearchResult{
+			Kind:      MOVIE_RESULT,
+			Uuid:      movies[ii].Uuid,
+			MovieUuid: movies[ii].Uuid,
+			Title:     movies[ii].Title,
+			ImdbId:    movies[ii].ImdbID,
+			Year:      movies[ii].Year,
+			Snippet:   movies[ii].Snippet,
+			Score:     -movies[ii].Rank,
+			flags: searchFlags(
+				movies[ii].CallFelissa, movies[ii].Slasher, movies[ii].Zombies,
+				movies[ii].Beast, movies[ii].Godzilla, movies[ii].WallpaperFu,
+			),
+		})
+	}
+
+	watches, err := queries.SearchWatchNotes(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error searching watch notes: %v", err)
+	}
+	for ii := range watches {
+		results = append(results, SearchResult{
+			Kind:      WATCH_RESULT,
+			Uuid:      watches[ii].Uuid,
+			MovieUuid: watches[ii].MovieUuid,
+			Title:     watches[ii].Title,
+			ImdbId:    watches[ii].ImdbID,
+			Year:      watches[ii].Year,
+			Watched:   watches[ii].Watched,
+			Snippet:   watches[ii].Snippet,
+			Score:     -watches[ii].Rank,
+			flags: searchFlags(
+				watches[ii].CallFelissa, watches[ii].Slasher,
+				watches[ii].Zombies, watches[ii].Beast, watches[ii].Godzilla,
+				watches[ii].WallpaperFu,
+			),
+		})
+	}
+
+	reviews, err := queries.SearchReviewText(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error searching reviews: %v", err)
+	}
+	for ii := range reviews {
+		results = append(results, SearchResult{
+			Kind:      REVIEW_RESULT,
+			Uuid:      reviews[ii].Uuid,
+			MovieUuid: reviews[ii].MovieUuid,
+			Title:     reviews[ii].Title,
+			ImdbId:    reviews[ii].ImdbID,
+			Year:      reviews[ii].Year,
+			Snippet:   reviews[ii].Snippet,
+			Score:     -reviews[ii].Rank,
+			flags: searchFlags(
+				reviews[ii].CallFelissa, reviews[ii].Slasher,
+				reviews[ii].Zombies, reviews[ii].Beast, reviews[ii].Godzilla,
+				reviews[ii].WallpaperFu,
+			),
+		})
+	}
+
+	kept := make([]SearchResult, 0, len(results))
+	for ii := range results {
+		if filter == nil || filter.Keep(&results[ii]) {
+			kept = append(kept, results[ii])
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].Score != kept[j].Score {
+			return kept[i].Score > kept[j].Score
+		}
+		if kept[i].Title != kept[j].Title {
+			return kept[i].Title < kept[j].Title
+		}
+		return kept[i].Watched < kept[j].Watched
+	})
+	return kept, nil
+}
+
+func search(cmd *cobra.Command, args []string) {
+	query := args[0]
+
+	year, err := cmd.Flags().GetInt("year")
+	if err != nil {
+		log.Panicf("Error obtaining year: %v", err)
+	}
+	genre, err := cmd.Flags().GetString("genre")
+	if err != nil {
+		log.Panicf("Error obtaining genre: %v", err)
+	}
+	person, err := cmd.Flags().GetString("person")
+	if err != nil {
+		log.Panicf("Error obtaining person: %v", err)
+	}
+	flags, err := cmd.Flags().GetStringSlice("flag")
+	if err != nil {
+		log.Panicf("Error obtaining flags: %v", err)
+	}
+	limit, err := cmd.Flags().GetInt("limit")
+	if err != nil {
+		log.Panicf("Error obtaining limit: %v", err)
+	}
+	asJson, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		log.Panicf("Error obtaining json: %v", err)
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		log.Panicf("Error loading genre taxonomy: %v", err)
+	}
+
+	filter, err := CreateSearchFilter(
+		ctx, queries, taxonomy, year, genre, person, flags,
+	)
+	if err != nil {
+		log.Panicf("Error creating search filter: %v", err)
+	}
+	results, err := Search(ctx, queries, query, filter)
+	if err != nil {
+		log.Panicf("Error searching for %v: %v", query, err)
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	if asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			log.Panicf("Error writing results: %v", err)
+		}
+		return
+	}
+	for ii := range results {
+		header := fmt.Sprintf(
+			"[%v] %v (%v) %v", results[ii].Kind, results[ii].Title,
+			results[ii].Year, results[ii].ImdbId,
+		)
+		if results[ii].Watched != "" {
+			header = fmt.Sprintf("%v watched %v", header, results[ii].Watched)
+		}
+		fmt.Println(header)
+		snippet := strings.Join(strings.Fields(results[ii].Snippet), " ")
+		fmt.Printf("    %v\n", snippet)
+	}
+}
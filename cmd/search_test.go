@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestSearch(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	movieDetails, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	movieWatch := sampleMovieWatchPage()
+	movieWatch.Notes = "The razor scene in the garden is still razor sharp."
+	watchParams := CreateInsertMovieWatchParams(movieWatch, movieDetails.Movie)
+	if err := queries.InsertMovieWatch(ctx, *watchParams); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	reviewParams := CreateInsertMovieReviewParams(
+		&MovieReviewPage{
+			MovieTitle: "Tenebrae",
+			Review:     "Argento's sleekest giallo, all razors and white walls.",
+		},
+		movieDetails.Movie, "",
+	)
+	if err := queries.InsertReview(ctx, *reviewParams); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	// Prefix query hitting the notes twice and the review once.
+	results, err := Search(ctx, queries, "razor*", nil)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	kinds := make([]string, len(results))
+	for ii := range results {
+		kinds[ii] = results[ii].Kind
+	}
+	kindsTruth := []string{WATCH_RESULT, REVIEW_RESULT}
+	if !cmp.Equal(kindsTruth, kinds) {
+		t.Errorf("Expected %v, got %v", kindsTruth, kinds)
+	}
+	if results[0].Snippet != "The **razor** scene in the garden is still **razor** sharp." {
+		t.Errorf("Unexpected snippet %v", results[0].Snippet)
+	}
+	if results[0].Watched != "2022-05-27" {
+		t.Errorf("Expected 2022-05-27, got %v", results[0].Watched)
+	}
+
+	// Phrase query against the plot.
+	results, err = Search(ctx, queries, `"serial killer"`, nil)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(results) != 1 || results[0].Kind != MOVIE_RESULT {
+		t.Errorf("Expected one movie result, got %v", results)
+	}
+
+	// Filters.
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	filter, err := CreateSearchFilter(
+		ctx, queries, taxonomy, 1982, "Horror", "Dario Argento", []string{"slasher"},
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	results, err = Search(ctx, queries, "razor*", filter)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 results, got %v", results)
+	}
+	filter, err = CreateSearchFilter(
+		ctx, queries, taxonomy, 0, "", "", []string{"zombies"},
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	results, err = Search(ctx, queries, "razor*", filter)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %v", results)
+	}
+	if _, err := CreateSearchFilter(
+		ctx, queries, taxonomy, 0, "", "Nobody", nil,
+	); err == nil {
+		t.Errorf("Expected an error for an unknown person")
+	}
+	if _, err := CreateSearchFilter(
+		ctx, queries, taxonomy, 0, "", "", []string{"kaiju"},
+	); err == nil {
+		t.Errorf("Expected an error for an unknown flag")
+	}
+
+	// Updates and deletes keep the index in sync.
+	if err := queries.UpdateReview(ctx, database.UpdateReviewParams{
+		MovieTitle: reviewParams.MovieTitle,
+		Review:     "Black gloves and white walls.",
+		Uuid:       reviewParams.Uuid,
+	}); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := queries.DeleteMovieWatch(ctx, watchParams.Uuid); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	results, err = Search(ctx, queries, "razor*", nil)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %v", results)
+	}
+	results, err = Search(ctx, queries, "gloves", nil)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(results) != 1 || results[0].Kind != REVIEW_RESULT {
+		t.Errorf("Expected one review result, got %v", results)
+	}
+}
@@ -0,0 +1,960 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serves the movies database as a JSON API.",
+	Long: `Serves the movies database as a JSON API, read-only unless --write is
+given.
+
+Endpoints:
+  GET /movies             movies by title, paginated with limit and cursor
+  GET /movies/{imdb_id}   a movie with genres, cast and ratings
+  GET /movies/{imdb_id}/reviews
+                          a movie's reviews with their revisions
+  GET /watches            watches, newest first, filtered by from, to,
+                          service and flag, paginated with limit and cursor
+  GET /reviews            reviews by movie title
+  GET /people/{name}      a person with their aliases and credits
+  GET /stats              watch totals by year and service
+  GET /stats/ratings      average ratings and how they compare with IMDB
+  GET /watches/{uuid}     a single watch
+  GET /feed.xml           an Atom feed of the latest watches
+
+The same server has a browsable HTML version under /ui/: the diary by
+month, movie, person and genre pages, and search.
+
+With --write, these take a bearer token matching API_TOKEN and write the
+vault pages too:
+  POST /watches                   log a watch, pulling new movies from OMDB
+  PUT /watches/{uuid}             change a watch
+  DELETE /watches/{uuid}          delete a watch
+  PUT /movies/{imdb_id}/review    write a review for a movie, or for one
+                                  of its watches with watched`,
+	Run:  serve,
+	Args: cobra.NoArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringP(
+		"addr", "a", "localhost:8080", "The address to listen on.",
+	)
+	serveCmd.Flags().BoolP(
+		"write", "w", false, "Serve the write endpoints as well.",
+	)
+	serveCmd.Flags().StringP(
+		"vault", "v", "", "The vault to write pages into with --write.",
+	)
+}
+
+const (
+	DEFAULT_PAGE_SIZE = 50
+	MAX_PAGE_SIZE     = 500
+)
+
+// Watch flags the /watches endpoint filters on, on top of the movie flags.
+var WATCH_FLAGS = []string{"first_time", "joe_bob"}
+
+type ApiMovieSummary struct {
+	Uuid   string `json:"uuid"`
+	Title  string `json:"title"`
+	ImdbId string `json:"imdb_id"`
+	Year   int64  `json:"year"`
+}
+
+type ApiRating struct {
+	Source string `json:"source"`
+	Value  string `json:"value"`
+}
+
+type ApiMovie struct {
+	ApiMovieSummary
+	Rated          string          `json:"rated,omitempty"`
+	Released       string          `json:"released,omitempty"`
+	RuntimeMinutes int64           `json:"runtime_minutes,omitempty"`
+	Plot           string          `json:"plot,omitempty"`
+	Country        string          `json:"country,omitempty"`
+	Language       string          `json:"language,omitempty"`
+	BoxOffice      string          `json:"box_office,omitempty"`
+	Production     string          `json:"production,omitempty"`
+	Flags          map[string]bool `json:"flags"`
+	Genres         []string        `json:"genres"`
+	Directors      []string        `json:"directors"`
+	Writers        []string        `json:"writers"`
+	Actors         []string        `json:"actors"`
+	Ratings        []ApiRating     `json:"ratings"`
+	Poster         string          `json:"poster,omitempty"`
+}
+
+type ApiWatch struct {
+	Uuid      string  `json:"uuid"`
+	MovieUuid string  `json:"movie_uuid"`
+	Title     string  `json:"title"`
+	ImdbId    string  `json:"imdb_id"`
+	Year      int64   `json:"year"`
+	Watched   string  `json:"watched"`
+	WatchedAt string  `json:"watched_at,omitempty"`
+	Timezone  string  `json:"timezone,omitempty"`
+	Rating    float64 `json:"rating,omitempty"`
+	Service   string  `json:"service"`
+	FirstTime bool    `json:"first_time"`
+	JoeBob    bool    `json:"joe_bob"`
+	Notes     string  `json:"notes,omitempty"`
+}
+
+type ApiReview struct {
+	Uuid      string  `json:"uuid"`
+	MovieUuid string  `json:"movie_uuid"`
+	Title     string  `json:"title"`
+	ImdbId    string  `json:"imdb_id"`
+	Year      int64   `json:"year"`
+	Review    string  `json:"review"`
+	Liked     bool    `json:"liked"`
+	Rating    float64 `json:"rating,omitempty"`
+	// The watch the review is for, if it's for one.
+	Watched   string              `json:"watched,omitempty"`
+	Revisions []ApiReviewRevision `json:"revisions,omitempty"`
+}
+
+type ApiReviewRevision struct {
+	Review  string  `json:"review"`
+	Liked   bool    `json:"liked"`
+	Rating  float64 `json:"rating,omitempty"`
+	Created string  `json:"created"`
+}
+
+type ApiCredit struct {
+	ApiMovieSummary
+	Role         string `json:"role"`
+	BillingOrder int64  `json:"billing_order"`
+}
+
+type ApiPerson struct {
+	ID      int64       `json:"id"`
+	Name    string      `json:"name"`
+	ImdbId  string      `json:"imdb_id,omitempty"`
+	Aliases []string    `json:"aliases"`
+	Credits []ApiCredit `json:"credits"`
+}
+
+type ApiServiceCount struct {
+	Service    string `json:"service"`
+	NumWatches int64  `json:"num_watches"`
+}
+
+type ApiStats struct {
+	NumWatches       int64             `json:"num_watches"`
+	NumMovies        int64             `json:"num_movies"`
+	NumFirstTime     int64             `json:"num_first_time"`
+	NumJoeBob        int64             `json:"num_joe_bob"`
+	FirstWatched     string            `json:"first_watched"`
+	LastWatched      string            `json:"last_watched"`
+	WatchesByYear    map[string]int64  `json:"watches_by_year"`
+	WatchesByService []ApiServiceCount `json:"watches_by_service"`
+}
+
+type ApiPage struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+type ApiError struct {
+	Error string `json:"error"`
+}
+
+// Cursors are opaque to clients, but they're just the sort key of the last
+// item on the page.
+func EncodeCursor(parts ...string) string {
+	return base64.RawURLEncoding.EncodeToString(
+		[]byte(strings.Join(parts, "\x00")),
+	)
+}
+
+func DecodeCursor(cursor string, numParts int) ([]string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor %v: %v", cursor, err)
+	}
+	parts := strings.Split(string(decoded), "\x00")
+	if len(parts) != numParts {
+		return nil, fmt.Errorf("malformed cursor %v", cursor)
+	}
+	return parts, nil
+}
+
+type apiServer struct {
+	queries *database.Queries
+	// Only set when serving the write API.
+	writer *apiWriter
+}
+
+// NewApiHandler routes the read-only API endpoints to the queries.
+func NewApiHandler(queries *database.Queries) http.Handler {
+	return newApiMux(&apiServer{queries: queries})
+}
+
+func newApiMux(server *apiServer) *http.ServeMux {
+	writer := server.writer
+	mux := http.NewServeMux()
+	mux.HandleFunc("/movies", methods(map[string]http.HandlerFunc{
+		http.MethodGet: server.listMovies,
+	}))
+	mux.HandleFunc("/movies/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/reviews") {
+			methods(map[string]http.HandlerFunc{
+				http.MethodGet: server.getMovieReviews,
+			})(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/review") {
+			methods(map[string]http.HandlerFunc{
+				http.MethodPut: writer.authorized(writer.putReview),
+			})(w, r)
+			return
+		}
+		methods(map[string]http.HandlerFunc{
+			http.MethodGet: server.getMovie,
+		})(w, r)
+	})
+	mux.HandleFunc("/watches", methods(map[string]http.HandlerFunc{
+		http.MethodGet:  server.listWatches,
+		http.MethodPost: writer.authorized(writer.createWatch),
+	}))
+	mux.HandleFunc("/watches/", methods(map[string]http.HandlerFunc{
+		http.MethodGet:    server.getWatch,
+		http.MethodPut:    writer.authorized(writer.updateWatch),
+		http.MethodDelete: writer.authorized(writer.deleteWatch),
+	}))
+	mux.HandleFunc("/reviews", methods(map[string]http.HandlerFunc{
+		http.MethodGet: server.listReviews,
+	}))
+	mux.HandleFunc("/people/", methods(map[string]http.HandlerFunc{
+		http.MethodGet: server.getPerson,
+	}))
+	mux.HandleFunc("/stats/ratings", methods(map[string]http.HandlerFunc{
+		http.MethodGet: server.getRatingStats,
+	}))
+	mux.HandleFunc("/stats", methods(map[string]http.HandlerFunc{
+		http.MethodGet: server.getStats,
+	}))
+	mux.HandleFunc("/feed.xml", methods(map[string]http.HandlerFunc{
+		http.MethodGet: server.getFeed,
+	}))
+	mux.Handle("/ui/", newUiMux(server.queries))
+	return mux
+}
+
+// methods dispatches on the request method. Nil handlers are skipped, which
+// is how the write endpoints disappear when serving read-only.
+func methods(handlers map[string]http.HandlerFunc) http.HandlerFunc {
+	allowed := make([]string, 0, len(handlers)+1)
+	for method, handler := range handlers {
+		if handler != nil {
+			allowed = append(allowed, method)
+		}
+	}
+	if handlers[http.MethodGet] != nil {
+		allowed = append(allowed, http.MethodHead)
+	}
+	sort.Strings(allowed)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		method := r.Method
+		if method == http.MethodHead {
+			method = http.MethodGet
+		}
+		handler := handlers[method]
+		if handler == nil {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			writeApiError(
+				w, http.StatusMethodNotAllowed,
+				fmt.Sprintf("method %v not allowed", r.Method),
+			)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// writeJson writes the value with an ETag from a hash of the body.
+func writeJson(w http.ResponseWriter, r *http.Request, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Error encoding response for %v: %v", r.URL, err)
+		writeApiError(w, http.StatusInternalServerError, "error encoding response")
+		return
+	}
+	writeWithEtag(w, r, "application/json", body)
+}
+
+// writeWithEtag answers conditional requests that already have the body
+// with a 304.
+func writeWithEtag(
+	w http.ResponseWriter, r *http.Request, contentType string, body []byte,
+) {
+	hash := sha256.Sum256(body)
+	etag := fmt.Sprintf(`"%v"`, hex.EncodeToString(hash[:16]))
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(body)
+}
+
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func writeApiError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ApiError{Error: message})
+}
+
+func writeInternalError(
+	w http.ResponseWriter, r *http.Request, message string, err error,
+) {
+	log.Printf("Error serving %v: %v: %v", r.URL, message, err)
+	writeApiError(w, http.StatusInternalServerError, message)
+}
+
+// parseIntParam gets a non-negative integer query parameter, or the default
+// if it isn't there.
+func parseIntParam(r *http.Request, name string, defaultValue int) (int, error) {
+	param := r.URL.Query().Get(name)
+	if param == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(param)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%v must be a number >= 0, got %v", name, param)
+	}
+	return value, nil
+}
+
+func pageLimit(r *http.Request) (int64, error) {
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam == "" {
+		return DEFAULT_PAGE_SIZE, nil
+	}
+	limit, err := strconv.ParseInt(limitParam, 10, 64)
+	if err != nil || limit < 1 || limit > MAX_PAGE_SIZE {
+		return 0, fmt.Errorf(
+			"limit must be between 1 and %v, got %v", MAX_PAGE_SIZE, limitParam,
+		)
+	}
+	return limit, nil
+}
+
+func (s *apiServer) listMovies(w http.ResponseWriter, r *http.Request) {
+	limit, err := pageLimit(r)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := database.ListMoviesAfterParams{Limit: limit + 1}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		parts, err := DecodeCursor(cursor, 2)
+		if err != nil {
+			writeApiError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		params.Title, params.Title_2, params.Uuid = parts[0], parts[0], parts[1]
+	}
+
+	movies, err := s.queries.ListMoviesAfter(r.Context(), params)
+	if err != nil {
+		writeInternalError(w, r, "error listing movies", err)
+		return
+	}
+	page := ApiPage{}
+	if int64(len(movies)) > limit {
+		movies = movies[:limit]
+		last := movies[len(movies)-1]
+		page.NextCursor = EncodeCursor(last.Title, last.Uuid)
+	}
+	items := make([]ApiMovieSummary, len(movies))
+	for ii := range movies {
+		items[ii] = ApiMovieSummary{
+			Uuid:   movies[ii].Uuid,
+			Title:  movies[ii].Title,
+			ImdbId: movies[ii].ImdbID,
+			Year:   movies[ii].Year,
+		}
+	}
+	page.Items = items
+	writeJson(w, r, page)
+}
+
+func GetApiMovie(
+	ctx context.Context, queries *database.Queries, imdbId string,
+) (*ApiMovie, error) {
+	movieUuid, err := queries.FindMovie(ctx, imdbId)
+	if err != nil {
+		// Let sql.ErrNoRows through untouched so callers can 404.
+		return nil, err
+	}
+	movie, err := queries.GetMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting movie %v: %v", movieUuid, err)
+	}
+	genres, err := queries.GetGenreNamesForMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting genres: %v", err)
+	}
+	directors, err := queries.GetDirectorNamesForMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting directors: %v", err)
+	}
+	writers, err := queries.GetWriterNamesForMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting writers: %v", err)
+	}
+	actors, err := queries.GetActorNamesForMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting actors: %v", err)
+	}
+	ratings, err := queries.GetRatingsForMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting ratings: %v", err)
+	}
+
+	apiMovie := ApiMovie{
+		ApiMovieSummary: ApiMovieSummary{
+			Uuid:   movie.Uuid,
+			Title:  movie.Title,
+			ImdbId: movie.ImdbID,
+			Year:   movie.Year,
+		},
+		Rated:          movie.Rated.String,
+		Released:       movie.Released.String,
+		RuntimeMinutes: movie.RuntimeMinutes.Int64,
+		Plot:           movie.Plot.String,
+		Country:        movie.Country.String,
+		Language:       movie.Language.String,
+		BoxOffice:      movie.BoxOffice.String,
+		Production:     movie.Production.String,
+		Poster:         movie.Poster.String,
+		Flags: searchFlags(
+			movie.CallFelissa, movie.Slasher, movie.Zombies, movie.Beast,
+			movie.Godzilla, movie.WallpaperFu,
+		),
+		Genres:    nonNilStrings(genres),
+		Directors: nonNilStrings(directors),
+		Writers:   nonNilStrings(writers),
+		Actors:    nonNilStrings(actors),
+		Ratings:   make([]ApiRating, len(ratings)),
+	}
+	for ii := range ratings {
+		apiMovie.Ratings[ii] = ApiRating{
+			Source: ratings[ii].Source, Value: ratings[ii].Value,
+		}
+	}
+	return &apiMovie, nil
+}
+
+// sqlc hands back nil for no rows, which would be null rather than [] in the
+// JSON.
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func (s *apiServer) getMovie(w http.ResponseWriter, r *http.Request) {
+	imdbId := strings.TrimPrefix(r.URL.Path, "/movies/")
+	movie, err := GetApiMovie(r.Context(), s.queries, imdbId)
+	if err == sql.ErrNoRows {
+		writeApiError(
+			w, http.StatusNotFound, fmt.Sprintf("no movie with id %v", imdbId),
+		)
+		return
+	} else if err != nil {
+		writeInternalError(w, r, "error getting movie", err)
+		return
+	}
+	writeJson(w, r, movie)
+}
+
+// Escapes LIKE's wildcards, for patterns with ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// CreateListMovieWatchesParams turns the /watches query string into query
+// params. Filters that aren't set are widened to match everything.
+func CreateListMovieWatchesParams(
+	query map[string][]string, limit int64,
+) (*database.ListMovieWatchesBeforeParams, error) {
+	get := func(key string) string {
+		if values := query[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	params := database.ListMovieWatchesBeforeParams{
+		Watched:   get("from"),
+		Watched_2: get("to"),
+		Service:   get("service"),
+		Watched_3: "9999-12-31",
+		Watched_4: "9999-12-31",
+		Limit:     limit + 1,
+	}
+	if params.Watched_2 == "" {
+		params.Watched_2 = "9999-12-31"
+	}
+	// The service is matched exactly, apart from case, so wildcards in it
+	// are escaped.
+	if params.Service == "" {
+		params.Service = "%"
+	} else {
+		params.Service = likeEscaper.Replace(params.Service)
+	}
+	if cursor := get("cursor"); cursor != "" {
+		parts, err := DecodeCursor(cursor, 2)
+		if err != nil {
+			return nil, err
+		}
+		params.Watched_3, params.Watched_4, params.Uuid = parts[0], parts[0], parts[1]
+	}
+	for _, flag := range query["flag"] {
+		switch flag {
+		case "first_time":
+			params.FirstTime = 1
+		case "joe_bob":
+			params.JoeBob = 1
+		case "call_felissa":
+			params.CallFelissa = 1
+		case "slasher":
+			params.Slasher = 1
+		case "zombies":
+			params.Zombies = 1
+		case "beast":
+			params.Beast = 1
+		case "godzilla":
+			params.Godzilla = 1
+		case "wallpaper_fu":
+			params.WallpaperFu = 1
+		default:
+			return nil, fmt.Errorf(
+				"unknown flag %v, expected one of %v", flag,
+				strings.Join(append(WATCH_FLAGS, SEARCH_FLAGS...), ", "),
+			)
+		}
+	}
+	return &params, nil
+}
+
+func (s *apiServer) listWatches(w http.ResponseWriter, r *http.Request) {
+	limit, err := pageLimit(r)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params, err := CreateListMovieWatchesParams(r.URL.Query(), limit)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	watches, err := s.queries.ListMovieWatchesBefore(r.Context(), *params)
+	if err != nil {
+		writeInternalError(w, r, "error listing watches", err)
+		return
+	}
+	page := ApiPage{}
+	if int64(len(watches)) > limit {
+		watches = watches[:limit]
+		last := watches[len(watches)-1]
+		page.NextCursor = EncodeCursor(last.Watched, last.Uuid)
+	}
+	items := make([]ApiWatch, len(watches))
+	for ii := range watches {
+		items[ii] = ApiWatch{
+			Uuid:      watches[ii].Uuid,
+			MovieUuid: watches[ii].MovieUuid,
+			Title:     watches[ii].Title,
+			ImdbId:    watches[ii].ImdbID,
+			Year:      watches[ii].Year,
+			Watched:   watches[ii].Watched,
+			WatchedAt: watches[ii].WatchedAt.String,
+			Timezone:  watches[ii].Timezone.String,
+			Rating:    watches[ii].Rating.Float64,
+			Service:   watches[ii].Service,
+			FirstTime: watches[ii].FirstTime != 0,
+			JoeBob:    watches[ii].JoeBob != 0,
+			Notes:     watches[ii].Notes.String,
+		}
+	}
+	page.Items = items
+	writeJson(w, r, page)
+}
+
+func GetApiWatch(
+	ctx context.Context, queries *database.Queries, watchUuid string,
+) (*ApiWatch, error) {
+	watch, err := queries.GetMovieWatch(ctx, watchUuid)
+	if err != nil {
+		// Let sql.ErrNoRows through untouched so callers can 404.
+		return nil, err
+	}
+	movie, err := queries.GetMovie(ctx, watch.MovieUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting movie %v: %v", watch.MovieUuid, err)
+	}
+	return &ApiWatch{
+		Uuid:      watch.Uuid,
+		MovieUuid: watch.MovieUuid,
+		Title:     movie.Title,
+		ImdbId:    watch.ImdbID,
+		Year:      movie.Year,
+		Watched:   watch.Watched,
+		WatchedAt: watch.WatchedAt.String,
+		Timezone:  watch.Timezone.String,
+		Rating:    watch.Rating.Float64,
+		Service:   watch.Service,
+		FirstTime: watch.FirstTime != 0,
+		JoeBob:    watch.JoeBob != 0,
+		Notes:     watch.Notes.String,
+	}, nil
+}
+
+func (s *apiServer) getWatch(w http.ResponseWriter, r *http.Request) {
+	watchUuid := strings.TrimPrefix(r.URL.Path, "/watches/")
+	watch, err := GetApiWatch(r.Context(), s.queries, watchUuid)
+	if err == sql.ErrNoRows {
+		writeApiError(
+			w, http.StatusNotFound, fmt.Sprintf("no watch %v", watchUuid),
+		)
+		return
+	} else if err != nil {
+		writeInternalError(w, r, "error getting watch", err)
+		return
+	}
+	writeJson(w, r, watch)
+}
+
+func (s *apiServer) listReviews(w http.ResponseWriter, r *http.Request) {
+	reviews, err := s.queries.ListReviews(r.Context())
+	if err != nil {
+		writeInternalError(w, r, "error listing reviews", err)
+		return
+	}
+	items := make([]ApiReview, len(reviews))
+	for ii := range reviews {
+		items[ii] = ApiReview{
+			Uuid:      reviews[ii].Uuid,
+			MovieUuid: reviews[ii].MovieUuid,
+			Title:     reviews[ii].Title,
+			ImdbId:    reviews[ii].ImdbID,
+			Year:      reviews[ii].Year,
+			Review:    reviews[ii].Review,
+			Liked:     reviews[ii].Liked != 0,
+			Rating:    reviews[ii].Rating.Float64,
+			Watched:   reviews[ii].Watched.String,
+		}
+	}
+	writeJson(w, r, items)
+}
+
+// GetApiReviews gets a movie's reviews, oldest first, each with its
+// revisions.
+func GetApiReviews(
+	ctx context.Context, queries *database.Queries, imdbId string,
+) ([]ApiReview, error) {
+	movieUuid, err := queries.FindMovie(ctx, imdbId)
+	if err != nil {
+		// Let sql.ErrNoRows through untouched so callers can 404.
+		return nil, err
+	}
+	movie, err := queries.GetMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting movie: %v", err)
+	}
+	reviews, err := queries.GetReviewsForMovie(ctx, imdbId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting reviews: %v", err)
+	}
+	items := make([]ApiReview, len(reviews))
+	for ii := range reviews {
+		items[ii] = ApiReview{
+			Uuid:      reviews[ii].Uuid,
+			MovieUuid: reviews[ii].MovieUuid,
+			Title:     movie.Title,
+			ImdbId:    movie.ImdbID,
+			Year:      movie.Year,
+			Review:    reviews[ii].Review,
+			Liked:     reviews[ii].Liked != 0,
+			Rating:    reviews[ii].Rating.Float64,
+			Watched:   reviews[ii].Watched.String,
+		}
+		revisions, err := queries.GetReviewRevisions(ctx, reviews[ii].Uuid)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error getting revisions for %v: %v", reviews[ii].Uuid, err,
+			)
+		}
+		items[ii].Revisions = make([]ApiReviewRevision, len(revisions))
+		for jj := range revisions {
+			items[ii].Revisions[jj] = ApiReviewRevision{
+				Review: revisions[jj].Review,
+				Liked:  revisions[jj].Liked != 0,
+				Rating: revisions[jj].Rating.Float64,
+				Created: time.Unix(
+					revisions[jj].CreatedDatetime, 0,
+				).UTC().Format(time.RFC3339),
+			}
+		}
+	}
+	return items, nil
+}
+
+func (s *apiServer) getMovieReviews(w http.ResponseWriter, r *http.Request) {
+	imdbId := strings.TrimSuffix(
+		strings.TrimPrefix(r.URL.Path, "/movies/"), "/reviews",
+	)
+	reviews, err := GetApiReviews(r.Context(), s.queries, imdbId)
+	if err == sql.ErrNoRows {
+		writeApiError(
+			w, http.StatusNotFound, fmt.Sprintf("no movie with id %v", imdbId),
+		)
+		return
+	} else if err != nil {
+		writeInternalError(w, r, "error getting reviews", err)
+		return
+	}
+	writeJson(w, r, reviews)
+}
+
+func GetApiPerson(
+	ctx context.Context, queries *database.Queries, name string,
+) (*ApiPerson, error) {
+	personID, err := FindPersonID(ctx, queries, name)
+	if err != nil {
+		// Let sql.ErrNoRows through untouched so callers can 404.
+		return nil, err
+	}
+	person, err := queries.GetPerson(ctx, personID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting person %v: %v", personID, err)
+	}
+	aliases, err := queries.GetAliasesForPerson(ctx, personID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting aliases: %v", err)
+	}
+	credits, err := queries.GetCreditsForPerson(ctx, personID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting credits: %v", err)
+	}
+
+	apiPerson := ApiPerson{
+		ID:      person.ID,
+		Name:    person.Name,
+		ImdbId:  person.ImdbID.String,
+		Aliases: nonNilStrings(aliases),
+		Credits: make([]ApiCredit, len(credits)),
+	}
+	for ii := range credits {
+		apiPerson.Credits[ii] = ApiCredit{
+			ApiMovieSummary: ApiMovieSummary{
+				Uuid:   credits[ii].Uuid,
+				Title:  credits[ii].Title,
+				ImdbId: credits[ii].ImdbID,
+				Year:   credits[ii].Year,
+			},
+			Role:         credits[ii].Role,
+			BillingOrder: credits[ii].BillingOrder,
+		}
+	}
+	return &apiPerson, nil
+}
+
+func (s *apiServer) getPerson(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/people/")
+	person, err := GetApiPerson(r.Context(), s.queries, name)
+	if err == sql.ErrNoRows {
+		writeApiError(
+			w, http.StatusNotFound, fmt.Sprintf("no person named %v", name),
+		)
+		return
+	} else if err != nil {
+		writeInternalError(w, r, "error getting person", err)
+		return
+	}
+	writeJson(w, r, person)
+}
+
+func (s *apiServer) getStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	stats, err := s.queries.GetWatchStats(ctx)
+	if err != nil {
+		writeInternalError(w, r, "error getting stats", err)
+		return
+	}
+	byYear, err := s.queries.GetWatchCountsByYear(ctx)
+	if err != nil {
+		writeInternalError(w, r, "error getting watches by year", err)
+		return
+	}
+	byService, err := s.queries.GetWatchCountsByService(ctx)
+	if err != nil {
+		writeInternalError(w, r, "error getting watches by service", err)
+		return
+	}
+
+	apiStats := ApiStats{
+		NumWatches:       stats.NumWatches,
+		NumMovies:        stats.NumMovies,
+		NumFirstTime:     stats.NumFirstTime,
+		NumJoeBob:        stats.NumJoeBob,
+		FirstWatched:     stats.FirstWatched,
+		LastWatched:      stats.LastWatched,
+		WatchesByYear:    make(map[string]int64),
+		WatchesByService: make([]ApiServiceCount, len(byService)),
+	}
+	for ii := range byYear {
+		apiStats.WatchesByYear[byYear[ii].Year] = byYear[ii].NumWatches
+	}
+	for ii := range byService {
+		apiStats.WatchesByService[ii] = ApiServiceCount{
+			Service:    byService[ii].Service,
+			NumWatches: byService[ii].NumWatches,
+		}
+	}
+	writeJson(w, r, apiStats)
+}
+
+// getRatingStats takes min_ratings and top like the rating-stats command.
+func (s *apiServer) getRatingStats(w http.ResponseWriter, r *http.Request) {
+	minRatings, err := parseIntParam(r, "min_ratings", 3)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	top, err := parseIntParam(r, "top", 10)
+	if err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	stats, err := GetRatingStats(r.Context(), s.queries, minRatings, top)
+	if err != nil {
+		writeInternalError(w, r, "error getting rating stats", err)
+		return
+	}
+	writeJson(w, r, stats)
+}
+
+func (s *apiServer) getFeed(w http.ResponseWriter, r *http.Request) {
+	limit := int64(DEFAULT_FEED_SIZE)
+	if r.URL.Query().Get("limit") != "" {
+		var err error
+		if limit, err = pageLimit(r); err != nil {
+			writeApiError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	watches, err := s.queries.GetRecentWatches(r.Context(), limit)
+	if err != nil {
+		writeInternalError(w, r, "error getting recent watches", err)
+		return
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	feed := CreateAtomFeed(watches, &FeedOptions{
+		Title: "Movie Diary",
+		Url:   fmt.Sprintf("%v://%v/feed.xml", scheme, r.Host),
+	})
+	var body bytes.Buffer
+	if err := WriteAtomFeed(&body, feed); err != nil {
+		writeInternalError(w, r, "error writing feed", err)
+		return
+	}
+	writeWithEtag(w, r, "application/atom+xml", body.Bytes())
+}
+
+func serve(cmd *cobra.Command, args []string) {
+	addr, err := cmd.Flags().GetString("addr")
+	if err != nil {
+		log.Panicf("Error obtaining addr: %v", err)
+	}
+
+	write, err := cmd.Flags().GetBool("write")
+	if err != nil {
+		log.Panicf("Error obtaining write: %v", err)
+	}
+	vaultDir, err := cmd.Flags().GetString("vault")
+	if err != nil {
+		log.Panicf("Error obtaining vault: %v", err)
+	}
+	if write && vaultDir == "" {
+		log.Panicf("--vault is required with --write")
+	}
+
+	// The file: prefix is what gets the driver to hand mode=ro to sqlite.
+	dsn := fmt.Sprintf("file:%v?mode=ro", DB)
+	if write {
+		dsn = DB
+	}
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		log.Panicf("Error connecting to database %v: %v", DB, err)
+	}
+	queries := database.New(db)
+
+	var handler http.Handler
+	if write {
+		handler, err = NewWriteApiHandler(
+			db, queries, NewOmdbClient(OMDB_KEY), vaultDir, API_TOKEN,
+		)
+		if err != nil {
+			log.Panicf("Error creating write API: %v", err)
+		}
+	} else {
+		handler = NewApiHandler(queries)
+	}
+
+	log.Printf("Serving %v on %v.", DB, addr)
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		log.Panicf("Error serving: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := EncodeCursor("2022-05-27", "abc")
+	answer, err := DecodeCursor(cursor, 2)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	truth := []string{"2022-05-27", "abc"}
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected %v, got %v", truth, answer)
+	}
+	if _, err := DecodeCursor(cursor, 3); err == nil {
+		t.Errorf("Expected an error for the wrong number of parts")
+	}
+}
+
+func getJson(
+	t *testing.T, handler http.Handler, url string, value interface{},
+) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, url, nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if value != nil && recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), value); err != nil {
+			t.Fatalf("Error decoding %v: %v", url, err)
+		}
+	}
+	return recorder
+}
+
+func TestApi(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	movieDetails, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	for _, watched := range []string{"2022-05-27", "2022-10-31", "2023-01-01"} {
+		movieWatch := sampleMovieWatchPage()
+		movieWatch.Watched = watched
+		movieWatch.JoeBob = watched == "2022-10-31"
+		if watched == "2022-10-31" {
+			movieWatch.WatchedAt = mustWatchedAt(
+				"2022-10-31T23:30:00-05:00", "America/Chicago",
+			)
+			movieWatch.Rating = 4.5
+		}
+		if err := queries.InsertMovieWatch(
+			ctx, *CreateInsertMovieWatchParams(movieWatch, movieDetails.Movie),
+		); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+	handler := NewApiHandler(queries)
+
+	movie := ApiMovie{}
+	recorder := getJson(t, handler, "/movies/tt0084777", &movie)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v", recorder.Code)
+	}
+	if movie.Title != "Tenebrae" || len(movie.Actors) != 3 || !movie.Flags["slasher"] {
+		t.Errorf("Unexpected movie %v", movie)
+	}
+	recorder = getJson(t, handler, "/movies/tt0000000", nil)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %v", recorder.Code)
+	}
+
+	// ETags.
+	etag := getJson(t, handler, "/movies/tt0084777", nil).Header().Get("ETag")
+	request := httptest.NewRequest(http.MethodGet, "/movies/tt0084777", nil)
+	request.Header.Set("If-None-Match", etag)
+	conditional := httptest.NewRecorder()
+	handler.ServeHTTP(conditional, request)
+	if conditional.Code != http.StatusNotModified {
+		t.Errorf("Expected 304, got %v", conditional.Code)
+	}
+
+	// Pagination, newest first.
+	watched := make([]string, 0)
+	url := "/watches?limit=2"
+	for url != "" {
+		page := struct {
+			Items      []ApiWatch `json:"items"`
+			NextCursor string     `json:"next_cursor"`
+		}{}
+		if recorder := getJson(t, handler, url, &page); recorder.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %v", recorder.Code)
+		}
+		for ii := range page.Items {
+			watched = append(watched, page.Items[ii].Watched)
+		}
+		url = ""
+		if page.NextCursor != "" {
+			url = "/watches?limit=2&cursor=" + page.NextCursor
+		}
+	}
+	watchedTruth := []string{"2023-01-01", "2022-10-31", "2022-05-27"}
+	if !cmp.Equal(watchedTruth, watched) {
+		t.Errorf("Expected %v, got %v", watchedTruth, watched)
+	}
+
+	// Filters.
+	page := struct {
+		Items []ApiWatch `json:"items"`
+	}{}
+	getJson(t, handler, "/watches?flag=joe_bob&from=2022-01-01&service=Shudder", &page)
+	if len(page.Items) != 1 || page.Items[0].Watched != "2022-10-31" {
+		t.Fatalf("Expected the joe bob watch, got %v", page.Items)
+	}
+	// The list has everything getting the watch does.
+	listed := page.Items[0]
+	single := ApiWatch{}
+	getJson(t, handler, "/watches/"+listed.Uuid, &single)
+	if !cmp.Equal(single, listed) {
+		t.Errorf("Expected %v, got %v", single, listed)
+	}
+	if listed.WatchedAt != "2022-10-31T23:30:00-05:00" ||
+		listed.Timezone != "America/Chicago" || listed.Rating != 4.5 {
+		t.Errorf("Unexpected watch %v", listed)
+	}
+	page.Items = nil
+	getJson(t, handler, "/watches?service=Shud%25", &page)
+	if len(page.Items) != 0 {
+		t.Errorf("Expected no watches for a wildcard, got %v", page.Items)
+	}
+	if recorder := getJson(t, handler, "/watches?flag=kaiju", nil); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %v", recorder.Code)
+	}
+
+	person := ApiPerson{}
+	getJson(t, handler, "/people/Dario%20Argento", &person)
+	if person.Name != "Dario Argento" || len(person.Credits) != 2 {
+		t.Errorf("Unexpected person %v", person)
+	}
+
+	stats := ApiStats{}
+	getJson(t, handler, "/stats", &stats)
+	statsTruth := ApiStats{
+		NumWatches:    3,
+		NumMovies:     1,
+		NumJoeBob:     1,
+		FirstWatched:  "2022-05-27",
+		LastWatched:   "2023-01-01",
+		WatchesByYear: map[string]int64{"2022": 2, "2023": 1},
+		WatchesByService: []ApiServiceCount{
+			{Service: "Shudder", NumWatches: 3},
+		},
+	}
+	if !cmp.Equal(statsTruth, stats) {
+		t.Errorf("Expected %v, got %v", statsTruth, stats)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/stats", nil)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %v", recorder.Code)
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// templatesCmd represents the templates command
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Checks the page templates in the vault.",
+}
+
+var templatesValidateCmd = &cobra.Command{
+	Use:   "validate <vault>",
+	Short: "Renders sample pages with the vault's templates and parses them back.",
+	Run:   templatesValidate,
+	Args:  cobra.ExactArgs(1),
+}
+
+func init() {
+	rootCmd.AddCommand(templatesCmd)
+	templatesCmd.AddCommand(templatesValidateCmd)
+
+	templatesValidateCmd.Flags().BoolP(
+		"show", "s", false, "Whether to print the sample pages.",
+	)
+}
+
+func templatesValidate(cmd *cobra.Command, args []string) {
+	vaultDir := args[0]
+
+	show, err := cmd.Flags().GetBool("show")
+	if err != nil {
+		log.Panicf("Error obtaining show value: %v", err)
+	}
+
+	log.Printf("Loading templates from %v", VaultTemplatesDir(vaultDir))
+	templates, err := LoadVaultTemplates(vaultDir)
+	if err != nil {
+		log.Panicf("Error loading templates: %v", err)
+	}
+	for _, vaultTemplate := range templates.All() {
+		log.Printf("Template %v", vaultTemplate)
+		// The built-in ones aren't checked on load.
+		if err := ValidateVaultTemplate(vaultTemplate); err != nil {
+			log.Panicf("Error validating template: %v", err)
+		}
+		if show {
+			sample, err := RenderSample(vaultTemplate)
+			if err != nil {
+				log.Panicf("Error rendering sample: %v", err)
+			}
+			fmt.Printf("---- %v ----\n%v\n", vaultTemplate.FileName, sample)
+		}
+	}
+	log.Println("All templates write pages that parse back the same.")
+}
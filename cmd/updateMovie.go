@@ -7,6 +7,8 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"path"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/timothyrenner/movies-app/database"
@@ -47,6 +49,11 @@ func updateMovie(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Panicf("Error parsing %v: %v", moviePageFile, err)
 	}
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		log.Panicf("Error loading genre taxonomy: %v", err)
+	}
+	page.Genres = taxonomy.Normalize(page.Genres)
 
 	// Make a transaction here for the inserts.
 	log.Println("Preparing update transaction.")
@@ -55,8 +62,17 @@ func updateMovie(cmd *cobra.Command, args []string) {
 		log.Panicf("Error creating movie insert params: %v", err)
 	}
 
-	// Get the UUID from the database.
-	movieUuid, err := queries.FindMovie(ctx, insertMovieParams.ImdbID)
+	// Get the UUID from the database. The page is named with the file title
+	// vault_name has for the movie, which might not be the title on it.
+	pageName := moviePageNameRegex.FindStringSubmatch(
+		strings.TrimSuffix(path.Base(moviePageFile), ".md"),
+	)
+	if pageName == nil {
+		log.Panicf("%v isn't named like a movie page", moviePageFile)
+	}
+	movieUuid, err := FindMovieForPage(
+		ctx, queries, pageName[1], insertMovieParams.ImdbID,
+	)
 	if err != nil {
 		log.Panicf("Error finding movie %v: %v", insertMovieParams.ImdbID, err)
 	}
@@ -76,6 +92,27 @@ func updateMovie(cmd *cobra.Command, args []string) {
 	if err := qtx.InsertMovie(ctx, *insertMovieParams); err != nil {
 		log.Panicf("Error inserting movie: %v", err)
 	}
+	// The title might have changed, so the file title might have too.
+	fileTitle, err := SaveVaultName(
+		ctx, qtx, insertMovieParams.Uuid, page.Title,
+	)
+	if err != nil {
+		log.Panicf("Error saving vault name: %v", err)
+	}
+	if fileTitle != cleanTitle(page.Title) {
+		log.Printf(
+			"%v has the same file name as another title, so it's named %v.",
+			page.Title, fileTitle,
+		)
+	}
+	if expected := MoviePageFileName(
+		fileTitle, insertMovieParams.ImdbID,
+	); path.Base(moviePageFile) != expected {
+		log.Printf(
+			"Warning: %v should be named %v, rename it to keep links working.",
+			moviePageFile, expected,
+		)
+	}
 
 	// Delete and reinsert the associated auxiliary tables we have data in the
 	// page for.
@@ -139,6 +176,17 @@ func updateMovie(cmd *cobra.Command, args []string) {
 			)
 		}
 	}
+	if err := InsertGenres(ctx, qtx, page.Genres); err != nil {
+		log.Panicf("Error inserting genres: %v", err)
+	}
+
+	// Delete and repopulate credits.
+	if err := qtx.DeleteCreditsForMovie(ctx, movieUuid); err != nil {
+		log.Panicf("Error deleting credits for movie %v: %v", movieUuid, err)
+	}
+	if err := InsertMovieCredits(ctx, qtx, page, movieUuid); err != nil {
+		log.Panicf("Error inserting credits for movie %v: %v", movieUuid, err)
+	}
 
 	log.Println("Committing updates to database.")
 	if err := tx.Commit(); err != nil {
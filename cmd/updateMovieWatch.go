@@ -6,10 +6,8 @@ package cmd
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"log"
 	"path"
-	"text/template"
 
 	"github.com/spf13/cobra"
 	"github.com/timothyrenner/movies-app/database"
@@ -44,6 +42,10 @@ func updateMovieWatch(cmd *cobra.Command, args []string) {
 	defer db.Close()
 
 	queries := database.New(db)
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		log.Panicf("Error loading genre taxonomy: %v", err)
+	}
 
 	log.Println("Parsing the movie watch page.")
 	parser, err := CreateMovieWatchParser()
@@ -64,57 +66,38 @@ func updateMovieWatch(cmd *cobra.Command, args []string) {
 	if movieUuid == "" {
 		log.Printf("Fetching %v from OMDB.", page.Title)
 		omdbClient := NewOmdbClient(OMDB_KEY)
-		omdbResponse, err := omdbClient.GetMovie(page.ImdbId)
-		if err != nil {
-			log.Panicf("Error fetching movie from OMDB: %v", err)
-		}
-
-		moviePage, err := CreateMoviePage(omdbResponse, page)
-		if err != nil {
-			log.Panicf("Error creating movie page: %v", err)
-		}
-
-		movieDetailUuids, err := InsertMovieDetails(
-			db, ctx, queries, moviePage, omdbResponse.Ratings,
+		moviePage, movieDetailUuids, err := InsertMovieFromOmdb(
+			db, ctx, queries, omdbClient, taxonomy, page,
 		)
 		if err != nil {
-			log.Panicf(
-				"Error inserting movie details into database: %v", err,
-			)
+			log.Panicf("Error inserting movie %v: %v", page.ImdbId, err)
 		}
 		movieUuid = movieDetailUuids.Movie
 
-		moviePageFileName := fmt.Sprintf(
-			"%v (%v).md", page.FileTitle, page.ImdbId,
-		)
 		// The vault dir is two levels up from the watch page.
 		// First call to dir removes the file, second call moves up into the
 		// root of the vault.
 		vaultDir := path.Dir(path.Dir(movieWatchPageFile))
-		moviePageFilePath := path.Join(vaultDir, "Movies", moviePageFileName)
-		moviePageFile, skipMovie, err := createOrOpenFile(
-			false, moviePageFilePath,
+		templates, err := LoadVaultTemplates(vaultDir)
+		if err != nil {
+			log.Panicf("Unable to load templates: %v", err)
+		}
+		movieTemplate := templates.Movie.Template
+		fileTitle, err := GetMovieFileTitle(ctx, queries, movieUuid)
+		if err != nil {
+			log.Panicf("Error getting file title: %v", err)
+		}
+		moviePageFilePath := path.Join(
+			vaultDir, "Movies", MoviePageFileName(fileTitle, page.ImdbId),
 		)
+		created, err := WriteNewPage(movieTemplate, moviePageFilePath, moviePage)
 		if err != nil {
-			log.Panicf("Error opening file %v: %v", moviePageFilePath, err)
+			log.Panicf("Error writing movie page: %v", err)
 		}
-		defer moviePageFile.Close()
-		if !skipMovie {
-			log.Printf("Creating page %v", moviePageFileName)
-			movieTemplate, err := template.New("movie").Parse(MOVIE_TEMPLATE)
-			if err != nil {
-				log.Panicf("Unable to parse movie template: %v", err)
-			}
-			if err := movieTemplate.Execute(
-				moviePageFile, moviePage,
-			); err != nil {
-				log.Panicf(
-					"Error writing movie page %v: %v",
-					moviePageFilePath, err,
-				)
-			}
+		if created {
+			log.Printf("Created page %v", moviePageFilePath)
 		} else {
-			log.Printf("Page %v already exists, skipping.", moviePageFileName)
+			log.Printf("Page %v already exists, skipping.", moviePageFilePath)
 		}
 	}
 
@@ -7,13 +7,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 	"log"
 	"os"
 	"path"
 	"regexp"
 	"strings"
-	"text/template"
 
 	"github.com/spf13/cobra"
 	"github.com/timothyrenner/movies-app/database"
@@ -74,6 +72,10 @@ func updateRecentMovies(cmd *cobra.Command, args []string) {
 	}
 	defer db.Close()
 	queries := database.New(db)
+	taxonomy, err := LoadGenreTaxonomy(ctx, queries)
+	if err != nil {
+		log.Panicf("Error loading genre taxonomy: %v", err)
+	}
 
 	latestMovieWatchRow, err := queries.GetLatestMovieWatchDate(ctx)
 	if err != nil {
@@ -107,12 +109,14 @@ func updateRecentMovies(cmd *cobra.Command, args []string) {
 	}
 
 	// Initialize the template for movie pages.
-	movieTemplate, err := template.New("movie").Parse(MOVIE_TEMPLATE)
+	templates, err := LoadVaultTemplates(vaultDir)
 	if err != nil {
-		log.Panicf("Unable to parse movie template: %v", err)
+		log.Panicf("Unable to load templates: %v", err)
 	}
+	movieTemplate := templates.Movie.Template
 
 	newMovies := 0
+	watchlistChanged := false
 	for ii := range newMovieWatchFiles {
 		watchFile := newMovieWatchFiles[ii]
 		// Parse the watch file.
@@ -160,7 +164,9 @@ func updateRecentMovies(cmd *cobra.Command, args []string) {
 				log.Panicf("Error fetching movie from OMDB: %v", err)
 			}
 
-			moviePage, err := CreateMoviePage(omdbResponse, movieWatchPage)
+			moviePage, err := CreateMoviePage(
+				omdbResponse, movieWatchPage, taxonomy,
+			)
 			if err != nil {
 				log.Panicf("Error creating movie page: %v", err)
 			}
@@ -175,8 +181,12 @@ func updateRecentMovies(cmd *cobra.Command, args []string) {
 			}
 			movieUuid = movieDetailUuids.Movie
 
-			moviePageFileName := fmt.Sprintf(
-				"%v (%v).md", movieWatchPage.FileTitle, movieWatchPage.ImdbId,
+			fileTitle, err := GetMovieFileTitle(ctx, queries, movieUuid)
+			if err != nil {
+				log.Panicf("Error getting file title: %v", err)
+			}
+			moviePageFileName := MoviePageFileName(
+				fileTitle, movieWatchPage.ImdbId,
 			)
 			moviePageFilePath := path.Join(vaultDir, "Movies", moviePageFileName)
 			moviePageFile, skipMovie, err := createOrOpenFile(
@@ -208,6 +218,22 @@ func updateRecentMovies(cmd *cobra.Command, args []string) {
 			log.Panicf("Error inserting movie watch into database: %v", err)
 		}
 		newMovies += 1
+
+		removed, err := RemoveWatchedFromWatchlist(
+			ctx, queries, movieWatchPage.ImdbId,
+		)
+		if err != nil {
+			log.Panicf("Error updating watchlist: %v", err)
+		}
+		if removed {
+			log.Printf("Removed %v from the watchlist.", movieWatchPage.Title)
+			watchlistChanged = true
+		}
+	}
+	if watchlistChanged {
+		if err := WriteWatchlistPage(ctx, queries, vaultDir); err != nil {
+			log.Panicf("Error writing watchlist page: %v", err)
+		}
 	}
 	log.Printf("Completed. Inserted %v new movie watches.", newMovies)
 }
@@ -16,8 +16,13 @@ import (
 var updateReviewCmd = &cobra.Command{
 	Use:   "update-review",
 	Short: "Updates a review in the database from an Obsidian page",
-	Run:   updateReview,
-	Args:  cobra.RangeArgs(1, 1),
+	Long: `Updates a review in the database from an Obsidian page.
+
+The page's review_id picks the review to update. Pages without one update
+the latest review of the movie for the same watch, or add a new review if
+there isn't one. The previous text is kept as a revision.`,
+	Run:  updateReview,
+	Args: cobra.RangeArgs(1, 1),
 }
 
 func init() {
@@ -45,23 +50,14 @@ func updateReview(cmd *cobra.Command, args []string) {
 		log.Panicf("Error parsing review page: %v", err)
 	}
 
-	// Get the movie uuid from the db for that title.
-	movieUuid, err := queries.FindMovie(ctx, page.ImdbId)
+	changed, err := SaveReview(db, ctx, queries, page)
 	if err != nil {
-		log.Panicf(
-			"Error obtaining movie %v (%v): %v",
-			page.ImdbId, page.MovieTitle, err,
-		)
+		log.Panicf("Error saving review for %v: %v", page.MovieTitle, err)
 	}
 
-	movieReviewParams := CreateInsertMovieReviewParams(page, movieUuid)
-
-	if err := queries.InsertReview(ctx, *movieReviewParams); err != nil {
-		log.Panicf(
-			"Error inserting review for %v: %v",
-			movieReviewParams.MovieTitle, err,
-		)
+	if !changed {
+		log.Printf("Review %v unchanged for %v", page.Uuid, reviewFile)
+		return
 	}
-
-	log.Printf("Review successfully updated for %v", reviewFile)
+	log.Printf("Review %v successfully updated for %v", page.Uuid, reviewFile)
 }
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Watch and movie pages are part generated, part mine. The sections under
+// these headings come from the database; everything else, the title line,
+// notes and any sections I've added, is left alone when merging.
+var GENERATED_SECTIONS = []string{"## Data", "## Tags"}
+
+// How build-obsidian-vault treats pages that already exist.
+type WriteMode int
+
+const (
+	// Leave existing pages alone.
+	WRITE_MISSING WriteMode = iota
+	// Replace existing pages outright, notes and all.
+	WRITE_FORCE
+	// Replace only the generated sections of existing pages.
+	WRITE_MERGE
+)
+
+// What happened to a page when it was written.
+type PageResult int
+
+const (
+	PAGE_CREATED PageResult = iota
+	PAGE_UPDATED
+	PAGE_UNCHANGED
+	// The page's generated sections couldn't be found, so it was left alone.
+	PAGE_CONFLICTED
+)
+
+// PageCounts tallies the results for a batch of pages. It's safe to use from
+// many goroutines.
+type PageCounts struct {
+	mu         sync.Mutex
+	Created    int
+	Updated    int
+	Unchanged  int
+	Conflicted int
+	// The paths of the conflicted pages.
+	Conflicts []string
+}
+
+func (c *PageCounts) Add(result PageResult, filePath string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch result {
+	case PAGE_CREATED:
+		c.Created++
+	case PAGE_UPDATED:
+		c.Updated++
+	case PAGE_UNCHANGED:
+		c.Unchanged++
+	case PAGE_CONFLICTED:
+		c.Conflicted++
+		c.Conflicts = append(c.Conflicts, filePath)
+	}
+}
+
+// Written is how many pages were created or updated.
+func (c *PageCounts) Written() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Created + c.Updated
+}
+
+func (c *PageCounts) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return fmt.Sprintf(
+		"%v created, %v updated, %v unchanged, %v conflicted",
+		c.Created, c.Updated, c.Unchanged, c.Conflicted,
+	)
+}
+
+// WritePageWithMode renders the page into filePath, treating an existing
+// page according to the mode.
+func WritePageWithMode(
+	pageTemplate *template.Template,
+	filePath string,
+	page interface{},
+	mode WriteMode,
+) (PageResult, error) {
+	switch mode {
+	case WRITE_MERGE:
+		return MergePage(pageTemplate, filePath, page)
+	case WRITE_FORCE:
+		_, err := os.Stat(filePath)
+		exists := err == nil
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return PAGE_UNCHANGED, fmt.Errorf(
+				"error checking %v: %v", filePath, err,
+			)
+		}
+		rendered, err := renderPage(pageTemplate, page)
+		if err != nil {
+			return PAGE_UNCHANGED, fmt.Errorf(
+				"error rendering %v: %v", filePath, err,
+			)
+		}
+		if err := WriteFileAtomically(filePath, rendered); err != nil {
+			return PAGE_UNCHANGED, err
+		}
+		if exists {
+			return PAGE_UPDATED, nil
+		}
+		return PAGE_CREATED, nil
+	default:
+		created, err := WriteNewPage(pageTemplate, filePath, page)
+		if err != nil {
+			return PAGE_UNCHANGED, err
+		}
+		if created {
+			return PAGE_CREATED, nil
+		}
+		return PAGE_UNCHANGED, nil
+	}
+}
+
+// MergePage renders the page and swaps its generated sections into the
+// existing file, leaving the rest of the file as it is. Files are only
+// written when something changed.
+func MergePage(
+	pageTemplate *template.Template, filePath string, page interface{},
+) (PageResult, error) {
+	rendered, err := renderPage(pageTemplate, page)
+	if err != nil {
+		return PAGE_UNCHANGED, fmt.Errorf(
+			"error rendering %v: %v", filePath, err,
+		)
+	}
+	existing, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := WriteFileAtomically(filePath, rendered); err != nil {
+			return PAGE_UNCHANGED, err
+		}
+		return PAGE_CREATED, nil
+	} else if err != nil {
+		return PAGE_UNCHANGED, fmt.Errorf("error reading %v: %v", filePath, err)
+	}
+
+	merged, ok := MergeGeneratedSections(string(existing), string(rendered))
+	if !ok {
+		return PAGE_CONFLICTED, nil
+	}
+	if merged == string(existing) {
+		return PAGE_UNCHANGED, nil
+	}
+	if err := WriteFileAtomically(filePath, []byte(merged)); err != nil {
+		return PAGE_UNCHANGED, err
+	}
+	return PAGE_UPDATED, nil
+}
+
+// MergeGeneratedSections replaces each generated section of the existing
+// page with the rendered one. It returns false if either page is missing a
+// generated heading or has it more than once.
+func MergeGeneratedSections(existing string, rendered string) (string, bool) {
+	existingLines := strings.Split(existing, "\n")
+	renderedLines := strings.Split(rendered, "\n")
+	for _, heading := range GENERATED_SECTIONS {
+		existingStart, existingEnd, ok := findSection(existingLines, heading)
+		if !ok {
+			return "", false
+		}
+		renderedStart, renderedEnd, ok := findSection(renderedLines, heading)
+		if !ok {
+			return "", false
+		}
+		merged := make([]string, 0, len(existingLines))
+		merged = append(merged, existingLines[:existingStart]...)
+		merged = append(merged, renderedLines[renderedStart:renderedEnd]...)
+		merged = append(merged, existingLines[existingEnd:]...)
+		existingLines = merged
+	}
+	return strings.Join(existingLines, "\n"), true
+}
+
+// findSection returns the lines from the heading up to the next heading of
+// any level, or the end of the page.
+func findSection(lines []string, heading string) (int, int, bool) {
+	start := -1
+	for ii := range lines {
+		if strings.TrimRight(lines[ii], " \r") == heading {
+			if start >= 0 {
+				return 0, 0, false
+			}
+			start = ii
+		}
+	}
+	if start < 0 {
+		return 0, 0, false
+	}
+	end := start + 1
+	for end < len(lines) && !isHeading(lines[end]) {
+		end++
+	}
+	return start, end, true
+}
+
+// isHeading is whether the line is a markdown heading. Tags like #movie
+// don't have the space.
+func isHeading(line string) bool {
+	trimmed := strings.TrimLeft(line, "#")
+	return len(trimmed) < len(line) && len(line)-len(trimmed) <= 6 &&
+		strings.HasPrefix(trimmed, " ")
+}
+
+func renderPage(pageTemplate *template.Template, page interface{}) (
+	[]byte, error,
+) {
+	var rendered bytes.Buffer
+	if err := pageTemplate.Execute(&rendered, page); err != nil {
+		return nil, err
+	}
+	return rendered.Bytes(), nil
+}
+
+// WriteFileAtomically writes to a temporary file next to filePath and
+// renames it into place, so an interrupted build never leaves half a page.
+func WriteFileAtomically(filePath string, contents []byte) error {
+	file, err := os.CreateTemp(
+		path.Dir(filePath), "."+path.Base(filePath)+".*.tmp",
+	)
+	if err != nil {
+		return fmt.Errorf("error creating temp file for %v: %v", filePath, err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(contents); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing %v: %v", file.Name(), err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing %v: %v", file.Name(), err)
+	}
+	// CreateTemp makes it 0600, pages are readable like any other file.
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		return fmt.Errorf("error setting permissions on %v: %v", file.Name(), err)
+	}
+	if err := os.Rename(file.Name(), filePath); err != nil {
+		return fmt.Errorf("error moving %v to %v: %v", file.Name(), filePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"os"
+	"path"
+	"testing"
+	"text/template"
+)
+
+func TestMergeGeneratedSections(t *testing.T) {
+	rendered := "# Tenebrae: 2022-10-31\n\n## Data\nservice:: Shudder\n\n" +
+		"## Tags\n#movie-watch\n\n## Notes\n\n"
+	tests := []struct {
+		name     string
+		existing string
+		truth    string
+		ok       bool
+	}{
+		{
+			"unchanged",
+			rendered,
+			rendered,
+			true,
+		},
+		{
+			"keeps the title, notes and my sections",
+			"# Tenebrae!: 2022-10-31\n\n## Data\nservice:: Tubi\n\n" +
+				"## Tags\n#movie-watch\n#giallo\n\n## Notes\nSharp.\n\n### Mine\nStays.\n",
+			"# Tenebrae!: 2022-10-31\n\n## Data\nservice:: Shudder\n\n" +
+				"## Tags\n#movie-watch\n\n## Notes\nSharp.\n\n### Mine\nStays.\n",
+			true,
+		},
+		{
+			"windows line endings",
+			"# Tenebrae: 2022-10-31\r\n\r\n## Data\r\nservice:: Tubi\r\n\r\n" +
+				"## Tags\r\n#movie-watch\r\n\r\n## Notes\r\nSharp.\r\n",
+			"# Tenebrae: 2022-10-31\r\n\r\n## Data\nservice:: Shudder\n\n" +
+				"## Tags\n#movie-watch\n\n## Notes\r\nSharp.\r\n",
+			true,
+		},
+		{
+			"missing tags",
+			"# Tenebrae: 2022-10-31\n\n## Data\nservice:: Tubi\n\n## Notes\n",
+			"",
+			false,
+		},
+		{
+			"doubled data",
+			"## Data\nservice:: Tubi\n## Data\n## Tags\n",
+			"",
+			false,
+		},
+	}
+	for _, test := range tests {
+		answer, ok := MergeGeneratedSections(test.existing, rendered)
+		if ok != test.ok || answer != test.truth {
+			t.Errorf(
+				"%v: expected %q %v, got %q %v",
+				test.name, test.truth, test.ok, answer, ok,
+			)
+		}
+	}
+}
+
+func TestMergePage(t *testing.T) {
+	pageTemplate, err := template.New("page").Parse(
+		"# {{.}}\n## Data\ntitle:: {{.}}\n\n## Tags\n#movie\n",
+	)
+	if err != nil {
+		t.Fatalf("Error parsing template: %v", err)
+	}
+	dir := t.TempDir()
+	filePath := path.Join(dir, "Tenebrae (tt0084777).md")
+
+	for _, step := range []struct {
+		title string
+		truth PageResult
+	}{
+		{"Tenebrae", PAGE_CREATED},
+		{"Tenebrae", PAGE_UNCHANGED},
+		{"Tenebre", PAGE_UPDATED},
+	} {
+		result, err := MergePage(pageTemplate, filePath, step.title)
+		if err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		if result != step.truth {
+			t.Errorf("Expected %v for %v, got %v", step.truth, step.title, result)
+		}
+	}
+	answer, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	truth := "# Tenebrae\n## Data\ntitle:: Tenebre\n\n## Tags\n#movie\n"
+	if string(answer) != truth {
+		t.Errorf("Expected %q, got %q", truth, string(answer))
+	}
+
+	// The temp files are always cleaned up, and pages aren't private.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected just the page, got %v", entries)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("Expected 0644, got %v", info.Mode().Perm())
+	}
+}
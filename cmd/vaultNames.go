@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// Everything in the vault is named after something in the database: movie
+// titles, people, genres and lists. This is the one place that decides what
+// those names turn into on disk. Movie titles go one step further and have
+// their file title saved in vault_name, so going from a page back to the
+// movie is a lookup instead of a regex, and two titles that end up with the
+// same file title get told apart.
+
+// Always stripped. Obsidian links break on [ ] | # ^, paths on / and \, and
+// : is trouble on macOS and Windows.
+const VAULT_RESERVED_CHARACTERS = `:/\#^[]|`
+
+// Longest a file title can be in bytes. File systems stop at 255 and the
+// date or IMDB ID and the extension go on top of it.
+const MAX_FILE_TITLE_BYTES = 200
+
+// Extra characters to strip from file names, set with VAULT_UNSAFE_CHARACTERS
+// in the environment or .env, like `?*"<>` for a vault synced to Windows.
+// Changing it renames pages, so rebuild the vault with --force after.
+var VAULT_UNSAFE_CHARACTERS string
+
+// cleanTitle makes the title safe for file names and wiki-links. Whatever it
+// returns has to match MOVIE_LINK_PATTERN's title. Letters from any script
+// are left alone, it only drops the characters above and the invisible ones.
+func cleanTitle(title string) string {
+	title = strings.Map(func(r rune) rune {
+		switch {
+		case r == utf8.RuneError,
+			strings.ContainsRune(VAULT_RESERVED_CHARACTERS, r),
+			strings.ContainsRune(VAULT_UNSAFE_CHARACTERS, r):
+			return -1
+		case r == '\t' || r == '\n' || r == '\r':
+			return ' '
+		// Joiners are part of how some scripts and emoji are spelled.
+		case r == '\u200c' || r == '\u200d':
+			return r
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			return -1
+		}
+		return r
+	}, title)
+	title = strings.TrimSpace(title)
+
+	if len(title) > MAX_FILE_TITLE_BYTES {
+		end := MAX_FILE_TITLE_BYTES
+		for end > 0 && !utf8.RuneStart(title[end]) {
+			end--
+		}
+		title = strings.TrimSpace(title[:end])
+	}
+	return title
+}
+
+// pageLink is what goes between the brackets of a wiki-link to the page
+// named for name, like genre and person pages are. The name is kept as the
+// alias when cleaning changed it, so it reads and parses back the same.
+func pageLink(name string) string {
+	pageName := cleanTitle(name)
+	if pageName == name {
+		return name
+	}
+	return pageName + "|" + name
+}
+
+// pageLinks is pageLink for each of the names.
+func pageLinks(names []string) []string {
+	links := make([]string, len(names))
+	for ii := range names {
+		links[ii] = pageLink(names[ii])
+	}
+	return links
+}
+
+// splitLinkNames splits a comma separated list of pageLink links back into
+// the names, taking the alias if there is one.
+func splitLinkNames(links string) []string {
+	names := SplitOnCommaAndTrim(links)
+	for ii := range names {
+		if idx := strings.Index(names[ii], "|"); idx >= 0 {
+			names[ii] = names[ii][idx+1:]
+		}
+	}
+	return names
+}
+
+// fileTitleCandidate is the n-th file title to try for a title that cleans
+// to fileTitle: the file title itself, then "fileTitle (2)", "fileTitle (3)"
+// and so on.
+func fileTitleCandidate(fileTitle string, n int) string {
+	if n == 1 {
+		return fileTitle
+	}
+	return fmt.Sprintf("%v (%v)", fileTitle, n)
+}
+
+// isFileTitleCandidate is whether candidate is one of fileTitle's candidates.
+func isFileTitleCandidate(candidate string, fileTitle string) bool {
+	if candidate == fileTitle {
+		return true
+	}
+	suffix := strings.TrimPrefix(candidate, fileTitle+" (")
+	if suffix == candidate || !strings.HasSuffix(suffix, ")") {
+		return false
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(suffix, ")"))
+	return err == nil && n > 1 && fileTitleCandidate(fileTitle, n) == candidate
+}
+
+// fileTitleTaken is whether a movie other than movieUuid with a different
+// title already has the file title. Movies with the same title, like
+// remakes, share one since their IMDB IDs tell the movie pages apart.
+func fileTitleTaken(
+	ctx context.Context,
+	queries *database.Queries,
+	fileTitle string,
+	movieUuid string,
+	title string,
+) (bool, error) {
+	movies, err := queries.FindMoviesForFileTitle(ctx, fileTitle)
+	if err != nil {
+		return false, fmt.Errorf(
+			"error finding movies for file title %v: %v", fileTitle, err,
+		)
+	}
+	for ii := range movies {
+		if movies[ii].Uuid != movieUuid && movies[ii].Title != title {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SaveVaultName records the file title for the movie and returns it. When
+// another title already cleans to the same file title the movie gets the
+// first free one with a number on the end, so watch pages from the same day
+// can't overwrite each other. A movie keeps the file title it has as long as
+// it's still one of its title's candidates and nobody else has it.
+func SaveVaultName(
+	ctx context.Context, queries *database.Queries, movieUuid string, title string,
+) (string, error) {
+	cleaned := cleanTitle(title)
+	current, err := queries.GetVaultFileTitle(ctx, movieUuid)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf(
+			"error getting file title for %v: %v", title, err,
+		)
+	}
+
+	fileTitle := ""
+	if err == nil && isFileTitleCandidate(current, cleaned) {
+		taken, err := fileTitleTaken(ctx, queries, current, movieUuid, title)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			fileTitle = current
+		}
+	}
+	for n := 1; fileTitle == ""; n++ {
+		candidate := fileTitleCandidate(cleaned, n)
+		taken, err := fileTitleTaken(ctx, queries, candidate, movieUuid, title)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			fileTitle = candidate
+		}
+	}
+
+	if err := queries.UpsertVaultName(ctx, database.UpsertVaultNameParams{
+		MovieUuid: movieUuid,
+		FileTitle: fileTitle,
+	}); err != nil {
+		return "", fmt.Errorf(
+			"error saving vault name for %v: %v", title, err,
+		)
+	}
+	return fileTitle, nil
+}
+
+// GetMovieFileTitle is the file title the movie's pages are named with,
+// saving it first if the movie predates vault_name.
+func GetMovieFileTitle(
+	ctx context.Context, queries *database.Queries, movieUuid string,
+) (string, error) {
+	fileTitle, err := queries.GetVaultFileTitle(ctx, movieUuid)
+	if err == nil {
+		return fileTitle, nil
+	} else if err != sql.ErrNoRows {
+		return "", fmt.Errorf(
+			"error getting file title for %v: %v", movieUuid, err,
+		)
+	}
+	movie, err := queries.GetMovie(ctx, movieUuid)
+	if err != nil {
+		return "", fmt.Errorf("error getting movie %v: %v", movieUuid, err)
+	}
+	return SaveVaultName(ctx, queries, movieUuid, movie.Title)
+}
+
+// SaveAllVaultNames brings vault_name up to date with every movie's title
+// and the current VAULT_UNSAFE_CHARACTERS, returning the titles whose pages
+// are named something other than the cleaned title.
+func SaveAllVaultNames(
+	ctx context.Context, queries *database.Queries,
+) (map[string]string, error) {
+	movies, err := queries.GetMovieTitles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting movie titles: %v", err)
+	}
+	suffixed := make(map[string]string)
+	for ii := range movies {
+		fileTitle, err := SaveVaultName(
+			ctx, queries, movies[ii].Uuid, movies[ii].Title,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if fileTitle != cleanTitle(movies[ii].Title) {
+			suffixed[movies[ii].Title] = fileTitle
+		}
+	}
+	return suffixed, nil
+}
+
+// VaultNames are the saved file titles of the movies in the database, by
+// IMDB ID. Everything that writes a movie's page name goes through it.
+type VaultNames map[string]string
+
+// LoadVaultNames gets the file title for every movie, saving one first for
+// any movie that predates vault_name.
+func LoadVaultNames(
+	ctx context.Context, queries *database.Queries,
+) (VaultNames, error) {
+	missing, err := queries.GetMoviesWithoutVaultName(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting movies without vault names: %v", err)
+	}
+	for ii := range missing {
+		if _, err := SaveVaultName(
+			ctx, queries, missing[ii].Uuid, missing[ii].Title,
+		); err != nil {
+			return nil, err
+		}
+	}
+	rows, err := queries.GetVaultNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting vault names: %v", err)
+	}
+	vaultNames := make(VaultNames, len(rows))
+	for ii := range rows {
+		vaultNames[rows[ii].ImdbID] = rows[ii].FileTitle
+	}
+	return vaultNames, nil
+}
+
+// FileTitle is the file title for the movie's pages. Movies that aren't in
+// the database yet, like the ones on the watchlist, get their cleaned title,
+// which is what they'll be saved with unless it collides.
+func (v VaultNames) FileTitle(imdbId string, title string) string {
+	if fileTitle, ok := v[imdbId]; ok {
+		return fileTitle
+	}
+	return cleanTitle(title)
+}
+
+// FindMovieForPage is the movie a page links to or is named for, going from
+// the file title through vault_name rather than trusting the title in the
+// link. Movies that predate vault_name are found by IMDB ID alone.
+func FindMovieForPage(
+	ctx context.Context,
+	queries *database.Queries,
+	fileTitle string,
+	imdbId string,
+) (string, error) {
+	movies, err := queries.FindMoviesForFileTitle(ctx, fileTitle)
+	if err != nil {
+		return "", fmt.Errorf(
+			"error finding movies for file title %v: %v", fileTitle, err,
+		)
+	}
+	for ii := range movies {
+		if movies[ii].ImdbID == imdbId {
+			return movies[ii].Uuid, nil
+		}
+	}
+
+	movieUuid, err := queries.FindMovie(ctx, imdbId)
+	if err != nil {
+		return "", err
+	}
+	saved, err := queries.GetVaultFileTitle(ctx, movieUuid)
+	if err == sql.ErrNoRows {
+		return movieUuid, nil
+	} else if err != nil {
+		return "", fmt.Errorf(
+			"error getting file title for %v: %v", movieUuid, err,
+		)
+	}
+	return "", fmt.Errorf(
+		"the page names %v (%v), but that movie's pages are named %v",
+		fileTitle, imdbId, saved,
+	)
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestCleanTitleUnicode(t *testing.T) {
+	tests := []struct {
+		title string
+		truth string
+	}{
+		{"Häxan", "Häxan"},
+		{"Le Frisson des Vampires", "Le Frisson des Vampires"},
+		{"Kwaidan / 怪談", "Kwaidan  怪談"},
+		{"Ночной дозор", "Ночной дозор"},
+		{"Mother!", "Mother!"},
+		{"What Ever Happened to Baby Jane?", "What Ever Happened to Baby Jane?"},
+		{"Dr. Jekyll & Sister Hyde", "Dr. Jekyll & Sister Hyde"},
+		{"\u200fاناباز\u200e", "اناباز"},
+		{"Tetsuo:\tThe\x00 Iron Man\n", "Tetsuo The Iron Man"},
+		{"Broken \xff Bytes", "Broken  Bytes"},
+		{strings.Repeat("ö", 150), strings.Repeat("ö", 100)},
+	}
+	for _, test := range tests {
+		if answer := cleanTitle(test.title); answer != test.truth {
+			t.Errorf("Expected %q for %q, got %q", test.truth, test.title, answer)
+		}
+	}
+}
+
+func TestCleanTitleUnsafeCharacters(t *testing.T) {
+	defer func(unsafe string) { VAULT_UNSAFE_CHARACTERS = unsafe }(
+		VAULT_UNSAFE_CHARACTERS,
+	)
+	VAULT_UNSAFE_CHARACTERS = `?*"<>`
+
+	title := `What Ever Happened to "Baby Jane"?`
+	truth := "What Ever Happened to Baby Jane"
+	if answer := cleanTitle(title); answer != truth {
+		t.Errorf("Expected %q, got %q", truth, answer)
+	}
+}
+
+func TestPageLinks(t *testing.T) {
+	names := []string{"Dario Argento", "Sci-Fi/Horror", "Bob: Jr."}
+	links := pageLinks(names)
+	truth := []string{
+		"Dario Argento", "Sci-FiHorror|Sci-Fi/Horror", "Bob Jr.|Bob: Jr.",
+	}
+	if !cmp.Equal(truth, links) {
+		t.Errorf("Expected %v, got %v", truth, links)
+	}
+	answer := splitLinkNames("[[" + strings.Join(links, "]], [[") + "]]")
+	if !cmp.Equal(names, answer) {
+		t.Errorf("Expected %v, got %v", names, answer)
+	}
+}
+
+func TestSaveVaultName(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	// Inserting the movie saves its file title.
+	vhs := sampleMoviePage()
+	vhs.Title = "V/H/S"
+	vhs.ImdbLink = "https://www.imdb.com/title/tt2105044/"
+	vhsUuids, err := InsertMovieDetails(db, ctx, queries, vhs, []Rating{})
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	fileTitle, err := GetMovieFileTitle(ctx, queries, vhsUuids.Movie)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if fileTitle != "VHS" {
+		t.Errorf("Expected VHS, got %v", fileTitle)
+	}
+
+	// A remake has the same title, so it isn't a collision.
+	remake := sampleMoviePage()
+	remake.Title = "V/H/S"
+	remake.ImdbLink = "https://www.imdb.com/title/tt9999999/"
+	remakeUuids, err := InsertMovieDetails(db, ctx, queries, remake, []Rating{})
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	fileTitle, err = SaveVaultName(
+		ctx, queries, remakeUuids.Movie, remake.Title,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if fileTitle != "VHS" {
+		t.Errorf("Expected VHS, got %v", fileTitle)
+	}
+
+	// A different title with the same file title gets a number on the end.
+	other := sampleMoviePage()
+	other.Title = "VHS"
+	other.ImdbLink = "https://www.imdb.com/title/tt0000001/"
+	otherUuids, err := InsertMovieDetails(db, ctx, queries, other, []Rating{})
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	fileTitle, err = GetMovieFileTitle(ctx, queries, otherUuids.Movie)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if fileTitle != "VHS (2)" {
+		t.Errorf("Expected VHS (2), got %v", fileTitle)
+	}
+
+	// The file titles go back to the movies.
+	movies, err := queries.FindMoviesForFileTitle(ctx, "VHS")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(movies) != 2 {
+		t.Errorf("Expected 2 movies, got %v", movies)
+	}
+	movieUuid, err := FindMovieForPage(ctx, queries, "VHS (2)", "tt0000001")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if movieUuid != otherUuids.Movie {
+		t.Errorf("Expected %v, got %v", otherUuids.Movie, movieUuid)
+	}
+	if _, err := FindMovieForPage(
+		ctx, queries, "VHS", "tt0000001",
+	); err == nil {
+		t.Errorf("Expected an error for a page with the wrong file title")
+	}
+
+	// Saving them all again doesn't move anyone.
+	for range []int{1, 2} {
+		suffixed, err := SaveAllVaultNames(ctx, queries)
+		if err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		truth := map[string]string{"VHS": "VHS (2)"}
+		if !cmp.Equal(truth, suffixed) {
+			t.Errorf("Expected %v, got %v", truth, suffixed)
+		}
+	}
+
+	vaultNames, err := LoadVaultNames(ctx, queries)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	vaultNamesTruth := VaultNames{
+		"tt2105044": "VHS", "tt9999999": "VHS", "tt0000001": "VHS (2)",
+	}
+	if !cmp.Equal(vaultNamesTruth, vaultNames) {
+		t.Errorf("Expected %v, got %v", vaultNamesTruth, vaultNames)
+	}
+	// Movies that aren't in the database get their cleaned title.
+	answer := vaultNames.FileTitle("tt0083629", "Q: The Winged Serpent")
+	if answer != "Q The Winged Serpent" {
+		t.Errorf("Expected Q The Winged Serpent, got %v", answer)
+	}
+}
+
+func TestGetMovieFileTitleBackfills(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+
+	movieUuids, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), []Rating{},
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	// Like a movie inserted before there was a vault_name table.
+	if _, err := db.Exec("DELETE FROM vault_name"); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	fileTitle, err := GetMovieFileTitle(ctx, queries, movieUuids.Movie)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if fileTitle != "Tenebrae" {
+		t.Errorf("Expected Tenebrae, got %v", fileTitle)
+	}
+}
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// Where --archive moves stale pages, inside the vault.
+const ARCHIVE_DIR = "Archive"
+
+// Page names as MoviePageFileName and MovieWatchPageFileName write them,
+// without the extension.
+var moviePageNameRegex = regexp.MustCompile(`^(.+) \((tt\d{7,8})\)$`)
+var watchPageNameRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}) (.+)$`)
+
+// StalePage is a generated page that no longer matches anything in the
+// database, usually because a repair script deleted or merged its movie or
+// watch.
+type StalePage struct {
+	// Movies or Watches.
+	Dir string
+	// The page name, the file name without the extension.
+	Name string
+	// The page that took its place, if there's one, so links can be moved
+	// over. Empty if there's nothing to point them at.
+	Replacement string
+}
+
+func (p *StalePage) String() string {
+	if p.Replacement == "" {
+		return path.Join(p.Dir, p.Name+".md")
+	}
+	return fmt.Sprintf(
+		"%v (replaced by %v)", path.Join(p.Dir, p.Name+".md"), p.Replacement,
+	)
+}
+
+// FindStalePages looks for movie and watch pages in the vault that the
+// database doesn't account for. Movie pages are matched on the IMDB ID and
+// title in the file name, watch pages on the date and title. Anything in
+// those directories that isn't named like a generated page is left out.
+//
+// Watch pages that still parse but whose movie was never watched that day
+// are returned separately as unknown: they're usually pages written by hand
+// that update-recent-movies hasn't picked up yet, not leftovers.
+func FindStalePages(
+	ctx context.Context, queries *database.Queries, vaultDir string,
+) ([]StalePage, []StalePage, error) {
+	movies, err := queries.GetAllMovies(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting movies: %v", err)
+	}
+	vaultNames, err := LoadVaultNames(ctx, queries)
+	if err != nil {
+		return nil, nil, err
+	}
+	moviePages := make(map[string]bool)
+	moviePagesByImdbId := make(map[string]string)
+	moviePagesByFileTitle := make(map[string][]string)
+	for ii := range movies {
+		fileTitle := vaultNames.FileTitle(movies[ii].ImdbID, movies[ii].Title)
+		name := strings.TrimSuffix(
+			MoviePageFileName(fileTitle, movies[ii].ImdbID), ".md",
+		)
+		moviePages[name] = true
+		moviePagesByImdbId[movies[ii].ImdbID] = name
+		moviePagesByFileTitle[fileTitle] = append(
+			moviePagesByFileTitle[fileTitle], name,
+		)
+	}
+
+	watches, err := queries.GetAllMovieWatches(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting movie watches: %v", err)
+	}
+	watchPages := make(map[string]bool)
+	watchPagesByImdbId := make(map[string]string)
+	watchPagesByFileTitle := make(map[string][]string)
+	for ii := range watches {
+		watchPage := CreateMovieWatchPage(&watches[ii], vaultNames)
+		name := strings.TrimSuffix(MovieWatchPageFileName(watchPage), ".md")
+		watchPages[name] = true
+		watchPagesByImdbId[watchPage.Watched+" "+watchPage.ImdbId] = name
+		key := watchPage.Watched + " " + watchPage.FileTitle
+		watchPagesByFileTitle[key] = append(watchPagesByFileTitle[key], name)
+	}
+
+	stale := make([]StalePage, 0)
+	unknown := make([]StalePage, 0)
+	names, err := listPageNames(path.Join(vaultDir, "Movies"))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range names {
+		match := moviePageNameRegex.FindStringSubmatch(name)
+		if match == nil || moviePages[name] {
+			continue
+		}
+		// A new title keeps the IMDB ID, a fixed IMDB ID keeps the title.
+		replacement := moviePagesByImdbId[match[2]]
+		if replacement == "" && len(moviePagesByFileTitle[match[1]]) == 1 {
+			replacement = moviePagesByFileTitle[match[1]][0]
+		}
+		stale = append(stale, StalePage{
+			Dir: "Movies", Name: name, Replacement: replacement,
+		})
+	}
+
+	watchParser, err := CreateMovieWatchParser()
+	if err != nil {
+		return nil, nil, err
+	}
+	names, err = listPageNames(path.Join(vaultDir, "Watches"))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range names {
+		match := watchPageNameRegex.FindStringSubmatch(name)
+		if match == nil || watchPages[name] {
+			continue
+		}
+		// The page knows which movie it was for, if it still parses. If
+		// there's no watch of that movie that day it hasn't been ingested.
+		page, err := watchParser.ParsePage(
+			path.Join(vaultDir, "Watches", name+".md"),
+		)
+		if err == nil {
+			replacement := watchPagesByImdbId[match[1]+" "+page.ImdbId]
+			if replacement == "" {
+				unknown = append(unknown, StalePage{Dir: "Watches", Name: name})
+			} else {
+				stale = append(stale, StalePage{
+					Dir: "Watches", Name: name, Replacement: replacement,
+				})
+			}
+			continue
+		}
+		var replacement string
+		key := match[1] + " " + match[2]
+		if len(watchPagesByFileTitle[key]) == 1 {
+			replacement = watchPagesByFileTitle[key][0]
+		}
+		stale = append(stale, StalePage{
+			Dir: "Watches", Name: name, Replacement: replacement,
+		})
+	}
+	return stale, unknown, nil
+}
+
+// listPageNames returns the names of the markdown files in dir, sorted.
+func listPageNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading %v: %v", dir, err)
+	}
+	names := make([]string, 0, len(entries))
+	for ii := range entries {
+		name := entries[ii].Name()
+		if entries[ii].IsDir() || !strings.HasSuffix(name, ".md") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(name, ".md"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ArchiveStalePages moves the pages into the archive directory of the vault,
+// keeping the Movies and Watches split. Links to them without a replacement
+// still resolve, since Obsidian goes by the page name.
+func ArchiveStalePages(vaultDir string, pages []StalePage) error {
+	for ii := range pages {
+		archiveDir := path.Join(vaultDir, ARCHIVE_DIR, pages[ii].Dir)
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			return fmt.Errorf("error creating %v: %v", archiveDir, err)
+		}
+		fileName := pages[ii].Name + ".md"
+		from := path.Join(vaultDir, pages[ii].Dir, fileName)
+		to := path.Join(archiveDir, fileName)
+		if _, err := os.Stat(to); err == nil {
+			return fmt.Errorf("%v is already archived at %v", from, to)
+		}
+		if err := os.Rename(from, to); err != nil {
+			return fmt.Errorf("error moving %v to %v: %v", from, to, err)
+		}
+	}
+	return nil
+}
+
+// RewriteBacklinks points links to the stale pages at their replacements
+// everywhere in the vault outside ARCHIVE_DIR, keeping any alias or heading
+// on the link. It returns how many files it changed.
+func RewriteBacklinks(vaultDir string, pages []StalePage) (int, error) {
+	type rewrite struct {
+		link        *regexp.Regexp
+		name        string
+		replacement string
+	}
+	rewrites := make([]rewrite, 0)
+	for ii := range pages {
+		if pages[ii].Replacement == "" {
+			continue
+		}
+		rewrites = append(rewrites, rewrite{
+			link: regexp.MustCompile(
+				`\[\[` + regexp.QuoteMeta(pages[ii].Name) + `(\]\]|\||#)`,
+			),
+			name:        "[[" + pages[ii].Name,
+			replacement: "[[" + pages[ii].Replacement,
+		})
+	}
+	if len(rewrites) == 0 {
+		return 0, nil
+	}
+
+	changed := 0
+	err := filepath.WalkDir(vaultDir, func(
+		filePath string, entry fs.DirEntry, err error,
+	) error {
+		if err != nil {
+			return err
+		}
+		// Obsidian's settings and the like, and the archived pages, which
+		// keep their links as they were.
+		if entry.IsDir() && filePath != vaultDir &&
+			(strings.HasPrefix(entry.Name(), ".") ||
+				filePath == path.Join(vaultDir, ARCHIVE_DIR)) {
+			return filepath.SkipDir
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			return nil
+		}
+		contents, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("error reading %v: %v", filePath, err)
+		}
+		rewritten := string(contents)
+		for ii := range rewrites {
+			// Titles can have a $ in them, so no expanding the replacement.
+			rewritten = rewrites[ii].link.ReplaceAllStringFunc(
+				rewritten,
+				func(link string) string {
+					return rewrites[ii].replacement +
+						strings.TrimPrefix(link, rewrites[ii].name)
+				},
+			)
+		}
+		if rewritten == string(contents) {
+			return nil
+		}
+		if err := WriteFileAtomically(filePath, []byte(rewritten)); err != nil {
+			return err
+		}
+		changed++
+		return nil
+	})
+	if err != nil {
+		return changed, fmt.Errorf("error rewriting links: %v", err)
+	}
+	return changed, nil
+}
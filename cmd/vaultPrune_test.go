@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestPruneStalePages(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	vaultDir := t.TempDir()
+
+	tenebrae, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	suspiria := sampleMoviePage()
+	suspiria.Title = "Suspiria"
+	suspiria.ImdbLink = "https://www.imdb.com/title/tt0076786/"
+	suspiriaDetails, err := InsertMovieDetails(db, ctx, queries, suspiria, nil)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	for _, watch := range []struct {
+		movieUuid string
+		title     string
+		imdbId    string
+		watched   string
+	}{
+		{tenebrae.Movie, "Tenebrae", "tt0084777", "2022-05-27"},
+		{suspiriaDetails.Movie, "Suspiria", "tt0076786", "2022-10-31"},
+	} {
+		movieWatch := sampleMovieWatchPage()
+		movieWatch.Title = watch.title
+		movieWatch.ImdbId = watch.imdbId
+		movieWatch.Watched = watch.watched
+		if err := queries.InsertMovieWatch(
+			ctx, *CreateInsertMovieWatchParams(movieWatch, watch.movieUuid),
+		); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+
+	// A watch page from before the title was fixed, which still knows its
+	// movie.
+	movieWatchTemplate, err := template.New("movie_watch").Parse(
+		MOVIE_WATCH_TEMPLATE,
+	)
+	if err != nil {
+		t.Fatalf("Error parsing template: %v", err)
+	}
+	oldWatch := sampleMovieWatchPage()
+	oldWatch.Title = "Tenebre"
+	oldWatch.FileTitle = "Tenebre"
+	var oldWatchPage bytes.Buffer
+	if err := movieWatchTemplate.Execute(&oldWatchPage, oldWatch); err != nil {
+		t.Fatalf("Error executing template: %v", err)
+	}
+
+	// A watch page that hasn't been ingested yet.
+	newWatch := sampleMovieWatchPage()
+	newWatch.Watched = "2022-06-01"
+	var newWatchPage bytes.Buffer
+	if err := movieWatchTemplate.Execute(&newWatchPage, newWatch); err != nil {
+		t.Fatalf("Error executing template: %v", err)
+	}
+
+	pages := map[string]string{
+		"Movies/Tenebrae (tt0084777).md":                  "",
+		"Movies/Tenebrae (Director's Cut) (tt0084777).md": "",
+		"Movies/Inferno (tt0080923).md":                   "",
+		"Movies/Argento.md":                               "",
+		"Watches/2022-05-27 Tenebrae.md":                  "",
+		"Watches/2022-05-27 Tenebre.md":                   oldWatchPage.String(),
+		"Watches/2022-10-31 Suspiria.md":                  "",
+		"Watches/2021-10-31 Suspiria.md":                  "",
+		"Watches/2022-06-01 Tenebrae.md":                  newWatchPage.String(),
+		"Diary.md": "[[Tenebrae (Director's Cut) (tt0084777)|Tenebrae]] " +
+			"[[2022-05-27 Tenebre#Notes]] [[Inferno (tt0080923)]] " +
+			"[[Tenebrae (Director's Cut) (tt0084777) extra]]\n",
+		".obsidian/Templates.md": "[[2022-05-27 Tenebre]]\n",
+		"Archive/Diary 2021.md":  "[[2022-05-27 Tenebre]]\n",
+	}
+	for name, contents := range pages {
+		filePath := path.Join(vaultDir, name)
+		if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+		if err := os.WriteFile(filePath, []byte(contents), 0644); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+
+	stalePages, unknownPages, err := FindStalePages(ctx, queries, vaultDir)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	truth := []StalePage{
+		{
+			Dir:         "Movies",
+			Name:        "Inferno (tt0080923)",
+			Replacement: "",
+		},
+		{
+			Dir:         "Movies",
+			Name:        "Tenebrae (Director's Cut) (tt0084777)",
+			Replacement: "Tenebrae (tt0084777)",
+		},
+		{
+			Dir:         "Watches",
+			Name:        "2021-10-31 Suspiria",
+			Replacement: "",
+		},
+		{
+			Dir:         "Watches",
+			Name:        "2022-05-27 Tenebre",
+			Replacement: "2022-05-27 Tenebrae",
+		},
+	}
+	if !cmp.Equal(truth, stalePages) {
+		t.Errorf("Expected %v, got %v", truth, stalePages)
+	}
+	unknownTruth := []StalePage{{Dir: "Watches", Name: "2022-06-01 Tenebrae"}}
+	if !cmp.Equal(unknownTruth, unknownPages) {
+		t.Errorf("Expected %v, got %v", unknownTruth, unknownPages)
+	}
+
+	if err := ArchiveStalePages(vaultDir, stalePages); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	for ii := range stalePages {
+		fileName := stalePages[ii].Name + ".md"
+		if _, err := os.Stat(
+			path.Join(vaultDir, stalePages[ii].Dir, fileName),
+		); err == nil {
+			t.Errorf("Expected %v to be moved", fileName)
+		}
+		if _, err := os.Stat(
+			path.Join(vaultDir, ARCHIVE_DIR, stalePages[ii].Dir, fileName),
+		); err != nil {
+			t.Errorf("Expected %v to be archived: %v", fileName, err)
+		}
+	}
+
+	changed, err := RewriteBacklinks(vaultDir, stalePages)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("Expected 1 page changed, got %v", changed)
+	}
+	diary, err := os.ReadFile(path.Join(vaultDir, "Diary.md"))
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	diaryTruth := "[[Tenebrae (tt0084777)|Tenebrae]] " +
+		"[[2022-05-27 Tenebrae#Notes]] [[Inferno (tt0080923)]] " +
+		"[[Tenebrae (Director's Cut) (tt0084777) extra]]\n"
+	if diaryTruth != string(diary) {
+		t.Errorf("Expected \n%v, got \n%v", diaryTruth, string(diary))
+	}
+	settings, err := os.ReadFile(path.Join(vaultDir, ".obsidian/Templates.md"))
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if string(settings) != "[[2022-05-27 Tenebre]]\n" {
+		t.Errorf("Expected .obsidian to be left alone, got %v", string(settings))
+	}
+
+	archived, err := os.ReadFile(
+		path.Join(vaultDir, ARCHIVE_DIR, "Diary 2021.md"),
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if string(archived) != "[[2022-05-27 Tenebre]]\n" {
+		t.Errorf(
+			"Expected the archive to be left alone, got %v", string(archived),
+		)
+	}
+
+	// Once they're archived there's nothing left to prune, and the page
+	// that hasn't been ingested is still where it was.
+	stalePages, unknownPages, err = FindStalePages(ctx, queries, vaultDir)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(stalePages) != 0 {
+		t.Errorf("Expected no stale pages, got %v", stalePages)
+	}
+	if !cmp.Equal(unknownTruth, unknownPages) {
+		t.Errorf("Expected %v, got %v", unknownTruth, unknownPages)
+	}
+}
+
+func TestRewriteBacklinksDollarSign(t *testing.T) {
+	vaultDir := t.TempDir()
+	filePath := path.Join(vaultDir, "Diary.md")
+	diaryPage := "[[Cash (tt1092632)|Ca$h]] [[Cash (tt1092632)]]\n"
+	if err := os.WriteFile(filePath, []byte(diaryPage), 0644); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if _, err := RewriteBacklinks(vaultDir, []StalePage{
+		{
+			Dir:         "Movies",
+			Name:        "Cash (tt1092632)",
+			Replacement: "Ca$h (tt1092632)",
+		},
+	}); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	diary, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	truth := "[[Ca$h (tt1092632)|Ca$h]] [[Ca$h (tt1092632)]]\n"
+	if truth != string(diary) {
+		t.Errorf("Expected %v, got %v", truth, string(diary))
+	}
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// The pages the app writes and reads back can have their layout replaced
+// without recompiling, by putting a template with the same file name in the
+// vault's templates directory. Anything that isn't there falls back to the
+// built-in template. Since the app reads these pages back in, a template is
+// only used if a sample page written with it parses back the same.
+
+// Where the templates are in the vault unless VAULT_TEMPLATES_DIR says
+// otherwise.
+const DEFAULT_VAULT_TEMPLATES_DIR = "_templates/movies-app"
+
+// The templates directory, set with VAULT_TEMPLATES_DIR in the environment or
+// .env. Relative paths are relative to the vault.
+var VAULT_TEMPLATES_DIR string
+
+// Functions for the templates on top of the text/template built-ins.
+var VAULT_TEMPLATE_FUNCS = template.FuncMap{
+	// {{join ", " .Actors}}
+	"join": func(sep string, items []string) string {
+		return strings.Join(items, sep)
+	},
+	// {{wikilink .Title}} is [[Title]].
+	"wikilink": func(name string) string {
+		return "[[" + name + "]]"
+	},
+	// {{tagSlug "Science Fiction"}} is science-fiction.
+	"tagSlug": GenreSlug,
+	// {{date "January 2, 2006" .Watched}} reformats a YYYY-MM-DD date,
+	// leaving anything else as it is.
+	"date": func(layout string, value string) string {
+		date, err := time.Parse(WATCHED_DATE_LAYOUT, value)
+		if err != nil {
+			return value
+		}
+		return date.Format(layout)
+	},
+}
+
+// VaultTemplate is one page template and where it came from.
+type VaultTemplate struct {
+	// The file name in the templates directory.
+	FileName string
+	// The file it was loaded from, empty if it's the built-in one.
+	Source   string
+	Template *template.Template
+}
+
+func (t *VaultTemplate) String() string {
+	if t.Source == "" {
+		return fmt.Sprintf("%v: built-in", t.FileName)
+	}
+	return fmt.Sprintf("%v: %v", t.FileName, t.Source)
+}
+
+type VaultTemplates struct {
+	MovieWatch *VaultTemplate
+	Movie      *VaultTemplate
+	Review     *VaultTemplate
+}
+
+// VaultTemplatesDir is the templates directory for the vault.
+func VaultTemplatesDir(vaultDir string) string {
+	if VAULT_TEMPLATES_DIR == "" {
+		return path.Join(vaultDir, DEFAULT_VAULT_TEMPLATES_DIR)
+	}
+	if path.IsAbs(VAULT_TEMPLATES_DIR) {
+		return VAULT_TEMPLATES_DIR
+	}
+	return path.Join(vaultDir, VAULT_TEMPLATES_DIR)
+}
+
+// LoadVaultTemplates loads the page templates from the vault's templates
+// directory, falling back to the built-in ones. Every template loaded from
+// the vault is checked with ValidateVaultTemplate.
+func LoadVaultTemplates(vaultDir string) (*VaultTemplates, error) {
+	templatesDir := VaultTemplatesDir(vaultDir)
+	templates := VaultTemplates{}
+	var err error
+	templates.MovieWatch, err = loadVaultTemplate(
+		templatesDir, "movie_watch.md", MOVIE_WATCH_TEMPLATE,
+	)
+	if err != nil {
+		return nil, err
+	}
+	templates.Movie, err = loadVaultTemplate(
+		templatesDir, "movie.md", MOVIE_TEMPLATE,
+	)
+	if err != nil {
+		return nil, err
+	}
+	templates.Review, err = loadVaultTemplate(
+		templatesDir, "review.md", REVIEW_TEMPLATE,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vaultTemplate := range templates.All() {
+		if vaultTemplate.Source == "" {
+			continue
+		}
+		if err := ValidateVaultTemplate(vaultTemplate); err != nil {
+			return nil, err
+		}
+	}
+	return &templates, nil
+}
+
+// All is every template, in a fixed order.
+func (t *VaultTemplates) All() []*VaultTemplate {
+	return []*VaultTemplate{t.MovieWatch, t.Movie, t.Review}
+}
+
+func loadVaultTemplate(
+	templatesDir string, fileName string, builtIn string,
+) (*VaultTemplate, error) {
+	vaultTemplate := VaultTemplate{FileName: fileName}
+	text := builtIn
+	filePath := path.Join(templatesDir, fileName)
+	contents, err := os.ReadFile(filePath)
+	if err == nil {
+		vaultTemplate.Source = filePath
+		text = string(contents)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading template %v: %v", filePath, err)
+	}
+	vaultTemplate.Template, err = template.New(
+		strings.TrimSuffix(fileName, ".md"),
+	).Funcs(VAULT_TEMPLATE_FUNCS).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template %v: %v", fileName, err)
+	}
+	return &vaultTemplate, nil
+}
+
+// ValidateVaultTemplate writes a sample page with the template and parses
+// it back with the page's parser, returning an error naming every field that
+// didn't make the trip.
+func ValidateVaultTemplate(vaultTemplate *VaultTemplate) error {
+	var err error
+	switch vaultTemplate.FileName {
+	case "movie_watch.md":
+		var parser *MovieWatchParser
+		if parser, err = CreateMovieWatchParser(); err == nil {
+			err = checkRoundTrip(
+				vaultTemplate.Template, SampleMovieWatchPage(), parser.ParsePage,
+			)
+		}
+	case "movie.md":
+		var parser *MovieParser
+		if parser, err = CreateMovieParser(); err == nil {
+			err = checkRoundTrip(
+				vaultTemplate.Template, SampleMoviePage(), parser.ParsePage,
+			)
+		}
+	case "review.md":
+		var parser *MovieReviewParser
+		if parser, err = CreateMovieReviewParser(); err == nil {
+			err = checkRoundTrip(
+				vaultTemplate.Template, SampleMovieReviewPage(),
+				parser.ParseMovieReviewPage,
+			)
+		}
+	default:
+		err = fmt.Errorf("no parser for this template")
+	}
+	if err != nil {
+		return fmt.Errorf("template %v: %v", vaultTemplate, err)
+	}
+	return nil
+}
+
+// RenderSample is the sample page written with the template.
+func RenderSample(vaultTemplate *VaultTemplate) (string, error) {
+	var page interface{}
+	switch vaultTemplate.FileName {
+	case "movie_watch.md":
+		page = SampleMovieWatchPage()
+	case "movie.md":
+		page = SampleMoviePage()
+	case "review.md":
+		page = SampleMovieReviewPage()
+	default:
+		return "", fmt.Errorf("no sample for %v", vaultTemplate.FileName)
+	}
+	rendered, err := renderPage(vaultTemplate.Template, page)
+	if err != nil {
+		return "", fmt.Errorf("error rendering %v: %v", vaultTemplate, err)
+	}
+	return string(rendered), nil
+}
+
+func checkRoundTrip[T any](
+	pageTemplate *template.Template, page *T, parse func(string) (*T, error),
+) error {
+	rendered, err := renderPage(pageTemplate, page)
+	if err != nil {
+		return fmt.Errorf("error rendering sample: %v", err)
+	}
+	dir, err := os.MkdirTemp("", "movies-app-template")
+	if err != nil {
+		return fmt.Errorf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := path.Join(dir, "sample.md")
+	if err := os.WriteFile(fileName, rendered, 0644); err != nil {
+		return fmt.Errorf("error writing sample: %v", err)
+	}
+	parsed, err := parse(fileName)
+	if err != nil {
+		return fmt.Errorf("sample doesn't parse: %v", err)
+	}
+
+	// Compared as they'd be printed, so times in different locations that
+	// are the same instant and offset come out equal.
+	var differences []string
+	want := reflect.ValueOf(page).Elem()
+	got := reflect.ValueOf(parsed).Elem()
+	for ii := 0; ii < want.NumField(); ii++ {
+		wantField := fmt.Sprint(want.Field(ii).Interface())
+		gotField := fmt.Sprint(got.Field(ii).Interface())
+		if wantField != gotField {
+			differences = append(differences, fmt.Sprintf(
+				"%v was %q, read back %q",
+				want.Type().Field(ii).Name, wantField, gotField,
+			))
+		}
+	}
+	if len(differences) > 0 {
+		return fmt.Errorf(
+			"sample doesn't read back the same: %v",
+			strings.Join(differences, "; "),
+		)
+	}
+	return nil
+}
+
+// SampleMovieWatchPage is a watch with every field filled in.
+func SampleMovieWatchPage() *MovieWatchPage {
+	watchedAt, err := NewWatchedAt("2022-10-31T21:30:00-05:00", "")
+	if err != nil {
+		// It's a constant, so this is a bug.
+		panic(err)
+	}
+	return &MovieWatchPage{
+		Title:       "Tetsuo: The Iron Man",
+		FileTitle:   cleanTitle("Tetsuo: The Iron Man"),
+		Watched:     "2022-10-31",
+		ImdbLink:    "https://www.imdb.com/title/tt0096251/",
+		ImdbId:      "tt0096251",
+		FirstTime:   true,
+		JoeBob:      true,
+		CallFelissa: true,
+		Beast:       true,
+		Godzilla:    true,
+		Zombies:     true,
+		Slasher:     true,
+		WallpaperFu: true,
+		Service:     "Criterion Channel",
+		Notes:       "Metal.\n\nMore metal.",
+		WatchedAt:   watchedAt,
+		Rating:      4.5,
+	}
+}
+
+// SampleMoviePage is a movie with every field on the page filled in.
+func SampleMoviePage() *MoviePage {
+	return &MoviePage{
+		Title:          "Tetsuo: The Iron Man",
+		ImdbLink:       "https://www.imdb.com/title/tt0096251/",
+		Genres:         []string{"Horror", "Science Fiction"},
+		Directors:      []string{"Shin'ya Tsukamoto"},
+		Actors:         []string{"Tomorowo Taguchi", "Kei Fujiwara"},
+		Writers:        []string{"Shin'ya Tsukamoto"},
+		Year:           1989,
+		RuntimeMinutes: 67,
+		Rating:         "Not Rated",
+		Released:       "1989-07-01",
+		Plot:           "A man turns into metal.",
+		Country:        "Japan",
+		Language:       "Japanese",
+		BoxOffice:      "N/A",
+		Production:     "Kaiju Theatre",
+		CallFelissa:    true,
+		Slasher:        true,
+		Zombies:        true,
+		Beast:          true,
+		Godzilla:       true,
+		WallpaperFu:    true,
+		Reviews:        []string{"Tetsuo The Iron Man (tt0096251) Review"},
+	}
+}
+
+// SampleMovieReviewPage is a review of a watch with every field filled in.
+func SampleMovieReviewPage() *MovieReviewPage {
+	return &MovieReviewPage{
+		Uuid:       "2b7e0c7a-0d4f-4f5e-8f0a-3c1d2e4f5a6b",
+		MovieTitle: cleanTitle("Tetsuo: The Iron Man"),
+		ImdbId:     "tt0096251",
+		Watched:    "2022-10-31",
+		Liked:      true,
+		Review:     "Metal.\n\nMore metal.",
+		Rating:     4.5,
+	}
+}
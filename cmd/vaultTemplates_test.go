@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestVaultTemplateFuncs(t *testing.T) {
+	tests := []struct {
+		template string
+		truth    string
+	}{
+		{`{{join ", " .Actors}}`, "Tomorowo Taguchi, Kei Fujiwara"},
+		{`{{wikilink .Title}}`, "[[Tetsuo: The Iron Man]]"},
+		{`{{range .Genres}}#{{tagSlug .}} {{end}}`, "#horror #science-fiction "},
+		{`{{date "January 2, 2006" .Released}}`, "July 1, 1989"},
+		{`{{date "January 2, 2006" .Rating}}`, "Not Rated"},
+	}
+	for _, test := range tests {
+		parsed, err := template.New("test").Funcs(VAULT_TEMPLATE_FUNCS).Parse(
+			test.template,
+		)
+		if err != nil {
+			t.Fatalf("Error parsing %v: %v", test.template, err)
+		}
+		var answer bytes.Buffer
+		if err := parsed.Execute(&answer, SampleMoviePage()); err != nil {
+			t.Fatalf("Error executing %v: %v", test.template, err)
+		}
+		if answer.String() != test.truth {
+			t.Errorf(
+				"Expected %q for %v, got %q",
+				test.truth, test.template, answer.String(),
+			)
+		}
+	}
+}
+
+func TestValidateBuiltInTemplates(t *testing.T) {
+	templates, err := LoadVaultTemplates(t.TempDir())
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	for _, vaultTemplate := range templates.All() {
+		if vaultTemplate.Source != "" {
+			t.Errorf("Expected %v to be built-in", vaultTemplate)
+		}
+		if err := ValidateVaultTemplate(vaultTemplate); err != nil {
+			t.Errorf("Encountered error: %v", err)
+		}
+	}
+}
+
+func TestLoadVaultTemplates(t *testing.T) {
+	defer func(templatesDir string) { VAULT_TEMPLATES_DIR = templatesDir }(
+		VAULT_TEMPLATES_DIR,
+	)
+	VAULT_TEMPLATES_DIR = ""
+	vaultDir := t.TempDir()
+	templatesDir := path.Join(vaultDir, DEFAULT_VAULT_TEMPLATES_DIR)
+	if err := os.MkdirAll(templatesDir, 0755); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	// Extra lines are fine as long as the data still parses.
+	movieTemplate := strings.Replace(
+		MOVIE_TEMPLATE,
+		"## Data\n",
+		"Released {{date \"January 2, 2006\" .Released}}.\n## Data\n",
+		1,
+	)
+	if err := os.WriteFile(
+		path.Join(templatesDir, "movie.md"), []byte(movieTemplate), 0644,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	templates, err := LoadVaultTemplates(vaultDir)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if templates.Movie.Source != path.Join(templatesDir, "movie.md") {
+		t.Errorf("Expected movie.md from the vault, got %v", templates.Movie)
+	}
+	if templates.MovieWatch.Source != "" || templates.Review.Source != "" {
+		t.Errorf("Expected the rest to be built-in, got %v", templates.All())
+	}
+	sample, err := RenderSample(templates.Movie)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if !strings.Contains(sample, "Released July 1, 1989.") {
+		t.Errorf("Expected the vault's template, got \n%v", sample)
+	}
+
+	// Dropping a field isn't.
+	movieWatchTemplate := strings.Replace(
+		MOVIE_WATCH_TEMPLATE, "joe_bob:: {{.JoeBob}}\n", "", 1,
+	)
+	if err := os.WriteFile(
+		path.Join(templatesDir, "movie_watch.md"),
+		[]byte(movieWatchTemplate),
+		0644,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	_, err = LoadVaultTemplates(vaultDir)
+	if err == nil || !strings.Contains(err.Error(), "JoeBob") {
+		t.Errorf("Expected an error about JoeBob, got %v", err)
+	}
+
+	// Nor is one that doesn't parse as a template.
+	if err := os.WriteFile(
+		path.Join(templatesDir, "movie_watch.md"), []byte("{{.Title"), 0644,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if _, err := LoadVaultTemplates(vaultDir); err == nil {
+		t.Error("Expected an error for a broken template")
+	}
+
+	// Somewhere else in the vault.
+	VAULT_TEMPLATES_DIR = "Templates"
+	if VaultTemplatesDir(vaultDir) != path.Join(vaultDir, "Templates") {
+		t.Errorf("Expected Templates in the vault, got %v", VaultTemplatesDir(vaultDir))
+	}
+	templates, err = LoadVaultTemplates(vaultDir)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if templates.Movie.Source != "" {
+		t.Errorf("Expected the built-in movie template, got %v", templates.Movie)
+	}
+}
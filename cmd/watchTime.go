@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Watch dates and times all go through here. watched is the calendar date
+// of the watch in wherever it happened, and watched_at optionally pins down
+// the moment with the timezone it happened in. Converting between the two
+// anywhere else is how we ended up with the fix-watched-times and
+// fix-watch-dates-again migrations.
+
+const WATCHED_DATE_LAYOUT = "2006-01-02"
+const WATCHED_MONTH_LAYOUT = "2006-01"
+
+// Like 2022-10-31T21:30:00-05:00[America/Chicago], with the zone optional.
+var watchedAtRegex = regexp.MustCompile(`^([^\[\]\s]+)(?:\[([^\[\]\s]+)\])?$`)
+
+type WatchedAt struct {
+	// In the watch's timezone, or with the offset it was written with if
+	// there's no timezone.
+	Time time.Time
+	// The IANA name, empty if only the offset is known.
+	Timezone string
+}
+
+// ParseWatchedAt parses the watched_at:: field on a watch page, an RFC 3339
+// timestamp optionally followed by an IANA timezone in brackets.
+func ParseWatchedAt(text string) (*WatchedAt, error) {
+	match := watchedAtRegex.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return nil, fmt.Errorf(
+			"expected watched_at like "+
+				"2022-10-31T21:30:00-05:00[America/Chicago], got %v",
+			text,
+		)
+	}
+	return NewWatchedAt(match[1], match[2])
+}
+
+// NewWatchedAt builds the watch time from the timestamp and timezone as
+// they're stored in movie_watch.
+func NewWatchedAt(timestamp string, timezone string) (*WatchedAt, error) {
+	parsed, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing timestamp %v: %v", timestamp, err)
+	}
+	if timezone == "" {
+		return &WatchedAt{Time: parsed}, nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %v: %v", timezone, err)
+	}
+	local := parsed.In(location)
+	// If these disagree it's anyone's guess which local date was meant.
+	_, localOffset := local.Zone()
+	_, parsedOffset := parsed.Zone()
+	if localOffset != parsedOffset {
+		return nil, fmt.Errorf(
+			"the offset in %v isn't %v's offset at that time",
+			timestamp, timezone,
+		)
+	}
+	return &WatchedAt{Time: local, Timezone: timezone}, nil
+}
+
+// Timestamp is the RFC 3339 timestamp with the local offset.
+func (w *WatchedAt) Timestamp() string {
+	return w.Time.Format(time.RFC3339)
+}
+
+// String is the watched_at:: field for the watch page.
+func (w *WatchedAt) String() string {
+	if w.Timezone == "" {
+		return w.Timestamp()
+	}
+	return fmt.Sprintf("%v[%v]", w.Timestamp(), w.Timezone)
+}
+
+// Date is the local date of the watch, which is what goes in watched.
+func (w *WatchedAt) Date() string {
+	return w.Time.Format(WATCHED_DATE_LAYOUT)
+}
+
+// ParseWatchedDate parses a watched date. It's a calendar date rather than
+// an instant, so it comes back as midnight UTC where adding days can't trip
+// over daylight saving.
+func ParseWatchedDate(watched string) (time.Time, error) {
+	date, err := time.Parse(WATCHED_DATE_LAYOUT, watched)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(
+			"expected watched as YYYY-MM-DD, got %v", watched,
+		)
+	}
+	return date, nil
+}
+
+// ParseWatchedMonth parses a YYYY-MM month the same way.
+func ParseWatchedMonth(month string) (time.Time, error) {
+	date, err := time.Parse(WATCHED_MONTH_LAYOUT, month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected month as YYYY-MM, got %v", month)
+	}
+	return date, nil
+}
+
+// WatchedToday is today's date in the timezone, or in the local one if it's
+// empty.
+func WatchedToday(timezone string) (string, error) {
+	location := time.Local
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return "", fmt.Errorf("unknown timezone %v: %v", timezone, err)
+		}
+	}
+	return time.Now().In(location).Format(WATCHED_DATE_LAYOUT), nil
+}
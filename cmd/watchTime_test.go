@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"os"
+	"path"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseWatchedAt(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatalf("Error loading timezone: %v", err)
+	}
+	tests := []struct {
+		text  string
+		truth WatchedAt
+		date  string
+	}{
+		{
+			text: "2022-10-31T23:30:00-05:00[America/Chicago]",
+			truth: WatchedAt{
+				Time:     time.Date(2022, 10, 31, 23, 30, 0, 0, chicago),
+				Timezone: "America/Chicago",
+			},
+			date: "2022-10-31",
+		},
+		{
+			// Standard time, and a UTC date that's the next day.
+			text: "2022-12-31T21:00:00-06:00[America/Chicago]",
+			truth: WatchedAt{
+				Time:     time.Date(2022, 12, 31, 21, 0, 0, 0, chicago),
+				Timezone: "America/Chicago",
+			},
+			date: "2022-12-31",
+		},
+		{
+			text: "2022-10-31T23:30:00-05:00",
+			truth: WatchedAt{
+				Time: time.Date(
+					2022, 10, 31, 23, 30, 0, 0, time.FixedZone("", -5*60*60),
+				),
+			},
+			date: "2022-10-31",
+		},
+	}
+	for _, test := range tests {
+		answer, err := ParseWatchedAt(test.text)
+		if err != nil {
+			t.Errorf("Error parsing %v: %v", test.text, err)
+			continue
+		}
+		if !cmp.Equal(test.truth, *answer) {
+			t.Errorf("Expected %v, got %v", test.truth, *answer)
+		}
+		if answer.Date() != test.date {
+			t.Errorf("Expected date %v, got %v", test.date, answer.Date())
+		}
+		if answer.String() != test.text {
+			t.Errorf("Expected %v, got %v", test.text, answer.String())
+		}
+	}
+
+	for _, text := range []string{
+		"2022-10-31",
+		"2022-10-31T23:30:00",
+		"2022-10-31T23:30:00-05:00[Not/AZone]",
+		// Chicago is on standard time in December.
+		"2022-12-31T21:00:00-05:00[America/Chicago]",
+	} {
+		if _, err := ParseWatchedAt(text); err == nil {
+			t.Errorf("Expected an error parsing %v", text)
+		}
+	}
+}
+
+func TestParseWatchPageWatchedAt(t *testing.T) {
+	dir := t.TempDir()
+	watchedAt, err := ParseWatchedAt(
+		"2022-10-31T23:30:00-05:00[America/Chicago]",
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	truth := sampleMovieWatchPage()
+	truth.Watched = "2022-10-31"
+	truth.WatchedAt = watchedAt
+
+	movieWatchTemplate, err := template.New("movie_watch").Parse(
+		MOVIE_WATCH_TEMPLATE,
+	)
+	if err != nil {
+		t.Fatalf("Error parsing template: %v", err)
+	}
+	fileName := path.Join(dir, "watch.md")
+	file, err := os.Create(fileName)
+	if err != nil {
+		t.Fatalf("Error creating file: %v", err)
+	}
+	if err := movieWatchTemplate.Execute(file, truth); err != nil {
+		t.Fatalf("Error executing template: %v", err)
+	}
+	file.Close()
+
+	parser, err := CreateMovieWatchParser()
+	if err != nil {
+		t.Fatalf("Error creating parser: %v", err)
+	}
+	answer, err := parser.ParsePage(fileName)
+	if err != nil {
+		t.Fatalf("Error parsing page: %v", err)
+	}
+	if !cmp.Equal(truth.WatchedAt, answer.WatchedAt) ||
+		answer.Watched != truth.Watched {
+		t.Errorf(
+			"Expected %v %v, got %v %v",
+			truth.Watched, truth.WatchedAt, answer.Watched, answer.WatchedAt,
+		)
+	}
+
+	// A watched date that disagrees with watched_at is an error.
+	truth.Watched = "2022-11-01"
+	file, err = os.Create(fileName)
+	if err != nil {
+		t.Fatalf("Error creating file: %v", err)
+	}
+	if err := movieWatchTemplate.Execute(file, truth); err != nil {
+		t.Fatalf("Error executing template: %v", err)
+	}
+	file.Close()
+	if _, err := parser.ParsePage(fileName); err == nil {
+		t.Errorf("Expected an error for mismatched watched and watched_at")
+	}
+}
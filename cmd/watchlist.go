@@ -0,0 +1,460 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math/rand"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+const WATCHLIST_PAGE = "Watchlist.md"
+
+const MIN_WATCHLIST_PRIORITY = 1
+const MAX_WATCHLIST_PRIORITY = 5
+const DEFAULT_WATCHLIST_PRIORITY = 3
+
+var WATCHLIST_TEMPLATE = `
+# Watchlist
+
+## Data
+movies:: {{len .Items}}
+{{range .Priorities}}
+## Priority {{.Priority}}
+{{range .Items}}- [[{{.FileTitle}} ({{.ImdbId}})|{{.Title}} ({{.Year}})]] added [[{{.Added}}]]{{with .Source}}, {{.}}{{end}}
+{{end}}{{end}}
+## Tags
+#watchlist
+
+## Notes
+{{.Notes}}`
+
+var watchlistCmd = &cobra.Command{
+	Use:   "watchlist",
+	Short: "Keeps track of movies to watch.",
+	Long: `Keeps track of movies to watch.
+
+Movies come off the watchlist on their own when update-recent-movies pulls in
+a watch of them. With --vault the Watchlist.md page is rewritten on every
+change.`,
+}
+
+var watchlistAddCmd = &cobra.Command{
+	Use:   "add <title or imdb id>",
+	Short: "Adds a movie to the watchlist, or updates it if it's there.",
+	Run:   watchlistAdd,
+	Args:  cobra.ExactArgs(1),
+}
+
+var watchlistRemoveCmd = &cobra.Command{
+	Use:   "remove <title or imdb id>",
+	Short: "Removes a movie from the watchlist.",
+	Run:   watchlistRemove,
+	Args:  cobra.ExactArgs(1),
+}
+
+var watchlistListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the watchlist, highest priority first.",
+	Run:   watchlistList,
+	Args:  cobra.NoArgs,
+}
+
+var watchlistPickCmd = &cobra.Command{
+	Use:   "pick",
+	Short: "Picks a movie off the watchlist, weighted by priority.",
+	Run:   watchlistPick,
+	Args:  cobra.NoArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(watchlistCmd)
+	watchlistCmd.AddCommand(watchlistAddCmd)
+	watchlistCmd.AddCommand(watchlistRemoveCmd)
+	watchlistCmd.AddCommand(watchlistListCmd)
+	watchlistCmd.AddCommand(watchlistPickCmd)
+
+	watchlistCmd.PersistentFlags().StringP(
+		"vault", "v", "", "The vault to write Watchlist.md into.",
+	)
+	watchlistAddCmd.Flags().StringP(
+		"year", "y", "", "The release year, to narrow down the search.",
+	)
+	watchlistAddCmd.Flags().IntP(
+		"priority", "p", DEFAULT_WATCHLIST_PRIORITY,
+		fmt.Sprintf(
+			"How much I want to see it, from %v to %v.",
+			MIN_WATCHLIST_PRIORITY, MAX_WATCHLIST_PRIORITY,
+		),
+	)
+	watchlistAddCmd.Flags().StringP(
+		"source", "s", "", "Where it came from, like \"recommended by Felissa\".",
+	)
+}
+
+type WatchlistPageItem struct {
+	Title     string
+	FileTitle string
+	ImdbId    string
+	Year      string
+	Added     string
+	Source    string
+}
+
+type WatchlistPagePriority struct {
+	Priority int64
+	Items    []WatchlistPageItem
+}
+
+type WatchlistPage struct {
+	Items      []WatchlistPageItem
+	Priorities []WatchlistPagePriority
+	Notes      string
+}
+
+// CreateWatchlistPage expects the items in the order GetWatchlist returns
+// them, highest priority first. Links go by vaultNames for movies that are
+// already in the database.
+func CreateWatchlistPage(
+	items []database.Watchlist, vaultNames VaultNames,
+) *WatchlistPage {
+	page := WatchlistPage{Items: make([]WatchlistPageItem, len(items))}
+	for ii := range items {
+		page.Items[ii] = WatchlistPageItem{
+			Title:     items[ii].Title,
+			FileTitle: vaultNames.FileTitle(items[ii].ImdbID, items[ii].Title),
+			ImdbId:    items[ii].ImdbID,
+			Year:      items[ii].Year,
+			Added:     items[ii].Added,
+			Source:    items[ii].Source.String,
+		}
+		if len(page.Priorities) == 0 ||
+			page.Priorities[len(page.Priorities)-1].Priority != items[ii].Priority {
+			page.Priorities = append(
+				page.Priorities,
+				WatchlistPagePriority{Priority: items[ii].Priority},
+			)
+		}
+		priority := &page.Priorities[len(page.Priorities)-1]
+		priority.Items = append(priority.Items, page.Items[ii])
+	}
+	return &page
+}
+
+// WriteWatchlistPage rewrites Watchlist.md in the vault, keeping the notes.
+func WriteWatchlistPage(
+	ctx context.Context, queries *database.Queries, vaultDir string,
+) error {
+	watchlistTemplate, err := template.New("watchlist").Parse(WATCHLIST_TEMPLATE)
+	if err != nil {
+		return fmt.Errorf("unable to parse watchlist template: %v", err)
+	}
+	items, err := queries.GetWatchlist(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting watchlist: %v", err)
+	}
+	vaultNames, err := LoadVaultNames(ctx, queries)
+	if err != nil {
+		return err
+	}
+	page := CreateWatchlistPage(items, vaultNames)
+
+	filePath := path.Join(vaultDir, WATCHLIST_PAGE)
+	if page.Notes, err = ReadPreservedNotes(filePath); err != nil {
+		return fmt.Errorf("error reading notes for the watchlist: %v", err)
+	}
+	return WritePage(watchlistTemplate, filePath, page)
+}
+
+// RemoveWatchedFromWatchlist takes the movie off the watchlist now that
+// it's been watched, and reports whether it was on it.
+func RemoveWatchedFromWatchlist(
+	ctx context.Context, queries *database.Queries, imdbId string,
+) (bool, error) {
+	removed, err := queries.DeleteWatchlistItem(ctx, imdbId)
+	if err != nil {
+		return false, fmt.Errorf(
+			"error removing %v from the watchlist: %v", imdbId, err,
+		)
+	}
+	return removed > 0, nil
+}
+
+func describeSearchResults(results []OmdbSearchResult) string {
+	descriptions := make([]string, len(results))
+	for ii := range results {
+		descriptions[ii] = fmt.Sprintf(
+			"%v (%v) %v", results[ii].Title, results[ii].Year, results[ii].ImdbID,
+		)
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// ResolveWatchlistMovie finds the movie for an IMDB ID or a title. A title
+// has to pick out exactly one movie, either as the only search result or as
+// the only exact title match.
+func ResolveWatchlistMovie(
+	omdbClient *OmdbClient, query string, year string,
+) (*OmdbSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if imdbIdRegex.MatchString(query) {
+		movie, err := omdbClient.GetMovie(query)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v from OMDB: %v", query, err)
+		}
+		if movie.Response == "False" {
+			return nil, fmt.Errorf("no movie found for %v", query)
+		}
+		return &OmdbSearchResult{
+			Title:  movie.Title,
+			Year:   movie.Year,
+			ImdbID: movie.ImdbID,
+			Type:   movie.Type,
+			Poster: movie.Poster,
+		}, nil
+	}
+
+	results, err := omdbClient.SearchMovies(query, year)
+	if err != nil {
+		return nil, err
+	}
+	if len(results.Search) == 0 {
+		return nil, fmt.Errorf("no movies found for %v", query)
+	}
+	if len(results.Search) == 1 {
+		return &results.Search[0], nil
+	}
+	exact := make([]OmdbSearchResult, 0)
+	for ii := range results.Search {
+		if strings.EqualFold(results.Search[ii].Title, query) {
+			exact = append(exact, results.Search[ii])
+		}
+	}
+	if len(exact) == 1 {
+		return &exact[0], nil
+	}
+	candidates := exact
+	if len(candidates) == 0 {
+		candidates = results.Search
+	}
+	return nil, fmt.Errorf(
+		"%v matches more than one movie, use the year or IMDB ID: %v",
+		query, describeSearchResults(candidates),
+	)
+}
+
+func CreateInsertWatchlistItemParams(
+	movie *OmdbSearchResult, priority int64, source string, added string,
+) *database.InsertWatchlistItemParams {
+	return &database.InsertWatchlistItemParams{
+		Uuid:     uuid.New().String(),
+		ImdbID:   movie.ImdbID,
+		Title:    movie.Title,
+		Year:     movie.Year,
+		Priority: priority,
+		Source:   textToNullString(source),
+		Added:    added,
+	}
+}
+
+// FindWatchlistItem looks an item up by IMDB ID or by its title, which has
+// to be unique on the watchlist.
+func FindWatchlistItem(
+	ctx context.Context, queries *database.Queries, query string,
+) (*database.Watchlist, error) {
+	query = strings.TrimSpace(query)
+	if imdbIdRegex.MatchString(query) {
+		item, err := queries.FindWatchlistItem(ctx, query)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%v isn't on the watchlist", query)
+		} else if err != nil {
+			return nil, fmt.Errorf("error finding %v: %v", query, err)
+		}
+		return &item, nil
+	}
+	items, err := queries.FindWatchlistItemsByTitle(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error finding %v: %v", query, err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%v isn't on the watchlist", query)
+	}
+	if len(items) > 1 {
+		imdbIds := make([]string, len(items))
+		for ii := range items {
+			imdbIds[ii] = fmt.Sprintf("%v (%v)", items[ii].ImdbID, items[ii].Year)
+		}
+		return nil, fmt.Errorf(
+			"more than one %v on the watchlist, use the IMDB ID: %v",
+			query, strings.Join(imdbIds, ", "),
+		)
+	}
+	return &items[0], nil
+}
+
+// PickWatchlistItem picks an item at random with odds proportional to its
+// priority. It returns nil for an empty watchlist.
+func PickWatchlistItem(
+	items []database.Watchlist, random *rand.Rand,
+) *database.Watchlist {
+	var total int64
+	for ii := range items {
+		total += watchlistWeight(&items[ii])
+	}
+	if total == 0 {
+		return nil
+	}
+	pick := random.Int63n(total)
+	for ii := range items {
+		pick -= watchlistWeight(&items[ii])
+		if pick < 0 {
+			return &items[ii]
+		}
+	}
+	return nil
+}
+
+func watchlistWeight(item *database.Watchlist) int64 {
+	if item.Priority < MIN_WATCHLIST_PRIORITY {
+		return MIN_WATCHLIST_PRIORITY
+	}
+	return item.Priority
+}
+
+func describeWatchlistItem(item *database.Watchlist) string {
+	description := fmt.Sprintf(
+		"%v (%v) %v priority %v, added %v",
+		item.Title, item.Year, item.ImdbID, item.Priority, item.Added,
+	)
+	if item.Source.Valid {
+		description += ", " + item.Source.String
+	}
+	return description
+}
+
+// openWatchlist opens the database and gets the vault flag for the
+// watchlist commands.
+func openWatchlist(cmd *cobra.Command) (*sql.DB, *database.Queries, string) {
+	vaultDir, err := cmd.Flags().GetString("vault")
+	if err != nil {
+		log.Panicf("Error obtaining vault: %v", err)
+	}
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	return db, database.New(db), vaultDir
+}
+
+func writeWatchlistPageIfVault(
+	ctx context.Context, queries *database.Queries, vaultDir string,
+) {
+	if vaultDir == "" {
+		return
+	}
+	if err := WriteWatchlistPage(ctx, queries, vaultDir); err != nil {
+		log.Panicf("Error writing watchlist page: %v", err)
+	}
+}
+
+func watchlistAdd(cmd *cobra.Command, args []string) {
+	year, err := cmd.Flags().GetString("year")
+	if err != nil {
+		log.Panicf("Error obtaining year: %v", err)
+	}
+	priority, err := cmd.Flags().GetInt("priority")
+	if err != nil {
+		log.Panicf("Error obtaining priority: %v", err)
+	}
+	if priority < MIN_WATCHLIST_PRIORITY || priority > MAX_WATCHLIST_PRIORITY {
+		log.Panicf(
+			"priority must be between %v and %v, got %v",
+			MIN_WATCHLIST_PRIORITY, MAX_WATCHLIST_PRIORITY, priority,
+		)
+	}
+	source, err := cmd.Flags().GetString("source")
+	if err != nil {
+		log.Panicf("Error obtaining source: %v", err)
+	}
+	if OMDB_KEY == "" {
+		log.Panic("OMDB_KEY must be present to add to the watchlist.")
+	}
+
+	ctx := context.Background()
+	db, queries, vaultDir := openWatchlist(cmd)
+	defer db.Close()
+
+	movie, err := ResolveWatchlistMovie(NewOmdbClient(OMDB_KEY), args[0], year)
+	if err != nil {
+		log.Panicf("Error finding movie: %v", err)
+	}
+	added, err := WatchedToday("")
+	if err != nil {
+		log.Panicf("Error getting today's date: %v", err)
+	}
+	if err := queries.InsertWatchlistItem(
+		ctx,
+		*CreateInsertWatchlistItemParams(movie, int64(priority), source, added),
+	); err != nil {
+		log.Panicf("Error adding %v to the watchlist: %v", movie.Title, err)
+	}
+	log.Printf(
+		"Added %v (%v) %v to the watchlist.", movie.Title, movie.Year, movie.ImdbID,
+	)
+	writeWatchlistPageIfVault(ctx, queries, vaultDir)
+}
+
+func watchlistRemove(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	db, queries, vaultDir := openWatchlist(cmd)
+	defer db.Close()
+
+	item, err := FindWatchlistItem(ctx, queries, args[0])
+	if err != nil {
+		log.Panicf("Error finding watchlist item: %v", err)
+	}
+	if _, err := queries.DeleteWatchlistItem(ctx, item.ImdbID); err != nil {
+		log.Panicf("Error removing %v from the watchlist: %v", item.Title, err)
+	}
+	log.Printf("Removed %v (%v) from the watchlist.", item.Title, item.Year)
+	writeWatchlistPageIfVault(ctx, queries, vaultDir)
+}
+
+func watchlistList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	db, queries, vaultDir := openWatchlist(cmd)
+	defer db.Close()
+
+	items, err := queries.GetWatchlist(ctx)
+	if err != nil {
+		log.Panicf("Error getting watchlist: %v", err)
+	}
+	for ii := range items {
+		fmt.Println(describeWatchlistItem(&items[ii]))
+	}
+	writeWatchlistPageIfVault(ctx, queries, vaultDir)
+}
+
+func watchlistPick(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	db, queries, _ := openWatchlist(cmd)
+	defer db.Close()
+
+	items, err := queries.GetWatchlist(ctx)
+	if err != nil {
+		log.Panicf("Error getting watchlist: %v", err)
+	}
+	item := PickWatchlistItem(items, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if item == nil {
+		log.Panic("The watchlist is empty.")
+	}
+	fmt.Println(describeWatchlistItem(item))
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestResolveWatchlistMovie(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	client := OmdbClient{
+		client:  http.Client{},
+		key:     "abc123",
+		rootUrl: "http://omdbapi.com",
+	}
+	httpmock.RegisterResponder(
+		"GET", `http://omdbapi.com/?apikey=abc123&s=tenebrae&type=movie`,
+		httpmock.NewStringResponder(200, `{
+			"Search": [
+				{"Title": "Tenebrae", "Year": "1982", "imdbID": "tt0084777", "Type": "movie", "Poster": "N/A"},
+				{"Title": "Tenebrae: Behind the Scenes", "Year": "2011", "imdbID": "tt2000000", "Type": "movie", "Poster": "N/A"}
+			],
+			"totalResults": "2",
+			"Response": "True"
+		}`),
+	)
+	httpmock.RegisterResponder(
+		"GET", `http://omdbapi.com/?apikey=abc123&s=The+Thing&type=movie`,
+		httpmock.NewStringResponder(200, `{
+			"Search": [
+				{"Title": "The Thing", "Year": "1982", "imdbID": "tt0084787", "Type": "movie", "Poster": "N/A"},
+				{"Title": "The Thing", "Year": "2011", "imdbID": "tt0905372", "Type": "movie", "Poster": "N/A"}
+			],
+			"totalResults": "2",
+			"Response": "True"
+		}`),
+	)
+	httpmock.RegisterResponder(
+		"GET", `http://omdbapi.com/?apikey=abc123&s=The+Thing&type=movie&y=1982`,
+		httpmock.NewStringResponder(200, `{
+			"Search": [
+				{"Title": "The Thing", "Year": "1982", "imdbID": "tt0084787", "Type": "movie", "Poster": "N/A"}
+			],
+			"totalResults": "1",
+			"Response": "True"
+		}`),
+	)
+	httpmock.RegisterResponder(
+		"GET", `http://omdbapi.com/?apikey=abc123&s=Nothing+Like+It&type=movie`,
+		httpmock.NewStringResponder(
+			200, `{"Response": "False", "Error": "Movie not found!"}`,
+		),
+	)
+	httpmock.RegisterResponder(
+		"GET", `http://omdbapi.com/?apikey=abc123&i=tt0084777`,
+		httpmock.NewJsonResponderOrPanic(200, omdbSampleMovie()),
+	)
+
+	tenebrae := OmdbSearchResult{
+		Title: "Tenebrae", Year: "1982", ImdbID: "tt0084777", Type: "movie",
+		Poster: "N/A",
+	}
+	answer, err := ResolveWatchlistMovie(&client, "tenebrae", "")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if !cmp.Equal(tenebrae, *answer) {
+		t.Errorf("Expected %v, got %v", tenebrae, *answer)
+	}
+
+	answer, err = ResolveWatchlistMovie(&client, "tt0084777", "")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if answer.ImdbID != "tt0084777" || answer.Title != omdbSampleMovie().Title {
+		t.Errorf("Unexpected movie %v", *answer)
+	}
+
+	if _, err := ResolveWatchlistMovie(&client, "The Thing", ""); err == nil ||
+		!strings.Contains(err.Error(), "tt0905372") {
+		t.Errorf("Expected an error listing both movies, got %v", err)
+	}
+	answer, err = ResolveWatchlistMovie(&client, "The Thing", "1982")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if answer.ImdbID != "tt0084787" {
+		t.Errorf("Expected tt0084787, got %v", answer.ImdbID)
+	}
+
+	if _, err := ResolveWatchlistMovie(&client, "Nothing Like It", ""); err == nil {
+		t.Errorf("Expected an error for no results")
+	}
+}
+
+func TestPickWatchlistItem(t *testing.T) {
+	items := []database.Watchlist{
+		{ImdbID: "tt0084787", Priority: 5},
+		{ImdbID: "tt0084777", Priority: 1},
+	}
+	random := rand.New(rand.NewSource(42))
+	counts := make(map[string]int)
+	for ii := 0; ii < 600; ii++ {
+		counts[PickWatchlistItem(items, random).ImdbID] += 1
+	}
+	// Should be about 500 to 100.
+	if counts["tt0084787"] < 400 || counts["tt0084777"] < 50 {
+		t.Errorf("Unexpected picks %v", counts)
+	}
+	if PickWatchlistItem(nil, random) != nil {
+		t.Errorf("Expected nothing picked from an empty watchlist")
+	}
+}
+
+func TestCreateWatchlistPage(t *testing.T) {
+	items := []database.Watchlist{
+		{
+			ImdbID: "tt0084787", Title: "The Thing", Year: "1982", Priority: 5,
+			Added: "2022-10-01",
+		},
+		{
+			ImdbID: "tt0089885", Title: "Re-Animator", Year: "1985", Priority: 5,
+			Added:  "2022-10-02",
+			Source: sql.NullString{String: "recommended by Joe Bob", Valid: true},
+		},
+		{
+			ImdbID: "tt0083629", Title: "Q: The Winged Serpent", Year: "1982",
+			Priority: 2, Added: "2022-09-01",
+		},
+	}
+	page := CreateWatchlistPage(items, nil)
+	if len(page.Items) != 3 || len(page.Priorities) != 2 {
+		t.Fatalf("Unexpected page %v", page)
+	}
+	if page.Priorities[0].Priority != 5 || len(page.Priorities[0].Items) != 2 {
+		t.Errorf("Unexpected priority %v", page.Priorities[0])
+	}
+	truth := WatchlistPageItem{
+		Title:     "Q: The Winged Serpent",
+		FileTitle: "Q The Winged Serpent",
+		ImdbId:    "tt0083629",
+		Year:      "1982",
+		Added:     "2022-09-01",
+	}
+	if !cmp.Equal(truth, page.Priorities[1].Items[0]) {
+		t.Errorf("Expected %v, got %v", truth, page.Priorities[1].Items[0])
+	}
+}
+
+func TestWatchlist(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	vaultDir := t.TempDir()
+
+	thing := OmdbSearchResult{Title: "The Thing", Year: "1982", ImdbID: "tt0084787"}
+	tenebrae := OmdbSearchResult{Title: "Tenebrae", Year: "1982", ImdbID: "tt0084777"}
+	for _, params := range []*database.InsertWatchlistItemParams{
+		CreateInsertWatchlistItemParams(&thing, 2, "recommended by Joe Bob", "2022-10-01"),
+		CreateInsertWatchlistItemParams(&tenebrae, 3, "", "2022-10-02"),
+		// Adding it again bumps the priority but keeps the source.
+		CreateInsertWatchlistItemParams(&thing, 4, "", "2022-10-03"),
+	} {
+		if err := queries.InsertWatchlistItem(ctx, *params); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+
+	items, err := queries.GetWatchlist(ctx)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if len(items) != 2 || items[0].ImdbID != "tt0084787" ||
+		items[0].Priority != 4 || items[0].Added != "2022-10-01" ||
+		items[0].Source.String != "recommended by Joe Bob" {
+		t.Errorf("Unexpected watchlist %v", items)
+	}
+
+	item, err := FindWatchlistItem(ctx, queries, "the thing")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if item.ImdbID != "tt0084787" {
+		t.Errorf("Expected tt0084787, got %v", item.ImdbID)
+	}
+	if _, err := FindWatchlistItem(ctx, queries, "tt0000000"); err == nil {
+		t.Errorf("Expected an error for a movie that isn't on the watchlist")
+	}
+
+	watchlistPath := path.Join(vaultDir, WATCHLIST_PAGE)
+	if err := os.WriteFile(
+		watchlistPath, []byte("# Watchlist\n\n## Notes\nAsk Felissa.\n"), 0644,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := WriteWatchlistPage(ctx, queries, vaultDir); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	pageBytes, err := os.ReadFile(watchlistPath)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	for _, expected := range []string{
+		"- [[The Thing (tt0084787)|The Thing (1982)]] added [[2022-10-01]], " +
+			"recommended by Joe Bob\n",
+		"- [[Tenebrae (tt0084777)|Tenebrae (1982)]] added [[2022-10-02]]\n",
+		"Ask Felissa.",
+	} {
+		if !strings.Contains(string(pageBytes), expected) {
+			t.Errorf("Expected %q in %v", expected, string(pageBytes))
+		}
+	}
+
+	// Watching it takes it off the list.
+	removed, err := RemoveWatchedFromWatchlist(ctx, queries, "tt0084777")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if !removed {
+		t.Errorf("Expected tt0084777 to be removed")
+	}
+	removed, err = RemoveWatchedFromWatchlist(ctx, queries, "tt0084777")
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if removed {
+		t.Errorf("Expected nothing left to remove")
+	}
+}
@@ -0,0 +1,448 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/timothyrenner/movies-app/database"
+)
+
+var UI_LAYOUT_TEMPLATE = `{{define "layout"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} | Movies</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 48rem; margin: 0 auto; padding: 1rem; line-height: 1.5; }
+header { display: flex; gap: 1rem; align-items: center; border-bottom: 1px solid #ccc; padding-bottom: 0.5rem; }
+header form { margin-left: auto; }
+a { color: #8b0000; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: 0.25rem 0.5rem 0.25rem 0; vertical-align: top; }
+.poster { float: right; max-width: 12rem; margin: 0 0 1rem 1rem; }
+.muted { color: #666; }
+.pager { display: flex; justify-content: space-between; margin-top: 1rem; }
+</style>
+</head>
+<body>
+<header>
+<a href="{{link "diary" ""}}">Diary</a>
+<form action="{{link "search" ""}}" method="get">
+<input type="search" name="q" value="{{.Query}}" placeholder="Search plots, notes and reviews">
+</form>
+</header>
+<main>
+{{template "content" .Content}}
+</main>
+</body>
+</html>
+{{end}}`
+
+var UI_DIARY_TEMPLATE = `{{define "content"}}<h1>{{.MonthName}}</h1>
+{{if .Watches}}<table>
+{{range .Watches}}<tr>
+<td>{{.Watched}}</td>
+<td><a href="{{link "movie" .ImdbID}}">{{.MovieTitle}}</a> <span class="muted">({{.Year}})</span></td>
+<td>{{.Service}}</td>
+<td>{{if .FirstTime}}first time{{end}}</td>
+</tr>
+{{end}}</table>
+{{else}}<p>Nothing watched this month.</p>
+{{end}}<nav class="pager">
+<span>{{if .Newer}}<a href="{{link "month" .Newer}}">&larr; {{.Newer}}</a>{{end}}</span>
+<span>{{if .Older}}<a href="{{link "month" .Older}}">{{.Older}} &rarr;</a>{{end}}</span>
+</nav>
+{{end}}`
+
+var UI_MOVIE_TEMPLATE = `{{define "content"}}{{with .Movie}}{{if .Poster}}<img class="poster" src="{{.Poster}}" alt="Poster for {{.Title}}">
+{{end}}<h1>{{.Title}} <span class="muted">({{.Year}})</span></h1>
+<p class="muted">{{if .Rated}}{{.Rated}} &middot; {{end}}{{if .RuntimeMinutes}}{{.RuntimeMinutes}} min &middot; {{end}}{{range $ii, $genre := $.Genres}}{{if $ii}}, {{end}}<a href="{{link "genre" $genre.Slug}}">{{$genre.Name}}</a>{{end}}</p>
+{{if .Plot}}<p>{{.Plot}}</p>
+{{end}}<table>
+<tr><th>Directed by</th><td>{{range $ii, $name := .Directors}}{{if $ii}}, {{end}}<a href="{{link "person" $name}}">{{$name}}</a>{{end}}</td></tr>
+<tr><th>Written by</th><td>{{range $ii, $name := .Writers}}{{if $ii}}, {{end}}<a href="{{link "person" $name}}">{{$name}}</a>{{end}}</td></tr>
+<tr><th>Starring</th><td>{{range $ii, $name := .Actors}}{{if $ii}}, {{end}}<a href="{{link "person" $name}}">{{$name}}</a>{{end}}</td></tr>
+{{range .Ratings}}<tr><th>{{.Source}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+<p><a href="https://www.imdb.com/title/{{.ImdbId}}/">IMDB</a></p>
+{{end}}<h2>Watches</h2>
+<table>
+{{range .Watches}}<tr>
+<td><a href="{{link "month" (month .Watched)}}">{{.Watched}}</a></td>
+<td>{{.Service}}</td>
+<td>{{if .FirstTime}}first time{{end}}{{if .JoeBob}} Joe Bob{{end}}</td>
+</tr>
+{{if .Notes.Valid}}<tr><td></td><td colspan="2">{{.Notes.String}}</td></tr>
+{{end}}{{end}}</table>
+{{if .Reviews}}<h2>Reviews</h2>
+{{range .Reviews}}<p class="muted">{{with .Watched.String}}{{.}} &middot; {{end}}{{if .Liked}}Liked it.{{else}}Didn't like it.{{end}}{{if .Rating.Valid}} {{.Rating.Float64}} stars.{{end}}</p>
+<p>{{.Review}}</p>
+{{end}}{{end}}{{end}}`
+
+var UI_PERSON_TEMPLATE = `{{define "content"}}<h1>{{.Name}}</h1>
+{{if .Aliases}}<p class="muted">Also credited as {{range $ii, $alias := .Aliases}}{{if $ii}}, {{end}}{{$alias}}{{end}}</p>
+{{end}}<table>
+{{range .Credits}}<tr>
+<td><a href="{{link "movie" .ImdbId}}">{{.Title}}</a> <span class="muted">({{.Year}})</span></td>
+<td>{{.Role}}</td>
+</tr>
+{{end}}</table>
+{{end}}`
+
+var UI_GENRE_TEMPLATE = `{{define "content"}}<h1>{{.Name}}</h1>
+<table>
+{{range .Movies}}<tr>
+<td><a href="{{link "movie" .ImdbID}}">{{.Title}}</a> <span class="muted">({{.Year}})</span></td>
+</tr>
+{{end}}</table>
+{{end}}`
+
+var UI_SEARCH_TEMPLATE = `{{define "content"}}<h1>Search</h1>
+{{if .Query}}{{if .Results}}<table>
+{{range .Results}}<tr>
+<td><a href="{{link "movie" .ImdbId}}">{{.Title}}</a> <span class="muted">({{.Year}})</span><br>
+<span class="muted">{{.Kind}}{{if .Watched}} {{.Watched}}{{end}}</span></td>
+<td>{{highlight .Snippet}}</td>
+</tr>
+{{end}}</table>
+{{else}}<p>Nothing matched {{.Query}}.</p>
+{{end}}{{end}}{{end}}`
+
+// UiLink is the URL for a page in the web UI. Templates get it as link so
+// the routes only live here and in newUiMux.
+func UiLink(kind string, value string) string {
+	switch kind {
+	case "movie":
+		return "/ui/movies/" + url.PathEscape(value)
+	case "person":
+		return "/ui/people/" + url.PathEscape(value)
+	case "genre":
+		// Genres go by their slugs.
+		return "/ui/genres/" + url.PathEscape(value)
+	case "month":
+		return "/ui/diary?month=" + url.QueryEscape(value)
+	case "search":
+		if value == "" {
+			return "/ui/search"
+		}
+		return "/ui/search?q=" + url.QueryEscape(value)
+	default:
+		return "/ui/diary"
+	}
+}
+
+// HighlightSnippet escapes a search snippet and turns the ** markers the
+// search queries put around matches into <mark>.
+func HighlightSnippet(snippet string) template.HTML {
+	parts := strings.Split(snippet, "**")
+	var builder strings.Builder
+	for ii := range parts {
+		if ii > 0 {
+			if ii%2 == 1 {
+				builder.WriteString("<mark>")
+			} else {
+				builder.WriteString("</mark>")
+			}
+		}
+		builder.WriteString(template.HTMLEscapeString(parts[ii]))
+	}
+	// Unbalanced markers would leave the mark open.
+	if len(parts)%2 == 0 {
+		builder.WriteString("</mark>")
+	}
+	return template.HTML(builder.String())
+}
+
+var uiFuncs = template.FuncMap{
+	"link":      UiLink,
+	"highlight": HighlightSnippet,
+	"month": func(watched string) string {
+		if len(watched) < 7 {
+			return watched
+		}
+		return watched[:7]
+	},
+}
+
+// Each page is the layout with its own content block.
+var uiTemplates = map[string]*template.Template{
+	"diary":  parseUiTemplate("diary", UI_DIARY_TEMPLATE),
+	"movie":  parseUiTemplate("movie", UI_MOVIE_TEMPLATE),
+	"person": parseUiTemplate("person", UI_PERSON_TEMPLATE),
+	"genre":  parseUiTemplate("genre", UI_GENRE_TEMPLATE),
+	"search": parseUiTemplate("search", UI_SEARCH_TEMPLATE),
+}
+
+func parseUiTemplate(name string, content string) *template.Template {
+	return template.Must(
+		template.Must(
+			template.New(name).Funcs(uiFuncs).Parse(UI_LAYOUT_TEMPLATE),
+		).Parse(content),
+	)
+}
+
+type uiPage struct {
+	Title   string
+	Query   string
+	Content interface{}
+}
+
+type UiDiaryPage struct {
+	Month     string
+	MonthName string
+	// The closest months with watches on either side, empty at the ends.
+	Newer   string
+	Older   string
+	Watches []database.GetMovieWatchesBetweenRow
+}
+
+type UiMoviePage struct {
+	Movie *ApiMovie
+	// With their slugs for the links.
+	Genres  []database.GetGenresForMovieRow
+	Watches []database.GetWatchesForMovieRow
+	Reviews []database.GetReviewsForMovieUuidRow
+}
+
+type UiGenrePage struct {
+	Name   string
+	Movies []database.GetMoviesForGenreRow
+}
+
+type UiSearchPage struct {
+	Query   string
+	Results []SearchResult
+}
+
+type uiServer struct {
+	queries *database.Queries
+}
+
+func newUiMux(queries *database.Queries) *http.ServeMux {
+	server := &uiServer{queries: queries}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ui/", methods(map[string]http.HandlerFunc{
+		http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/ui/" {
+				writeUiNotFound(w, "Nothing lives here.")
+				return
+			}
+			http.Redirect(w, r, UiLink("diary", ""), http.StatusFound)
+		},
+	}))
+	mux.HandleFunc("/ui/diary", methods(map[string]http.HandlerFunc{
+		http.MethodGet: server.diary,
+	}))
+	mux.HandleFunc("/ui/movies/", methods(map[string]http.HandlerFunc{
+		http.MethodGet: server.movie,
+	}))
+	mux.HandleFunc("/ui/people/", methods(map[string]http.HandlerFunc{
+		http.MethodGet: server.person,
+	}))
+	mux.HandleFunc("/ui/genres/", methods(map[string]http.HandlerFunc{
+		http.MethodGet: server.genre,
+	}))
+	mux.HandleFunc("/ui/search", methods(map[string]http.HandlerFunc{
+		http.MethodGet: server.search,
+	}))
+	return mux
+}
+
+// writeHtml renders into a buffer first so a template error is a 500 rather
+// than half a page.
+func writeHtml(
+	w http.ResponseWriter, r *http.Request, status int, name string,
+	page *uiPage,
+) {
+	var body bytes.Buffer
+	if err := uiTemplates[name].ExecuteTemplate(&body, "layout", page); err != nil {
+		log.Printf("Error rendering %v for %v: %v", name, r.URL, err)
+		http.Error(w, "error rendering page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(body.Bytes())
+}
+
+func writeUiNotFound(w http.ResponseWriter, message string) {
+	http.Error(w, message, http.StatusNotFound)
+}
+
+func writeUiError(
+	w http.ResponseWriter, r *http.Request, message string, err error,
+) {
+	log.Printf("Error serving %v: %v: %v", r.URL, message, err)
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// CreateUiDiaryPage gets the watches for a YYYY-MM month, or the latest month
+// with any watches if month is empty.
+func CreateUiDiaryPage(
+	ctx context.Context, queries *database.Queries, month string,
+) (*UiDiaryPage, error) {
+	months, err := queries.GetWatchMonths(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting watch months: %v", err)
+	}
+	if month == "" {
+		if len(months) == 0 {
+			today, err := WatchedToday("")
+			if err != nil {
+				return nil, err
+			}
+			month = today[:len(WATCHED_MONTH_LAYOUT)]
+		} else {
+			month = months[0].Month
+		}
+	}
+	start, err := ParseWatchedMonth(month)
+	if err != nil {
+		return nil, err
+	}
+
+	page := UiDiaryPage{Month: month, MonthName: start.Format("January 2006")}
+	// Months come back newest first.
+	for ii := range months {
+		if months[ii].Month > month {
+			page.Newer = months[ii].Month
+		} else if months[ii].Month < month && page.Older == "" {
+			page.Older = months[ii].Month
+		}
+	}
+	page.Watches, err = queries.GetMovieWatchesBetween(
+		ctx, database.GetMovieWatchesBetweenParams{
+			Watched:   start.Format(WATCHED_DATE_LAYOUT),
+			Watched_2: start.AddDate(0, 1, 0).Format(WATCHED_DATE_LAYOUT),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting watches for %v: %v", month, err)
+	}
+	return &page, nil
+}
+
+func (s *uiServer) diary(w http.ResponseWriter, r *http.Request) {
+	month := r.URL.Query().Get("month")
+	if month != "" {
+		if _, err := ParseWatchedMonth(month); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	page, err := CreateUiDiaryPage(r.Context(), s.queries, month)
+	if err != nil {
+		writeUiError(w, r, "error getting diary", err)
+		return
+	}
+	writeHtml(w, r, http.StatusOK, "diary", &uiPage{
+		Title: page.MonthName, Content: page,
+	})
+}
+
+func CreateUiMoviePage(
+	ctx context.Context, queries *database.Queries, imdbId string,
+) (*UiMoviePage, error) {
+	movie, err := GetApiMovie(ctx, queries, imdbId)
+	if err != nil {
+		// Let sql.ErrNoRows through untouched so callers can 404.
+		return nil, err
+	}
+	watches, err := queries.GetWatchesForMovie(ctx, movie.Uuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting watches: %v", err)
+	}
+	genres, err := queries.GetGenresForMovie(ctx, movie.Uuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting genres: %v", err)
+	}
+	page := UiMoviePage{Movie: movie, Genres: genres, Watches: watches}
+	page.Reviews, err = queries.GetReviewsForMovieUuid(ctx, movie.Uuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting reviews: %v", err)
+	}
+	return &page, nil
+}
+
+func (s *uiServer) movie(w http.ResponseWriter, r *http.Request) {
+	imdbId := strings.TrimPrefix(r.URL.Path, "/ui/movies/")
+	page, err := CreateUiMoviePage(r.Context(), s.queries, imdbId)
+	if err == sql.ErrNoRows {
+		writeUiNotFound(w, fmt.Sprintf("No movie with id %v.", imdbId))
+		return
+	} else if err != nil {
+		writeUiError(w, r, "error getting movie", err)
+		return
+	}
+	writeHtml(w, r, http.StatusOK, "movie", &uiPage{
+		Title: page.Movie.Title, Content: page,
+	})
+}
+
+func (s *uiServer) person(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/ui/people/")
+	person, err := GetApiPerson(r.Context(), s.queries, name)
+	if err == sql.ErrNoRows {
+		writeUiNotFound(w, fmt.Sprintf("No person named %v.", name))
+		return
+	} else if err != nil {
+		writeUiError(w, r, "error getting person", err)
+		return
+	}
+	writeHtml(w, r, http.StatusOK, "person", &uiPage{
+		Title: person.Name, Content: person,
+	})
+}
+
+func (s *uiServer) genre(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	slug := strings.TrimPrefix(r.URL.Path, "/ui/genres/")
+	genre, err := s.queries.FindGenreBySlug(ctx, slug)
+	if err == sql.ErrNoRows {
+		writeUiNotFound(w, fmt.Sprintf("No genre %v.", slug))
+		return
+	} else if err != nil {
+		writeUiError(w, r, "error getting genre", err)
+		return
+	}
+	movies, err := s.queries.GetMoviesForGenre(ctx, genre.Name)
+	if err != nil {
+		writeUiError(w, r, "error getting movies for genre", err)
+		return
+	}
+	writeHtml(w, r, http.StatusOK, "genre", &uiPage{
+		Title: genre.Name, Content: &UiGenrePage{Name: genre.Name, Movies: movies},
+	})
+}
+
+func (s *uiServer) search(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	page := UiSearchPage{Query: query}
+	if query != "" {
+		results, err := Search(r.Context(), s.queries, query, &SearchFilter{})
+		if err != nil {
+			// Most of these are FTS syntax errors in the query itself.
+			log.Printf("Error searching for %v: %v", query, err)
+			http.Error(
+				w, fmt.Sprintf("unable to search for %v", query),
+				http.StatusBadRequest,
+			)
+			return
+		}
+		if len(results) > DEFAULT_PAGE_SIZE {
+			results = results[:DEFAULT_PAGE_SIZE]
+		}
+		page.Results = results
+	}
+	writeHtml(w, r, http.StatusOK, "search", &uiPage{
+		Title: "Search", Query: query, Content: &page,
+	})
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestUiLink(t *testing.T) {
+	truth := []string{
+		"/ui/movies/tt0084777",
+		"/ui/people/Dario%20Argento",
+		"/ui/genres/science-fiction",
+		"/ui/diary?month=2022-05",
+		"/ui/search?q=razor+blade",
+	}
+	answer := []string{
+		UiLink("movie", "tt0084777"),
+		UiLink("person", "Dario Argento"),
+		UiLink("genre", "science-fiction"),
+		UiLink("month", "2022-05"),
+		UiLink("search", "razor blade"),
+	}
+	if !cmp.Equal(truth, answer) {
+		t.Errorf("Expected %v, got %v", truth, answer)
+	}
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	truth := "a <mark>razor</mark> &amp; <mark>blade</mark>"
+	answer := string(HighlightSnippet("a **razor** & **blade"))
+	if truth != answer {
+		t.Errorf("Expected %v, got %v", truth, answer)
+	}
+}
+
+func getHtml(
+	t *testing.T, handler http.Handler, url string, status int,
+) string {
+	request := httptest.NewRequest(http.MethodGet, url, nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != status {
+		t.Errorf("Expected %v for %v, got %v", status, url, recorder.Code)
+	}
+	return recorder.Body.String()
+}
+
+func TestUi(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	movieDetails, err := InsertMovieDetails(
+		db, ctx, queries, sampleMoviePage(), nil,
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	for _, watched := range []string{"2022-05-27", "2022-10-31", "2023-01-01"} {
+		movieWatch := sampleMovieWatchPage()
+		movieWatch.Watched = watched
+		movieWatch.Notes = "Razor blades <everywhere>."
+		if err := queries.InsertMovieWatch(
+			ctx, *CreateInsertMovieWatchParams(movieWatch, movieDetails.Movie),
+		); err != nil {
+			t.Fatalf("Encountered error: %v", err)
+		}
+	}
+	handler := NewApiHandler(queries)
+
+	// The diary starts on the latest month and pages back through the
+	// months that have watches.
+	body := getHtml(t, handler, "/ui/diary", http.StatusOK)
+	if !strings.Contains(body, "January 2023") ||
+		!strings.Contains(body, `href="/ui/diary?month=2022-10"`) {
+		t.Errorf("Unexpected diary %v", body)
+	}
+	body = getHtml(t, handler, "/ui/diary?month=2022-10", http.StatusOK)
+	if !strings.Contains(body, `href="/ui/diary?month=2023-01"`) ||
+		!strings.Contains(body, `href="/ui/diary?month=2022-05"`) ||
+		!strings.Contains(body, `href="/ui/movies/tt0084777"`) {
+		t.Errorf("Unexpected diary %v", body)
+	}
+	getHtml(t, handler, "/ui/diary?month=October", http.StatusBadRequest)
+
+	body = getHtml(t, handler, "/ui/movies/tt0084777", http.StatusOK)
+	if !strings.Contains(body, `href="/ui/people/John%20Saxon"`) ||
+		!strings.Contains(body, `href="/ui/genres/horror"`) ||
+		!strings.Contains(body, "Razor blades &lt;everywhere&gt;.") {
+		t.Errorf("Unexpected movie page %v", body)
+	}
+	getHtml(t, handler, "/ui/movies/tt0000000", http.StatusNotFound)
+
+	body = getHtml(t, handler, "/ui/people/Dario%20Argento", http.StatusOK)
+	if !strings.Contains(body, "director") || !strings.Contains(body, "writer") {
+		t.Errorf("Unexpected person page %v", body)
+	}
+	body = getHtml(t, handler, "/ui/genres/horror", http.StatusOK)
+	if !strings.Contains(body, "Tenebrae") {
+		t.Errorf("Unexpected genre page %v", body)
+	}
+	getHtml(t, handler, "/ui/genres/kaiju", http.StatusNotFound)
+
+	body = getHtml(t, handler, "/ui/search?q=razor", http.StatusOK)
+	if !strings.Contains(body, "<mark>Razor</mark>") {
+		t.Errorf("Unexpected search page %v", body)
+	}
+}
@@ -0,0 +1,651 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/google/uuid"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+var imdbIdRegex = regexp.MustCompile(`^tt\d{7,8}$`)
+
+// The body for POST /watches and PUT /watches/{uuid}. The movie flags are
+// only used when the movie isn't in the database yet.
+type ApiWatchRequest struct {
+	ImdbId  string `json:"imdb_id"`
+	Watched string `json:"watched"`
+	// Optional, an RFC 3339 timestamp with an optional [IANA/Zone] suffix.
+	WatchedAt   string  `json:"watched_at"`
+	Service     string  `json:"service"`
+	Rating      float64 `json:"rating"`
+	FirstTime   bool    `json:"first_time"`
+	JoeBob      bool    `json:"joe_bob"`
+	Notes       string  `json:"notes"`
+	CallFelissa bool    `json:"call_felissa"`
+	Slasher     bool    `json:"slasher"`
+	Zombies     bool    `json:"zombies"`
+	Beast       bool    `json:"beast"`
+	Godzilla    bool    `json:"godzilla"`
+	WallpaperFu bool    `json:"wallpaper_fu"`
+
+	// Filled in from WatchedAt by Validate.
+	watchedAt *WatchedAt
+}
+
+// Validate fills in a missing watch date, from watched_at if there is one
+// and today otherwise, and checks the rest.
+func (r *ApiWatchRequest) Validate() error {
+	if !imdbIdRegex.MatchString(r.ImdbId) {
+		return fmt.Errorf("expected an imdb_id like tt0084777, got %v", r.ImdbId)
+	}
+	r.watchedAt = nil
+	if r.WatchedAt != "" {
+		watchedAt, err := ParseWatchedAt(r.WatchedAt)
+		if err != nil {
+			return err
+		}
+		if r.Watched == "" {
+			r.Watched = watchedAt.Date()
+		} else if r.Watched != watchedAt.Date() {
+			return fmt.Errorf(
+				"watched %v doesn't match watched_at %v", r.Watched, watchedAt,
+			)
+		}
+		r.watchedAt = watchedAt
+	}
+	if r.Watched == "" {
+		today, err := WatchedToday("")
+		if err != nil {
+			return err
+		}
+		r.Watched = today
+	}
+	if _, err := ParseWatchedDate(r.Watched); err != nil {
+		return err
+	}
+	if strings.TrimSpace(r.Service) == "" {
+		return fmt.Errorf("service is required")
+	}
+	if err := ValidateStarRating(r.Rating); err != nil {
+		return err
+	}
+	return nil
+}
+
+// The body for PUT /movies/{imdb_id}/review.
+type ApiReviewRequest struct {
+	Review string  `json:"review"`
+	Liked  bool    `json:"liked"`
+	Rating float64 `json:"rating"`
+	// The date of the watch the review is for, if it's for one. Each watch
+	// and the movie itself have their own review; writing one again revises
+	// it.
+	Watched string `json:"watched"`
+}
+
+// apiWriter handles the endpoints that change the database, writing the
+// vault pages alongside so Obsidian stays in sync.
+type apiWriter struct {
+	db                 *sql.DB
+	queries            *database.Queries
+	omdbClient         *OmdbClient
+	taxonomy           *GenreTaxonomy
+	token              string
+	vaultDir           string
+	watchesDir         string
+	moviesDir          string
+	reviewsDir         string
+	movieWatchTemplate *template.Template
+	movieTemplate      *template.Template
+	reviewTemplate     *template.Template
+	// sqlite only takes one writer at a time anyway, and this keeps the
+	// check-then-insert in the handlers honest.
+	mu sync.Mutex
+}
+
+// NewWriteApiHandler serves the read-only endpoints plus the authenticated
+// write endpoints, which also write pages into the vault.
+func NewWriteApiHandler(
+	db *sql.DB,
+	queries *database.Queries,
+	omdbClient *OmdbClient,
+	vaultDir string,
+	token string,
+) (http.Handler, error) {
+	if token == "" {
+		return nil, fmt.Errorf("an API token is required to serve writes")
+	}
+	writer := apiWriter{
+		db:         db,
+		queries:    queries,
+		omdbClient: omdbClient,
+		token:      token,
+		vaultDir:   vaultDir,
+		watchesDir: path.Join(vaultDir, "Watches"),
+		moviesDir:  path.Join(vaultDir, "Movies"),
+		reviewsDir: path.Join(vaultDir, "Reviews"),
+	}
+	for _, dir := range []string{
+		writer.watchesDir, writer.moviesDir, writer.reviewsDir,
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("error creating %v: %v", dir, err)
+		}
+	}
+
+	templates, err := LoadVaultTemplates(vaultDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load templates: %v", err)
+	}
+	writer.movieWatchTemplate = templates.MovieWatch.Template
+	writer.movieTemplate = templates.Movie.Template
+	writer.reviewTemplate = templates.Review.Template
+
+	writer.taxonomy, err = LoadGenreTaxonomy(context.Background(), queries)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load genre taxonomy: %v", err)
+	}
+
+	return newApiMux(&apiServer{queries: queries, writer: &writer}), nil
+}
+
+// authorized wraps a write handler with a bearer token check. With no
+// writer it returns nil so the endpoint isn't served at all.
+func (a *apiWriter) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	if a == nil {
+		return nil
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(header, "Bearer ")
+		if token == header ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeApiError(w, http.StatusUnauthorized, "missing or bad token")
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func decodeJsonBody(
+	w http.ResponseWriter, r *http.Request, value interface{},
+) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return fmt.Errorf("error decoding request body: %v", err)
+	}
+	return nil
+}
+
+// CreateApiMovieWatchPage builds the watch page for a request against a
+// movie that's already in the database, named with its saved file title.
+func CreateApiMovieWatchPage(
+	request *ApiWatchRequest, movie *database.Movie, fileTitle string,
+) *MovieWatchPage {
+	return &MovieWatchPage{
+		Title:       movie.Title,
+		FileTitle:   fileTitle,
+		Watched:     request.Watched,
+		ImdbLink:    movie.ImdbLink,
+		ImdbId:      movie.ImdbID,
+		FirstTime:   request.FirstTime,
+		JoeBob:      request.JoeBob,
+		CallFelissa: movie.CallFelissa != 0,
+		Beast:       movie.Beast != 0,
+		Godzilla:    movie.Godzilla != 0,
+		Zombies:     movie.Zombies != 0,
+		Slasher:     movie.Slasher != 0,
+		WallpaperFu: movie.WallpaperFu != 0,
+		Service:     request.Service,
+		Notes:       request.Notes,
+		WatchedAt:   request.watchedAt,
+		Rating:      request.Rating,
+	}
+}
+
+// findOrInsertMovie returns the movie for the request, pulling it from OMDB
+// and writing its page if it's new.
+func (a *apiWriter) findOrInsertMovie(
+	r *http.Request, request *ApiWatchRequest,
+) (*database.Movie, error) {
+	ctx := r.Context()
+	movieUuid, err := a.queries.FindMovie(ctx, request.ImdbId)
+	if err == sql.ErrNoRows {
+		log.Printf("Fetching %v from OMDB.", request.ImdbId)
+		movieWatch := &MovieWatchPage{
+			ImdbId:      request.ImdbId,
+			CallFelissa: request.CallFelissa,
+			Slasher:     request.Slasher,
+			Zombies:     request.Zombies,
+			Beast:       request.Beast,
+			Godzilla:    request.Godzilla,
+			WallpaperFu: request.WallpaperFu,
+		}
+		moviePage, movieDetailUuids, err := InsertMovieFromOmdb(
+			a.db, ctx, a.queries, a.omdbClient, a.taxonomy, movieWatch,
+		)
+		if err != nil {
+			return nil, err
+		}
+		movieUuid = movieDetailUuids.Movie
+		fileTitle, err := GetMovieFileTitle(ctx, a.queries, movieUuid)
+		if err != nil {
+			return nil, err
+		}
+		moviePageFilePath := path.Join(
+			a.moviesDir, MoviePageFileName(fileTitle, request.ImdbId),
+		)
+		if _, err := WriteNewPage(
+			a.movieTemplate, moviePageFilePath, moviePage,
+		); err != nil {
+			return nil, fmt.Errorf("error writing movie page: %v", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("error finding movie %v: %v", request.ImdbId, err)
+	}
+
+	movie, err := a.queries.GetMovie(ctx, movieUuid)
+	if err != nil {
+		return nil, fmt.Errorf("error getting movie %v: %v", movieUuid, err)
+	}
+	return &movie, nil
+}
+
+// saveWatch upserts the watch and writes its page.
+func (a *apiWriter) saveWatch(
+	r *http.Request, watchUuid string, movieWatch *MovieWatchPage,
+	movieUuid string,
+) error {
+	// The watch only goes in the database if its page gets written.
+	tx, err := a.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	qtx := a.queries.WithTx(tx)
+
+	params := CreateInsertMovieWatchParams(movieWatch, movieUuid)
+	params.Uuid = watchUuid
+	if err := qtx.InsertMovieWatch(r.Context(), *params); err != nil {
+		return fmt.Errorf("error inserting movie watch: %v", err)
+	}
+	filePath := path.Join(a.watchesDir, MovieWatchPageFileName(movieWatch))
+	if err := WritePage(a.movieWatchTemplate, filePath, movieWatch); err != nil {
+		return fmt.Errorf("error writing watch page: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	removed, err := RemoveWatchedFromWatchlist(
+		r.Context(), a.queries, movieWatch.ImdbId,
+	)
+	if err != nil {
+		return err
+	}
+	if removed {
+		log.Printf("Removed %v from the watchlist.", movieWatch.Title)
+		if err := WriteWatchlistPage(r.Context(), a.queries, a.vaultDir); err != nil {
+			return fmt.Errorf("error writing watchlist page: %v", err)
+		}
+	}
+	return nil
+}
+
+func (a *apiWriter) writeWatch(
+	w http.ResponseWriter, r *http.Request, status int, watchUuid string,
+) {
+	watch, err := GetApiWatch(r.Context(), a.queries, watchUuid)
+	if err != nil {
+		writeInternalError(w, r, "error getting watch", err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/watches/%v", watchUuid))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(watch)
+}
+
+func (a *apiWriter) createWatch(w http.ResponseWriter, r *http.Request) {
+	request := ApiWatchRequest{}
+	if err := decodeJsonBody(w, r, &request); err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := request.Validate(); err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ctx := r.Context()
+
+	existingUuid, err := a.queries.FindMovieWatch(
+		ctx, database.FindMovieWatchParams{
+			ImdbID: request.ImdbId, Watched: request.Watched,
+		},
+	)
+	if err == nil {
+		writeApiError(
+			w, http.StatusConflict,
+			fmt.Sprintf(
+				"%v was already watched on %v as %v",
+				request.ImdbId, request.Watched, existingUuid,
+			),
+		)
+		return
+	} else if err != sql.ErrNoRows {
+		writeInternalError(w, r, "error finding watch", err)
+		return
+	}
+
+	movie, err := a.findOrInsertMovie(r, &request)
+	if err != nil {
+		log.Printf("Error getting movie %v: %v", request.ImdbId, err)
+		writeApiError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	fileTitle, err := GetMovieFileTitle(r.Context(), a.queries, movie.Uuid)
+	if err != nil {
+		writeInternalError(w, r, "error getting file title", err)
+		return
+	}
+	movieWatch := CreateApiMovieWatchPage(&request, movie, fileTitle)
+	watchUuid := uuid.New().String()
+	if err := a.saveWatch(r, watchUuid, movieWatch, movie.Uuid); err != nil {
+		writeInternalError(w, r, "error saving watch", err)
+		return
+	}
+	a.writeWatch(w, r, http.StatusCreated, watchUuid)
+}
+
+func (a *apiWriter) updateWatch(w http.ResponseWriter, r *http.Request) {
+	watchUuid := strings.TrimPrefix(r.URL.Path, "/watches/")
+	request := ApiWatchRequest{}
+	if err := decodeJsonBody(w, r, &request); err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ctx := r.Context()
+
+	existing, err := a.queries.GetMovieWatch(ctx, watchUuid)
+	if err == sql.ErrNoRows {
+		writeApiError(
+			w, http.StatusNotFound, fmt.Sprintf("no watch %v", watchUuid),
+		)
+		return
+	} else if err != nil {
+		writeInternalError(w, r, "error getting watch", err)
+		return
+	}
+	// Moving a watch to another movie is a delete and a create.
+	if request.ImdbId == "" {
+		request.ImdbId = existing.ImdbID
+	}
+	if request.ImdbId != existing.ImdbID {
+		writeApiError(
+			w, http.StatusBadRequest,
+			fmt.Sprintf("watch %v is for %v", watchUuid, existing.ImdbID),
+		)
+		return
+	}
+	if err := request.Validate(); err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if request.Watched != existing.Watched {
+		otherUuid, err := a.queries.FindMovieWatch(
+			ctx, database.FindMovieWatchParams{
+				ImdbID: request.ImdbId, Watched: request.Watched,
+			},
+		)
+		if err == nil {
+			writeApiError(
+				w, http.StatusConflict,
+				fmt.Sprintf(
+					"%v was already watched on %v as %v",
+					request.ImdbId, request.Watched, otherUuid,
+				),
+			)
+			return
+		} else if err != sql.ErrNoRows {
+			writeInternalError(w, r, "error finding watch", err)
+			return
+		}
+	}
+
+	movie, err := a.queries.GetMovie(ctx, existing.MovieUuid)
+	if err != nil {
+		writeInternalError(w, r, "error getting movie", err)
+		return
+	}
+	fileTitle, err := GetMovieFileTitle(ctx, a.queries, movie.Uuid)
+	if err != nil {
+		writeInternalError(w, r, "error getting file title", err)
+		return
+	}
+	movieWatch := CreateApiMovieWatchPage(&request, &movie, fileTitle)
+	if err := a.saveWatch(r, watchUuid, movieWatch, movie.Uuid); err != nil {
+		writeInternalError(w, r, "error saving watch", err)
+		return
+	}
+	// The page name has the date in it, so a new date leaves the old page
+	// behind.
+	if request.Watched != existing.Watched {
+		if err := removePage(
+			path.Join(
+				a.watchesDir, existingWatchPageFileName(&existing, fileTitle),
+			),
+		); err != nil {
+			writeInternalError(w, r, "error removing old watch page", err)
+			return
+		}
+	}
+	a.writeWatch(w, r, http.StatusOK, watchUuid)
+}
+
+// existingWatchPageFileName names the page the vault builder would have
+// written for the watch, given its movie's file title. The rows have the
+// same columns, so the conversion lets it go through CreateMovieWatchPage
+// like every other watch.
+func existingWatchPageFileName(
+	watch *database.GetMovieWatchRow, fileTitle string,
+) string {
+	return MovieWatchPageFileName(
+		CreateMovieWatchPage(
+			(*database.GetAllMovieWatchesRow)(watch),
+			VaultNames{watch.ImdbID: fileTitle},
+		),
+	)
+}
+
+func removePage(filePath string) error {
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing %v: %v", filePath, err)
+	}
+	return nil
+}
+
+func (a *apiWriter) deleteWatch(w http.ResponseWriter, r *http.Request) {
+	watchUuid := strings.TrimPrefix(r.URL.Path, "/watches/")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ctx := r.Context()
+
+	existing, err := a.queries.GetMovieWatch(ctx, watchUuid)
+	if err == sql.ErrNoRows {
+		writeApiError(
+			w, http.StatusNotFound, fmt.Sprintf("no watch %v", watchUuid),
+		)
+		return
+	} else if err != nil {
+		writeInternalError(w, r, "error getting watch", err)
+		return
+	}
+	fileTitle, err := GetMovieFileTitle(ctx, a.queries, existing.MovieUuid)
+	if err != nil {
+		writeInternalError(w, r, "error getting file title", err)
+		return
+	}
+	if err := a.queries.DeleteMovieWatch(ctx, watchUuid); err != nil {
+		writeInternalError(w, r, "error deleting watch", err)
+		return
+	}
+	if err := removePage(
+		path.Join(
+			a.watchesDir, existingWatchPageFileName(&existing, fileTitle),
+		),
+	); err != nil {
+		writeInternalError(w, r, "error removing watch page", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *apiWriter) putReview(w http.ResponseWriter, r *http.Request) {
+	imdbId := strings.TrimSuffix(
+		strings.TrimPrefix(r.URL.Path, "/movies/"), "/review",
+	)
+	request := ApiReviewRequest{}
+	if err := decodeJsonBody(w, r, &request); err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(request.Review) == "" {
+		writeApiError(w, http.StatusBadRequest, "review is required")
+		return
+	}
+	if err := ValidateStarRating(request.Rating); err != nil {
+		writeApiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if request.Watched != "" {
+		if _, err := ParseWatchedDate(request.Watched); err != nil {
+			writeApiError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ctx := r.Context()
+
+	movieUuid, err := a.queries.FindMovie(ctx, imdbId)
+	if err == sql.ErrNoRows {
+		writeApiError(
+			w, http.StatusNotFound, fmt.Sprintf("no movie with id %v", imdbId),
+		)
+		return
+	} else if err != nil {
+		writeInternalError(w, r, "error finding movie", err)
+		return
+	}
+	movie, err := a.queries.GetMovie(ctx, movieUuid)
+	if err != nil {
+		writeInternalError(w, r, "error getting movie", err)
+		return
+	}
+
+	// Review pages link to the movie page, so the title is the file title
+	// just like when update-review parses one.
+	fileTitle, err := GetMovieFileTitle(ctx, a.queries, movieUuid)
+	if err != nil {
+		writeInternalError(w, r, "error getting file title", err)
+		return
+	}
+	reviewPage := &MovieReviewPage{
+		MovieTitle: fileTitle,
+		ImdbId:     movie.ImdbID,
+		Watched:    request.Watched,
+		Liked:      request.Liked,
+		Review:     strings.TrimSpace(request.Review),
+		Rating:     request.Rating,
+	}
+	if request.Watched != "" {
+		if _, err := a.queries.FindMovieWatch(ctx, database.FindMovieWatchParams{
+			ImdbID: movie.ImdbID, Watched: request.Watched,
+		}); err == sql.ErrNoRows {
+			writeApiError(
+				w, http.StatusNotFound, fmt.Sprintf(
+					"no watch of %v on %v", imdbId, request.Watched,
+				),
+			)
+			return
+		} else if err != nil {
+			writeInternalError(w, r, "error finding watch", err)
+			return
+		}
+	}
+	if _, err := SaveReview(a.db, ctx, a.queries, reviewPage); err != nil {
+		writeInternalError(w, r, "error saving review", err)
+		return
+	}
+	// The review page is the review, which was just saved, so it's replaced.
+	filePath := path.Join(a.reviewsDir, ReviewPageFileName(reviewPage))
+	if _, err := WritePageWithMode(
+		a.reviewTemplate, filePath, reviewPage, WRITE_FORCE,
+	); err != nil {
+		writeInternalError(w, r, "error writing review page", err)
+		return
+	}
+	// The movie page links to its reviews, so a new one needs a new link.
+	// Only its generated sections are replaced, the rest is mine.
+	moviePage, err := GetMoviePage(ctx, a.queries, a.taxonomy, movieUuid)
+	if err != nil {
+		writeInternalError(w, r, "error getting movie page", err)
+		return
+	}
+	moviePagePath := path.Join(
+		a.moviesDir, MoviePageFileName(reviewPage.MovieTitle, movie.ImdbID),
+	)
+	result, err := WritePageWithMode(
+		a.movieTemplate, moviePagePath, moviePage, WRITE_MERGE,
+	)
+	if err != nil {
+		writeInternalError(w, r, "error writing movie page", err)
+		return
+	}
+	if result == PAGE_CONFLICTED {
+		log.Printf(
+			"Couldn't find the generated sections of %v, left it alone.",
+			moviePagePath,
+		)
+	}
+
+	review, err := a.queries.GetReview(ctx, reviewPage.Uuid)
+	if err != nil {
+		writeInternalError(w, r, "error getting review", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ApiReview{
+		Uuid:      review.Uuid,
+		MovieUuid: review.MovieUuid,
+		Title:     movie.Title,
+		ImdbId:    movie.ImdbID,
+		Year:      movie.Year,
+		Review:    review.Review,
+		Liked:     review.Liked != 0,
+		Rating:    review.Rating.Float64,
+		Watched:   review.Watched.String,
+	})
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func sendJson(
+	t *testing.T, handler http.Handler, method string, url string,
+	token string, body string, value interface{},
+) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, url, strings.NewReader(body))
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if value != nil && recorder.Code < 300 {
+		if err := json.Unmarshal(recorder.Body.Bytes(), value); err != nil {
+			t.Fatalf("Error decoding %v %v: %v", method, url, err)
+		}
+	}
+	return recorder
+}
+
+func TestWriteApi(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder(
+		"GET", "http://omdbapi.com/?apikey=abc123&i=tt0084777",
+		httpmock.NewJsonResponderOrPanic(200, omdbSampleMovie()),
+	)
+
+	queries := database.New(db)
+	vaultDir := t.TempDir()
+	handler, err := NewWriteApiHandler(
+		db, queries, NewOmdbClient("abc123"), vaultDir, "s3cret",
+	)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	body := `{"imdb_id": "tt0084777", "watched": "2022-05-27", "service": "Shudder", "slasher": true}`
+	recorder := sendJson(t, handler, http.MethodPost, "/watches", "", body, nil)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %v", recorder.Code)
+	}
+	recorder = sendJson(
+		t, handler, http.MethodPost, "/watches", "wrong", body, nil,
+	)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %v", recorder.Code)
+	}
+
+	// The token has to come as a bearer token.
+	request := httptest.NewRequest(
+		http.MethodPost, "/watches", strings.NewReader(body),
+	)
+	request.Header.Set("Authorization", "s3cret")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %v", recorder.Code)
+	}
+
+	// Creating a watch for a new movie pulls it from OMDB.
+	watch := ApiWatch{}
+	recorder = sendJson(
+		t, handler, http.MethodPost, "/watches", "s3cret", body, &watch,
+	)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %v: %v", recorder.Code, recorder.Body)
+	}
+	if watch.Title != "Tenebrae" || watch.Watched != "2022-05-27" {
+		t.Errorf("Unexpected watch %v", watch)
+	}
+	if recorder.Header().Get("Location") != "/watches/"+watch.Uuid {
+		t.Errorf(
+			"Expected location /watches/%v, got %v",
+			watch.Uuid, recorder.Header().Get("Location"),
+		)
+	}
+	moviePath := path.Join(vaultDir, "Movies", "Tenebrae (tt0084777).md")
+	if _, err := os.Stat(moviePath); err != nil {
+		t.Errorf("Expected movie page: %v", err)
+	}
+	watchPath := path.Join(vaultDir, "Watches", "2022-05-27 Tenebrae.md")
+	if _, err := os.Stat(watchPath); err != nil {
+		t.Errorf("Expected watch page: %v", err)
+	}
+
+	recorder = sendJson(t, handler, http.MethodPost, "/watches", "s3cret", body, nil)
+	if recorder.Code != http.StatusConflict {
+		t.Errorf("Expected 409, got %v", recorder.Code)
+	}
+	recorder = sendJson(
+		t, handler, http.MethodPost, "/watches", "s3cret",
+		`{"imdb_id": "tt0084777", "service": "Shudder", "stars": 5}`, nil,
+	)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %v", recorder.Code)
+	}
+
+	// Moving the watch to another day moves the page.
+	updated := ApiWatch{}
+	recorder = sendJson(
+		t, handler, http.MethodPut, "/watches/"+watch.Uuid, "s3cret",
+		`{"watched_at": "2022-10-31T23:30:00-05:00[America/Chicago]", `+
+			`"service": "Shudder", "joe_bob": true}`,
+		&updated,
+	)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v: %v", recorder.Code, recorder.Body)
+	}
+	if updated.Uuid != watch.Uuid || updated.Watched != "2022-10-31" || !updated.JoeBob {
+		t.Errorf("Unexpected watch %v", updated)
+	}
+	if updated.WatchedAt != "2022-10-31T23:30:00-05:00" ||
+		updated.Timezone != "America/Chicago" {
+		t.Errorf("Unexpected watched_at %v %v", updated.WatchedAt, updated.Timezone)
+	}
+	if _, err := os.Stat(watchPath); !os.IsNotExist(err) {
+		t.Errorf("Expected %v to be removed", watchPath)
+	}
+	watchPath = path.Join(vaultDir, "Watches", "2022-10-31 Tenebrae.md")
+	if _, err := os.Stat(watchPath); err != nil {
+		t.Errorf("Expected watch page: %v", err)
+	}
+
+	// Notes on the movie page survive the review rewriting it.
+	moviePageBytes, err := os.ReadFile(moviePath)
+	if err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	if err := os.WriteFile(
+		moviePath, append(moviePageBytes, "\n## My Notes\nGloves.\n"...), 0644,
+	); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+
+	review := ApiReview{}
+	recorder = sendJson(
+		t, handler, http.MethodPut, "/movies/tt0084777/review", "s3cret",
+		`{"review": "Razor sharp.", "liked": true}`, &review,
+	)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v: %v", recorder.Code, recorder.Body)
+	}
+	if review.Review != "Razor sharp." || !review.Liked {
+		t.Errorf("Unexpected review %v", review)
+	}
+	reviewPath := path.Join(vaultDir, "Reviews", "Tenebrae (tt0084777) Review.md")
+	reviewBytes, err := os.ReadFile(reviewPath)
+	if err != nil {
+		t.Fatalf("Expected review page: %v", err)
+	}
+	if !strings.Contains(string(reviewBytes), "Razor sharp.") {
+		t.Errorf("Unexpected review page %v", string(reviewBytes))
+	}
+	revised := ApiReview{}
+	recorder = sendJson(
+		t, handler, http.MethodPut, "/movies/tt0084777/review", "s3cret",
+		`{"review": "Razor sharp, white walls.", "liked": true}`, &revised,
+	)
+	if recorder.Code != http.StatusOK || revised.Uuid != review.Uuid {
+		t.Errorf("Expected %v to be revised, got %v", review.Uuid, revised)
+	}
+	watchReview := ApiReview{}
+	recorder = sendJson(
+		t, handler, http.MethodPut, "/movies/tt0084777/review", "s3cret",
+		`{"review": "Even better at midnight.", "watched": "2022-10-31"}`,
+		&watchReview,
+	)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v: %v", recorder.Code, recorder.Body)
+	}
+	if watchReview.Uuid == review.Uuid || watchReview.Watched != "2022-10-31" {
+		t.Errorf("Unexpected review %v", watchReview)
+	}
+	if _, err := os.Stat(path.Join(
+		vaultDir, "Reviews", "Tenebrae (tt0084777) Review 2022-10-31.md",
+	)); err != nil {
+		t.Errorf("Expected review page for the watch: %v", err)
+	}
+	movieBytes, err := os.ReadFile(
+		path.Join(vaultDir, "Movies", "Tenebrae (tt0084777).md"),
+	)
+	if err != nil {
+		t.Fatalf("Expected movie page: %v", err)
+	}
+	if !strings.Contains(
+		string(movieBytes), "review:: [[Tenebrae (tt0084777) Review 2022-10-31]]",
+	) {
+		t.Errorf("Expected a link to the review in %v", string(movieBytes))
+	}
+	if !strings.Contains(string(movieBytes), "## My Notes\nGloves.") {
+		t.Errorf("Expected the notes to be kept in %v", string(movieBytes))
+	}
+	recorder = sendJson(
+		t, handler, http.MethodPut, "/movies/tt0084777/review", "s3cret",
+		`{"review": "Never watched then.", "watched": "2022-11-01"}`, nil,
+	)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %v", recorder.Code)
+	}
+	reviews := []ApiReview{}
+	recorder = getJson(t, handler, "/movies/tt0084777/reviews", &reviews)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %v: %v", recorder.Code, recorder.Body)
+	}
+	if len(reviews) != 2 || len(reviews[0].Revisions) != 2 ||
+		reviews[0].Revisions[0].Review != "Razor sharp." ||
+		reviews[0].Review != "Razor sharp, white walls." {
+		t.Errorf("Unexpected reviews %v", reviews)
+	}
+	recorder = sendJson(
+		t, handler, http.MethodPut, "/movies/tt0000000/review", "s3cret",
+		`{"review": "Never seen it."}`, nil,
+	)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %v", recorder.Code)
+	}
+
+	recorder = sendJson(
+		t, handler, http.MethodDelete, "/watches/"+watch.Uuid, "s3cret", "", nil,
+	)
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %v", recorder.Code)
+	}
+	if _, err := os.Stat(watchPath); !os.IsNotExist(err) {
+		t.Errorf("Expected %v to be removed", watchPath)
+	}
+	recorder = getJson(t, handler, "/watches/"+watch.Uuid, nil)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %v", recorder.Code)
+	}
+
+	// A watch whose page can't be written doesn't get saved either.
+	watchPath = path.Join(vaultDir, "Watches", "2022-05-27 Tenebrae.md")
+	if err := os.Mkdir(watchPath, 0755); err != nil {
+		t.Fatalf("Encountered error: %v", err)
+	}
+	recorder = sendJson(
+		t, handler, http.MethodPost, "/watches", "s3cret", body, nil,
+	)
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %v", recorder.Code)
+	}
+	if _, err := queries.FindMovieWatch(
+		context.Background(), database.FindMovieWatchParams{
+			ImdbID: "tt0084777", Watched: "2022-05-27",
+		},
+	); err != sql.ErrNoRows {
+		t.Errorf("Expected no watch, got %v", err)
+	}
+
+	// The read-only handler doesn't have any of the write routes.
+	readOnly := NewApiHandler(queries)
+	recorder = sendJson(t, readOnly, http.MethodPost, "/watches", "s3cret", body, nil)
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %v", recorder.Code)
+	}
+}
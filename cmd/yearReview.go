@@ -0,0 +1,414 @@
+/*
+Copyright © 2022 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+// yearReviewCmd represents the yearReview command
+var yearReviewCmd = &cobra.Command{
+	Use:   "year-review <year> <vault>",
+	Short: "Writes a year in review page into the vault.",
+	Run:   yearReview,
+	Args:  cobra.ExactArgs(2),
+}
+
+func init() {
+	rootCmd.AddCommand(yearReviewCmd)
+
+	yearReviewCmd.Flags().IntP(
+		"top", "n", 10, "The number of directors and actors to list.",
+	)
+	yearReviewCmd.Flags().StringP(
+		"template", "t", "",
+		"Path to a template to use instead of the built in one.",
+	)
+}
+
+var YEAR_REVIEW_TEMPLATE = `
+# Year in Review: {{.Year}}
+
+## Summary
+total_watches:: {{.TotalWatches}}
+first_time:: {{.FirstTimeWatches}}
+rewatches:: {{.Rewatches}}
+
+## Watches by Month
+{{range .Months}}- {{.Month}}: {{.Count}}
+{{end}}
+## Most Watched Directors
+{{range .Directors}}- [[{{.Link}}]]: {{.Count}}
+{{end}}
+## Most Watched Actors
+{{range .Actors}}- [[{{.Link}}]]: {{.Count}}
+{{end}}
+## Runtime
+longest:: {{with .Longest}}[[{{.FileTitle}} ({{.ImdbId}})]] ({{.RuntimeMinutes}} min){{end}}
+shortest:: {{with .Shortest}}[[{{.FileTitle}} ({{.ImdbId}})]] ({{.RuntimeMinutes}} min){{end}}
+
+## Ratings
+highest_imdb:: {{with .HighestImdb}}[[{{.FileTitle}} ({{.ImdbId}})]] ({{.Value}}){{end}}
+lowest_imdb:: {{with .LowestImdb}}[[{{.FileTitle}} ({{.ImdbId}})]] ({{.Value}}){{end}}
+highest_rotten_tomatoes:: {{with .HighestRottenTomatoes}}[[{{.FileTitle}} ({{.ImdbId}})]] ({{.Value}}){{end}}
+lowest_rotten_tomatoes:: {{with .LowestRottenTomatoes}}[[{{.FileTitle}} ({{.ImdbId}})]] ({{.Value}}){{end}}
+
+## Liked
+{{range .Liked}}- [[{{.FileTitle}} ({{.ImdbId}})]]
+{{end}}
+## Watches
+{{range .Watches}}- [[{{.Watched}} {{.FileTitle}}|{{.Watched}}]] [[{{.FileTitle}} ({{.ImdbId}})]]{{if not .FirstTime}} (rewatch){{end}}
+{{end}}
+## Tags
+#year-review
+`
+
+const IMDB_RATING_SOURCE = "Internet Movie Database"
+const ROTTEN_TOMATOES_RATING_SOURCE = "Rotten Tomatoes"
+
+type YearReviewWatch struct {
+	Title     string
+	FileTitle string
+	ImdbId    string
+	Watched   string
+	Service   string
+	FirstTime bool
+}
+
+type YearReviewMonth struct {
+	Month string
+	Count int
+}
+
+type YearReviewName struct {
+	Name  string
+	Count int
+}
+
+// Link is the name as a link to the person's page, see pageLink.
+func (n YearReviewName) Link() string { return pageLink(n.Name) }
+
+type YearReviewMovie struct {
+	Title          string
+	FileTitle      string
+	ImdbId         string
+	RuntimeMinutes int
+}
+
+type YearReviewRating struct {
+	Title     string
+	FileTitle string
+	ImdbId    string
+	Value     string
+	score     float64
+}
+
+type YearReviewPage struct {
+	Year                  int
+	TotalWatches          int
+	FirstTimeWatches      int
+	Rewatches             int
+	Months                []YearReviewMonth
+	Directors             []YearReviewName
+	Actors                []YearReviewName
+	Longest               *YearReviewMovie
+	Shortest              *YearReviewMovie
+	HighestImdb           *YearReviewRating
+	LowestImdb            *YearReviewRating
+	HighestRottenTomatoes *YearReviewRating
+	LowestRottenTomatoes  *YearReviewRating
+	Liked                 []YearReviewMovie
+	Watches               []YearReviewWatch
+}
+
+// YearBounds returns the watched date range for a year, inclusive of the
+// first and exclusive of the second.
+func YearBounds(year int) (string, string) {
+	return fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-01-01", year+1)
+}
+
+func CreateYearReviewPage(
+	year int,
+	watches []database.GetMovieWatchesBetweenRow,
+	directors []database.GetDirectorWatchCountsBetweenRow,
+	actors []database.GetActorWatchCountsBetweenRow,
+	ratings []database.GetRatingsForMoviesWatchedBetweenRow,
+	liked []database.GetLikedReviewsForMoviesWatchedBetweenRow,
+	vaultNames VaultNames,
+) (*YearReviewPage, error) {
+	page := YearReviewPage{
+		Year:      year,
+		Months:    make([]YearReviewMonth, 12),
+		Directors: make([]YearReviewName, len(directors)),
+		Actors:    make([]YearReviewName, len(actors)),
+		Liked:     make([]YearReviewMovie, len(liked)),
+		Watches:   make([]YearReviewWatch, len(watches)),
+	}
+	for ii := range page.Months {
+		page.Months[ii].Month = time.Month(ii + 1).String()
+	}
+
+	for ii := range watches {
+		watch := &watches[ii]
+		watched, err := ParseWatchedDate(watch.Watched)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error parsing watched date %v for %v: %v",
+				watch.Watched, watch.MovieTitle, err,
+			)
+		}
+		if watched.Year() != year {
+			return nil, fmt.Errorf(
+				"watch of %v on %v is not in %v",
+				watch.MovieTitle, watch.Watched, year,
+			)
+		}
+		page.Months[watched.Month()-1].Count += 1
+
+		page.TotalWatches += 1
+		if watch.FirstTime != 0 {
+			page.FirstTimeWatches += 1
+		} else {
+			page.Rewatches += 1
+		}
+
+		page.Watches[ii] = YearReviewWatch{
+			Title:     watch.MovieTitle,
+			FileTitle: vaultNames.FileTitle(watch.ImdbID, watch.MovieTitle),
+			ImdbId:    watch.ImdbID,
+			Watched:   watch.Watched,
+			Service:   watch.Service,
+			FirstTime: watch.FirstTime != 0,
+		}
+
+		// Ties go to whichever was watched first.
+		if !watch.RuntimeMinutes.Valid {
+			continue
+		}
+		movie := YearReviewMovie{
+			Title:          watch.MovieTitle,
+			FileTitle:      vaultNames.FileTitle(watch.ImdbID, watch.MovieTitle),
+			ImdbId:         watch.ImdbID,
+			RuntimeMinutes: int(watch.RuntimeMinutes.Int64),
+		}
+		if page.Longest == nil ||
+			movie.RuntimeMinutes > page.Longest.RuntimeMinutes {
+			longest := movie
+			page.Longest = &longest
+		}
+		if page.Shortest == nil ||
+			movie.RuntimeMinutes < page.Shortest.RuntimeMinutes {
+			shortest := movie
+			page.Shortest = &shortest
+		}
+	}
+
+	for ii := range directors {
+		page.Directors[ii] = YearReviewName{
+			Name:  directors[ii].Name,
+			Count: int(directors[ii].NumWatches),
+		}
+	}
+	for ii := range actors {
+		page.Actors[ii] = YearReviewName{
+			Name:  actors[ii].Name,
+			Count: int(actors[ii].NumWatches),
+		}
+	}
+
+	for ii := range ratings {
+		rating := &ratings[ii]
+		if rating.Source != IMDB_RATING_SOURCE &&
+			rating.Source != ROTTEN_TOMATOES_RATING_SOURCE {
+			continue
+		}
+		score, err := ParseRatingValue(rating.Value)
+		if err != nil {
+			log.Printf(
+				"Unable to parse %v rating %v for %v, skipping.",
+				rating.Source, rating.Value, rating.Title,
+			)
+			continue
+		}
+		yearReviewRating := YearReviewRating{
+			Title:     rating.Title,
+			FileTitle: vaultNames.FileTitle(rating.ImdbID, rating.Title),
+			ImdbId:    rating.ImdbID,
+			Value:     rating.Value,
+			score:     score,
+		}
+		if rating.Source == IMDB_RATING_SOURCE {
+			page.HighestImdb, page.LowestImdb = updateRatingExtremes(
+				&yearReviewRating, page.HighestImdb, page.LowestImdb,
+			)
+		} else {
+			page.HighestRottenTomatoes, page.LowestRottenTomatoes =
+				updateRatingExtremes(
+					&yearReviewRating,
+					page.HighestRottenTomatoes,
+					page.LowestRottenTomatoes,
+				)
+		}
+	}
+
+	for ii := range liked {
+		page.Liked[ii] = YearReviewMovie{
+			Title:     liked[ii].Title,
+			FileTitle: vaultNames.FileTitle(liked[ii].ImdbID, liked[ii].Title),
+			ImdbId:    liked[ii].ImdbID,
+		}
+	}
+
+	return &page, nil
+}
+
+func updateRatingExtremes(
+	rating *YearReviewRating, highest *YearReviewRating, lowest *YearReviewRating,
+) (*YearReviewRating, *YearReviewRating) {
+	if highest == nil || rating.score > highest.score {
+		highest = rating
+	}
+	if lowest == nil || rating.score < lowest.score {
+		lowest = rating
+	}
+	return highest, lowest
+}
+
+// GetYearReviewPage runs the queries for a year and assembles the page,
+// keeping the top directors and actors.
+func GetYearReviewPage(
+	ctx context.Context, queries *database.Queries, year int, top int,
+) (*YearReviewPage, error) {
+	start, end := YearBounds(year)
+	log.Printf("Getting movie watches between %v and %v.", start, end)
+	watches, err := queries.GetMovieWatchesBetween(
+		ctx, database.GetMovieWatchesBetweenParams{
+			Watched: start, Watched_2: end,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting movie watches: %v", err)
+	}
+	directors, err := queries.GetDirectorWatchCountsBetween(
+		ctx, database.GetDirectorWatchCountsBetweenParams{
+			Watched: start, Watched_2: end, Limit: int64(top),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting directors: %v", err)
+	}
+	actors, err := queries.GetActorWatchCountsBetween(
+		ctx, database.GetActorWatchCountsBetweenParams{
+			Watched: start, Watched_2: end, Limit: int64(top),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting actors: %v", err)
+	}
+	ratings, err := queries.GetRatingsForMoviesWatchedBetween(
+		ctx, database.GetRatingsForMoviesWatchedBetweenParams{
+			Watched: start, Watched_2: end,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting ratings: %v", err)
+	}
+	liked, err := queries.GetLikedReviewsForMoviesWatchedBetween(
+		ctx, database.GetLikedReviewsForMoviesWatchedBetweenParams{
+			Watched: start, Watched_2: end,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting liked reviews: %v", err)
+	}
+	vaultNames, err := LoadVaultNames(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateYearReviewPage(
+		year, watches, directors, actors, ratings, liked, vaultNames,
+	)
+}
+
+func yearReview(cmd *cobra.Command, args []string) {
+	year, err := strconv.Atoi(args[0])
+	if err != nil {
+		log.Panicf("Error parsing year %v: %v", args[0], err)
+	}
+	vaultDir := args[1]
+
+	top, err := cmd.Flags().GetInt("top")
+	if err != nil {
+		log.Panicf("Error obtaining top: %v", err)
+	}
+	templateFile, err := cmd.Flags().GetString("template")
+	if err != nil {
+		log.Panicf("Error obtaining template: %v", err)
+	}
+
+	yearReviewTemplateText := YEAR_REVIEW_TEMPLATE
+	if templateFile != "" {
+		log.Printf("Loading year in review template from %v", templateFile)
+		templateBytes, err := os.ReadFile(templateFile)
+		if err != nil {
+			log.Panicf("Error reading template %v: %v", templateFile, err)
+		}
+		yearReviewTemplateText = string(templateBytes)
+	}
+	yearReviewTemplate, err := template.New("year_review").Parse(
+		yearReviewTemplateText,
+	)
+	if err != nil {
+		log.Panicf("Unable to parse year in review template: %v", err)
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", DB)
+	if err != nil {
+		log.Panicf("Error opening database %v: %v", DB, err)
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	page, err := GetYearReviewPage(ctx, queries, year, top)
+	if err != nil {
+		log.Panicf("Error creating year in review page: %v", err)
+	}
+
+	reviewsDir := path.Join(vaultDir, "Reviews")
+	if err = os.Mkdir(reviewsDir, 0755); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			log.Printf("%v exists", reviewsDir)
+		} else {
+			log.Panicf("Error creating %v", reviewsDir)
+		}
+	}
+	pageFilePath := path.Join(
+		reviewsDir, fmt.Sprintf("Year in Review %v.md", year),
+	)
+	pageFile, err := os.Create(pageFilePath)
+	if err != nil {
+		log.Panicf("Error opening %v: %v", pageFilePath, err)
+	}
+	defer pageFile.Close()
+	if err := yearReviewTemplate.Execute(pageFile, page); err != nil {
+		log.Panicf("Error writing year in review page: %v", err)
+	}
+	log.Printf(
+		"Wrote %v with %v watches.", pageFilePath, page.TotalWatches,
+	)
+}
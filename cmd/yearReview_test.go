@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/timothyrenner/movies-app/database"
+)
+
+func TestCreateYearReviewPage(t *testing.T) {
+	watches := []database.GetMovieWatchesBetweenRow{
+		{
+			MovieTitle:     "Tenebrae",
+			ImdbID:         "tt0084777",
+			Watched:        "2022-05-27",
+			Service:        "Shudder",
+			FirstTime:      0,
+			RuntimeMinutes: sql.NullInt64{Int64: 101, Valid: true},
+		}, {
+			MovieTitle:     "Grizzly 2: Revenge",
+			ImdbID:         "tt0093119",
+			Watched:        "2022-07-01",
+			Service:        "Shudder",
+			FirstTime:      1,
+			RuntimeMinutes: sql.NullInt64{Int64: 74, Valid: true},
+		}, {
+			MovieTitle: "Prey",
+			ImdbID:     "tt11866324",
+			Watched:    "2022-08-05",
+			Service:    "Hulu",
+			FirstTime:  1,
+		},
+	}
+	directors := []database.GetDirectorWatchCountsBetweenRow{
+		{Name: "Dario Argento", NumWatches: 1},
+	}
+	ratings := []database.GetRatingsForMoviesWatchedBetweenRow{
+		{
+			Title:  "Tenebrae",
+			ImdbID: "tt0084777",
+			Source: "Internet Movie Database",
+			Value:  "7.0/10",
+		}, {
+			Title:  "Tenebrae",
+			ImdbID: "tt0084777",
+			Source: "Rotten Tomatoes",
+			Value:  "77%",
+		}, {
+			Title:  "Grizzly 2: Revenge",
+			ImdbID: "tt0093119",
+			Source: "Internet Movie Database",
+			Value:  "2.6/10",
+		},
+	}
+	liked := []database.GetLikedReviewsForMoviesWatchedBetweenRow{
+		{Title: "Tenebrae", ImdbID: "tt0084777"},
+	}
+
+	answer, err := CreateYearReviewPage(
+		2022, watches, directors, nil, ratings, liked, nil,
+	)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+
+	if answer.TotalWatches != 3 {
+		t.Errorf("Expected 3 watches, got %v", answer.TotalWatches)
+	}
+	if answer.FirstTimeWatches != 2 {
+		t.Errorf("Expected 2 first time watches, got %v", answer.FirstTimeWatches)
+	}
+	if answer.Rewatches != 1 {
+		t.Errorf("Expected 1 rewatch, got %v", answer.Rewatches)
+	}
+	monthTruth := []int{0, 0, 0, 0, 1, 0, 1, 1, 0, 0, 0, 0}
+	for ii := range monthTruth {
+		if answer.Months[ii].Count != monthTruth[ii] {
+			t.Errorf(
+				"Expected %v watches in %v, got %v",
+				monthTruth[ii], answer.Months[ii].Month, answer.Months[ii].Count,
+			)
+		}
+	}
+
+	longestTruth := &YearReviewMovie{
+		Title:          "Tenebrae",
+		FileTitle:      "Tenebrae",
+		ImdbId:         "tt0084777",
+		RuntimeMinutes: 101,
+	}
+	if !cmp.Equal(longestTruth, answer.Longest) {
+		t.Errorf("Expected %v, got %v", longestTruth, answer.Longest)
+	}
+	shortestTruth := &YearReviewMovie{
+		Title:          "Grizzly 2: Revenge",
+		FileTitle:      "Grizzly 2 Revenge",
+		ImdbId:         "tt0093119",
+		RuntimeMinutes: 74,
+	}
+	if !cmp.Equal(shortestTruth, answer.Shortest) {
+		t.Errorf("Expected %v, got %v", shortestTruth, answer.Shortest)
+	}
+
+	ignoreScore := cmpopts.IgnoreUnexported(YearReviewRating{})
+	highestImdbTruth := &YearReviewRating{
+		Title:     "Tenebrae",
+		FileTitle: "Tenebrae",
+		ImdbId:    "tt0084777",
+		Value:     "7.0/10",
+	}
+	if !cmp.Equal(highestImdbTruth, answer.HighestImdb, ignoreScore) {
+		t.Errorf("Expected %v, got %v", highestImdbTruth, answer.HighestImdb)
+	}
+	lowestImdbTruth := &YearReviewRating{
+		Title:     "Grizzly 2: Revenge",
+		FileTitle: "Grizzly 2 Revenge",
+		ImdbId:    "tt0093119",
+		Value:     "2.6/10",
+	}
+	if !cmp.Equal(lowestImdbTruth, answer.LowestImdb, ignoreScore) {
+		t.Errorf("Expected %v, got %v", lowestImdbTruth, answer.LowestImdb)
+	}
+	if answer.HighestRottenTomatoes != answer.LowestRottenTomatoes {
+		t.Errorf(
+			"Expected a single rotten tomatoes rating, got %v and %v",
+			answer.HighestRottenTomatoes, answer.LowestRottenTomatoes,
+		)
+	}
+
+	directorsTruth := []YearReviewName{{Name: "Dario Argento", Count: 1}}
+	if !cmp.Equal(directorsTruth, answer.Directors) {
+		t.Errorf("Expected %v, got %v", directorsTruth, answer.Directors)
+	}
+	likedTruth := []YearReviewMovie{
+		{Title: "Tenebrae", FileTitle: "Tenebrae", ImdbId: "tt0084777"},
+	}
+	if !cmp.Equal(likedTruth, answer.Liked) {
+		t.Errorf("Expected %v, got %v", likedTruth, answer.Liked)
+	}
+
+	// Watches from the wrong year are an error.
+	if _, err := CreateYearReviewPage(
+		2021, watches, nil, nil, nil, nil, nil,
+	); err == nil {
+		t.Errorf("Expected error, got nil.")
+	}
+}
+
+func TestGetMovieWatchesBetween(t *testing.T) {
+	db, m := setupDatabase()
+	defer teardownDatabase(db, m)
+
+	queries := database.New(db)
+	ctx := context.Background()
+	// Both credits are the same person, so they're counted once.
+	moviePage := sampleMoviePage()
+	moviePage.Directors = []string{"Dario Argento", "Dario Argento (uncredited)"}
+	movieDetails, err := InsertMovieDetails(db, ctx, queries, moviePage, nil)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+
+	for _, watched := range []string{"2021-12-31", "2022-05-27", "2023-01-01"} {
+		movieWatch := sampleMovieWatchPage()
+		movieWatch.Watched = watched
+		if err := queries.InsertMovieWatch(
+			ctx, *CreateInsertMovieWatchParams(movieWatch, movieDetails.Movie),
+		); err != nil {
+			t.Errorf("Encountered error: %v", err)
+		}
+	}
+
+	start, end := YearBounds(2022)
+	answer, err := queries.GetMovieWatchesBetween(
+		ctx, database.GetMovieWatchesBetweenParams{
+			Watched: start, Watched_2: end,
+		},
+	)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	if len(answer) != 1 {
+		t.Fatalf("Expected 1 watch, got %v", len(answer))
+	}
+	if answer[0].Watched != "2022-05-27" {
+		t.Errorf("Expected 2022-05-27, got %v", answer[0].Watched)
+	}
+
+	directors, err := queries.GetDirectorWatchCountsBetween(
+		ctx, database.GetDirectorWatchCountsBetweenParams{
+			Watched: start, Watched_2: end, Limit: 10,
+		},
+	)
+	if err != nil {
+		t.Errorf("Encountered error: %v", err)
+	}
+	directorsTruth := []database.GetDirectorWatchCountsBetweenRow{
+		{Name: "Dario Argento", NumWatches: 1},
+	}
+	if !cmp.Equal(directorsTruth, directors) {
+		t.Errorf("Expected %v, got %v", directorsTruth, directors)
+	}
+}
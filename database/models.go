@@ -8,6 +8,34 @@ import (
 	"database/sql"
 )
 
+type Genre struct {
+	ID              int64
+	Name            string
+	Slug            string
+	CreatedDatetime int64
+}
+
+type GenreAlias struct {
+	Alias           string
+	GenreID         int64
+	CreatedDatetime int64
+}
+
+type List struct {
+	ID              int64
+	Name            string
+	Description     sql.NullString
+	CreatedDatetime int64
+}
+
+type ListItem struct {
+	ListID          int64
+	MovieUuid       string
+	Position        int64
+	Comment         sql.NullString
+	CreatedDatetime int64
+}
+
 type Movie struct {
 	Uuid            string
 	Title           string
@@ -29,6 +57,7 @@ type Movie struct {
 	ImdbID          string
 	RuntimeMinutes  sql.NullInt64
 	WallpaperFu     int64
+	Poster          sql.NullString
 }
 
 type MovieActor struct {
@@ -38,6 +67,14 @@ type MovieActor struct {
 	CreatedDatetime int64
 }
 
+type MovieCredit struct {
+	MovieUuid       string
+	PersonID        int64
+	Role            string
+	BillingOrder    int64
+	CreatedDatetime int64
+}
+
 type MovieDirector struct {
 	Uuid            string
 	MovieUuid       string
@@ -71,6 +108,10 @@ type MovieWatch struct {
 	ImdbID          string
 	Watched         string
 	Notes           sql.NullString
+	WatchedAt       sql.NullString
+	Timezone        sql.NullString
+	Rating          sql.NullFloat64
+	UpdatedDatetime sql.NullInt64
 }
 
 type MovieWriter struct {
@@ -80,6 +121,19 @@ type MovieWriter struct {
 	CreatedDatetime int64
 }
 
+type Person struct {
+	ID              int64
+	Name            string
+	ImdbID          sql.NullString
+	CreatedDatetime int64
+}
+
+type PersonAlias struct {
+	Alias           string
+	PersonID        int64
+	CreatedDatetime int64
+}
+
 type Review struct {
 	Uuid            string
 	MovieUuid       string
@@ -87,9 +141,38 @@ type Review struct {
 	Review          string
 	Liked           int64
 	CreatedDatetime int64
+	Rating          sql.NullFloat64
+	MovieWatchUuid  sql.NullString
+	UpdatedDatetime int64
+}
+
+type ReviewRevision struct {
+	ID              int64
+	ReviewUuid      string
+	Review          string
+	Liked           int64
+	Rating          sql.NullFloat64
+	CreatedDatetime int64
 }
 
 type UuidGrist struct {
 	Uuid    string
 	GristID int64
 }
+
+type VaultName struct {
+	MovieUuid       string
+	FileTitle       string
+	CreatedDatetime int64
+}
+
+type Watchlist struct {
+	Uuid            string
+	ImdbID          string
+	Title           string
+	Year            string
+	Priority        int64
+	Source          sql.NullString
+	Added           string
+	CreatedDatetime int64
+}
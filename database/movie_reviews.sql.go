@@ -7,63 +7,304 @@ package database
 
 import (
 	"context"
+	"database/sql"
 )
 
-const getReviewForMovie = `-- name: GetReviewForMovie :one
-SELECT uuid,
-    movie_uuid,
-    movie_title,
-    review,
-    liked
+const findReview = `-- name: FindReview :one
+SELECT uuid
 FROM review
-WHERE movie_title = ?
+WHERE movie_uuid = ?
+    AND movie_watch_uuid IS ?
+ORDER BY created_datetime DESC
+LIMIT 1
 `
 
-type GetReviewForMovieRow struct {
-	Uuid       string
-	MovieUuid  string
-	MovieTitle string
-	Review     string
-	Liked      int64
+type FindReviewParams struct {
+	MovieUuid      string
+	MovieWatchUuid sql.NullString
+}
+
+func (q *Queries) FindReview(ctx context.Context, arg FindReviewParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, findReview, arg.MovieUuid, arg.MovieWatchUuid)
+	var uuid string
+	err := row.Scan(&uuid)
+	return uuid, err
+}
+
+const getAllReviews = `-- name: GetAllReviews :many
+SELECT r.uuid,
+    r.movie_uuid,
+    r.movie_title,
+    m.imdb_id,
+    r.movie_watch_uuid,
+    w.watched,
+    r.review,
+    r.liked,
+    r.rating,
+    r.created_datetime,
+    r.updated_datetime
+FROM review AS r
+    INNER JOIN movie AS m ON m.uuid = r.movie_uuid
+    LEFT JOIN movie_watch AS w ON w.uuid = r.movie_watch_uuid
+ORDER BY r.created_datetime,
+    w.watched,
+    r.uuid
+`
+
+type GetAllReviewsRow struct {
+	Uuid            string
+	MovieUuid       string
+	MovieTitle      string
+	ImdbID          string
+	MovieWatchUuid  sql.NullString
+	Watched         sql.NullString
+	Review          string
+	Liked           int64
+	Rating          sql.NullFloat64
+	CreatedDatetime int64
+	UpdatedDatetime int64
+}
+
+func (q *Queries) GetAllReviews(ctx context.Context) ([]GetAllReviewsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllReviews)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAllReviewsRow
+	for rows.Next() {
+		var i GetAllReviewsRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.MovieUuid,
+			&i.MovieTitle,
+			&i.ImdbID,
+			&i.MovieWatchUuid,
+			&i.Watched,
+			&i.Review,
+			&i.Liked,
+			&i.Rating,
+			&i.CreatedDatetime,
+			&i.UpdatedDatetime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *Queries) GetReviewForMovie(ctx context.Context, movieTitle string) (GetReviewForMovieRow, error) {
-	row := q.db.QueryRowContext(ctx, getReviewForMovie, movieTitle)
-	var i GetReviewForMovieRow
+const getReview = `-- name: GetReview :one
+SELECT r.uuid,
+    r.movie_uuid,
+    r.movie_title,
+    m.imdb_id,
+    r.movie_watch_uuid,
+    w.watched,
+    r.review,
+    r.liked,
+    r.rating,
+    r.created_datetime,
+    r.updated_datetime
+FROM review AS r
+    INNER JOIN movie AS m ON m.uuid = r.movie_uuid
+    LEFT JOIN movie_watch AS w ON w.uuid = r.movie_watch_uuid
+WHERE r.uuid = ?
+`
+
+type GetReviewRow struct {
+	Uuid            string
+	MovieUuid       string
+	MovieTitle      string
+	ImdbID          string
+	MovieWatchUuid  sql.NullString
+	Watched         sql.NullString
+	Review          string
+	Liked           int64
+	Rating          sql.NullFloat64
+	CreatedDatetime int64
+	UpdatedDatetime int64
+}
+
+func (q *Queries) GetReview(ctx context.Context, uuid string) (GetReviewRow, error) {
+	row := q.db.QueryRowContext(ctx, getReview, uuid)
+	var i GetReviewRow
 	err := row.Scan(
 		&i.Uuid,
 		&i.MovieUuid,
 		&i.MovieTitle,
+		&i.ImdbID,
+		&i.MovieWatchUuid,
+		&i.Watched,
 		&i.Review,
 		&i.Liked,
+		&i.Rating,
+		&i.CreatedDatetime,
+		&i.UpdatedDatetime,
 	)
 	return i, err
 }
 
+const getReviewRevisions = `-- name: GetReviewRevisions :many
+SELECT id,
+    review_uuid,
+    review,
+    liked,
+    rating,
+    created_datetime
+FROM review_revision
+WHERE review_uuid = ?
+ORDER BY id
+`
+
+func (q *Queries) GetReviewRevisions(ctx context.Context, reviewUuid string) ([]ReviewRevision, error) {
+	rows, err := q.db.QueryContext(ctx, getReviewRevisions, reviewUuid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReviewRevision
+	for rows.Next() {
+		var i ReviewRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.ReviewUuid,
+			&i.Review,
+			&i.Liked,
+			&i.Rating,
+			&i.CreatedDatetime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReviewsForMovie = `-- name: GetReviewsForMovie :many
+SELECT r.uuid,
+    r.movie_uuid,
+    r.movie_title,
+    m.imdb_id,
+    r.movie_watch_uuid,
+    w.watched,
+    r.review,
+    r.liked,
+    r.rating,
+    r.created_datetime,
+    r.updated_datetime
+FROM review AS r
+    INNER JOIN movie AS m ON m.uuid = r.movie_uuid
+    LEFT JOIN movie_watch AS w ON w.uuid = r.movie_watch_uuid
+WHERE m.imdb_id = ?
+ORDER BY r.created_datetime,
+    w.watched,
+    r.uuid
+`
+
+type GetReviewsForMovieRow struct {
+	Uuid            string
+	MovieUuid       string
+	MovieTitle      string
+	ImdbID          string
+	MovieWatchUuid  sql.NullString
+	Watched         sql.NullString
+	Review          string
+	Liked           int64
+	Rating          sql.NullFloat64
+	CreatedDatetime int64
+	UpdatedDatetime int64
+}
+
+func (q *Queries) GetReviewsForMovie(ctx context.Context, imdbID string) ([]GetReviewsForMovieRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReviewsForMovie, imdbID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReviewsForMovieRow
+	for rows.Next() {
+		var i GetReviewsForMovieRow
+		if err := rows.Scan(
+			&i.Uuid,
+			&i.MovieUuid,
+			&i.MovieTitle,
+			&i.ImdbID,
+			&i.MovieWatchUuid,
+			&i.Watched,
+			&i.Review,
+			&i.Liked,
+			&i.Rating,
+			&i.CreatedDatetime,
+			&i.UpdatedDatetime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertReview = `-- name: InsertReview :exec
-INSERT INTO review (uuid, movie_uuid, movie_title, review, liked)
-VALUES (?, ?, ?, ?, ?) ON CONFLICT (movie_uuid) DO
-UPDATE
-SET review = excluded.review,
-    liked = excluded.liked
+INSERT INTO review (
+        uuid,
+        movie_uuid,
+        movie_title,
+        movie_watch_uuid,
+        review,
+        liked,
+        rating
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?)
 `
 
 type InsertReviewParams struct {
-	Uuid       string
-	MovieUuid  string
-	MovieTitle string
+	Uuid           string
+	MovieUuid      string
+	MovieTitle     string
+	MovieWatchUuid sql.NullString
+	Review         string
+	Liked          int64
+	Rating         sql.NullFloat64
+}
+
+func (q *Queries) InsertReview(ctx context.Context, arg InsertReviewParams) error {
+	_, err := q.db.ExecContext(ctx, insertReview, arg.Uuid, arg.MovieUuid, arg.MovieTitle, arg.MovieWatchUuid, arg.Review, arg.Liked, arg.Rating)
+	return err
+}
+
+const insertReviewRevision = `-- name: InsertReviewRevision :exec
+INSERT INTO review_revision (review_uuid, review, liked, rating)
+VALUES (?, ?, ?, ?)
+`
+
+type InsertReviewRevisionParams struct {
+	ReviewUuid string
 	Review     string
 	Liked      int64
+	Rating     sql.NullFloat64
 }
 
-func (q *Queries) InsertReview(ctx context.Context, arg InsertReviewParams) error {
-	_, err := q.db.ExecContext(ctx, insertReview,
-		arg.Uuid,
-		arg.MovieUuid,
-		arg.MovieTitle,
-		arg.Review,
-		arg.Liked,
-	)
+func (q *Queries) InsertReviewRevision(ctx context.Context, arg InsertReviewRevisionParams) error {
+	_, err := q.db.ExecContext(ctx, insertReviewRevision, arg.ReviewUuid, arg.Review, arg.Liked, arg.Rating)
 	return err
 }
 
@@ -80,3 +321,28 @@ func (q *Queries) UpdateMovieUuidForReview(ctx context.Context, arg UpdateMovieU
 	_, err := q.db.ExecContext(ctx, updateMovieUuidForReview, arg.MovieUuid, arg.Uuid)
 	return err
 }
+
+const updateReview = `-- name: UpdateReview :exec
+UPDATE review
+SET movie_title = ?,
+    movie_watch_uuid = ?,
+    review = ?,
+    liked = ?,
+    rating = ?,
+    updated_datetime = UNIXEPOCH()
+WHERE uuid = ?
+`
+
+type UpdateReviewParams struct {
+	MovieTitle     string
+	MovieWatchUuid sql.NullString
+	Review         string
+	Liked          int64
+	Rating         sql.NullFloat64
+	Uuid           string
+}
+
+func (q *Queries) UpdateReview(ctx context.Context, arg UpdateReviewParams) error {
+	_, err := q.db.ExecContext(ctx, updateReview, arg.MovieTitle, arg.MovieWatchUuid, arg.Review, arg.Liked, arg.Rating, arg.Uuid)
+	return err
+}
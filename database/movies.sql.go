@@ -170,7 +170,10 @@ SELECT w.uuid,
     m.beast,
     m.godzilla,
     m.zombies,
-    m.wallpaper_fu
+    m.wallpaper_fu,
+    w.watched_at,
+    w.timezone,
+    w.rating
 FROM movie_watch AS w
     INNER JOIN movie AS m ON m.uuid = w.movie_uuid
 `
@@ -192,6 +195,9 @@ type GetAllMovieWatchesRow struct {
 	Godzilla    int64
 	Zombies     int64
 	WallpaperFu int64
+	WatchedAt   sql.NullString
+	Timezone    sql.NullString
+	Rating      sql.NullFloat64
 }
 
 func (q *Queries) GetAllMovieWatches(ctx context.Context) ([]GetAllMovieWatchesRow, error) {
@@ -220,6 +226,9 @@ func (q *Queries) GetAllMovieWatches(ctx context.Context) ([]GetAllMovieWatchesR
 			&i.Godzilla,
 			&i.Zombies,
 			&i.WallpaperFu,
+			&i.WatchedAt,
+			&i.Timezone,
+			&i.Rating,
 		); err != nil {
 			return nil, err
 		}
@@ -267,6 +276,7 @@ const getGenreNamesForMovie = `-- name: GetGenreNamesForMovie :many
 SELECT name
 FROM movie_genre
 WHERE movie_uuid = ?
+ORDER BY rowid
 `
 
 func (q *Queries) GetGenreNamesForMovie(ctx context.Context, movieUuid string) ([]string, error) {
@@ -305,7 +315,7 @@ func (q *Queries) GetLatestMovieWatchDate(ctx context.Context) (interface{}, err
 }
 
 const getMovie = `-- name: GetMovie :one
-SELECT uuid, title, imdb_link, year, rated, released, plot, country, language, box_office, production, call_felissa, slasher, zombies, beast, godzilla, created_datetime, imdb_id, runtime_minutes, wallpaper_fu
+SELECT uuid, title, imdb_link, year, rated, released, plot, country, language, box_office, production, call_felissa, slasher, zombies, beast, godzilla, created_datetime, imdb_id, runtime_minutes, wallpaper_fu, poster
 FROM movie
 WHERE uuid = ?
 `
@@ -334,6 +344,81 @@ func (q *Queries) GetMovie(ctx context.Context, uuid string) (Movie, error) {
 		&i.ImdbID,
 		&i.RuntimeMinutes,
 		&i.WallpaperFu,
+		&i.Poster,
+	)
+	return i, err
+}
+
+const getMovieWatch = `-- name: GetMovieWatch :one
+SELECT w.uuid,
+    w.movie_uuid,
+    w.movie_title,
+    w.imdb_id,
+    w.watched,
+    w.service,
+    w.first_time,
+    w.joe_bob,
+    w.notes,
+    m.imdb_link,
+    m.slasher,
+    m.call_felissa,
+    m.beast,
+    m.godzilla,
+    m.zombies,
+    m.wallpaper_fu,
+    w.watched_at,
+    w.timezone,
+    w.rating
+FROM movie_watch AS w
+    INNER JOIN movie AS m ON m.uuid = w.movie_uuid
+WHERE w.uuid = ?
+`
+
+type GetMovieWatchRow struct {
+	Uuid        string
+	MovieUuid   string
+	MovieTitle  string
+	ImdbID      string
+	Watched     string
+	Service     string
+	FirstTime   int64
+	JoeBob      int64
+	Notes       sql.NullString
+	ImdbLink    string
+	Slasher     int64
+	CallFelissa int64
+	Beast       int64
+	Godzilla    int64
+	Zombies     int64
+	WallpaperFu int64
+	WatchedAt   sql.NullString
+	Timezone    sql.NullString
+	Rating      sql.NullFloat64
+}
+
+func (q *Queries) GetMovieWatch(ctx context.Context, uuid string) (GetMovieWatchRow, error) {
+	row := q.db.QueryRowContext(ctx, getMovieWatch, uuid)
+	var i GetMovieWatchRow
+	err := row.Scan(
+		&i.Uuid,
+		&i.MovieUuid,
+		&i.MovieTitle,
+		&i.ImdbID,
+		&i.Watched,
+		&i.Service,
+		&i.FirstTime,
+		&i.JoeBob,
+		&i.Notes,
+		&i.ImdbLink,
+		&i.Slasher,
+		&i.CallFelissa,
+		&i.Beast,
+		&i.Godzilla,
+		&i.Zombies,
+		&i.WallpaperFu,
+		&i.WatchedAt,
+		&i.Timezone,
+		&i.Rating,
 	)
 	return i, err
 }
@@ -598,17 +683,24 @@ INSERT INTO movie_watch (
         service,
         first_time,
         joe_bob,
-        notes
+        notes,
+        watched_at,
+        timezone,
+        rating
     )
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (uuid) DO
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (uuid) DO
 UPDATE
 SET movie_uuid = excluded.movie_uuid,
     movie_title = excluded.movie_title,
     imdb_id = excluded.imdb_id,
+    watched = excluded.watched,
     service = excluded.service,
     first_time = excluded.first_time,
     joe_bob = excluded.joe_bob,
-    notes = excluded.notes
+    notes = excluded.notes,
+    watched_at = excluded.watched_at,
+    timezone = excluded.timezone,
+    rating = excluded.rating
 `
 
 type InsertMovieWatchParams struct {
@@ -621,6 +713,9 @@ type InsertMovieWatchParams struct {
 	FirstTime  int64
 	JoeBob     int64
 	Notes      sql.NullString
+	WatchedAt  sql.NullString
+	Timezone   sql.NullString
+	Rating     sql.NullFloat64
 }
 
 func (q *Queries) InsertMovieWatch(ctx context.Context, arg InsertMovieWatchParams) error {
@@ -634,6 +729,9 @@ func (q *Queries) InsertMovieWatch(ctx context.Context, arg InsertMovieWatchPara
 		arg.FirstTime,
 		arg.JoeBob,
 		arg.Notes,
+		arg.WatchedAt,
+		arg.Timezone,
+		arg.Rating,
 	)
 	return err
 }
@@ -654,6 +752,20 @@ func (q *Queries) InsertMovieWriter(ctx context.Context, arg InsertMovieWriterPa
 	return err
 }
 
+const updateMoviePoster = `-- name: UpdateMoviePoster :exec
+UPDATE movie SET poster = ? WHERE uuid = ?
+`
+
+type UpdateMoviePosterParams struct {
+	Poster sql.NullString
+	Uuid   string
+}
+
+func (q *Queries) UpdateMoviePoster(ctx context.Context, arg UpdateMoviePosterParams) error {
+	_, err := q.db.ExecContext(ctx, updateMoviePoster, arg.Poster, arg.Uuid)
+	return err
+}
+
 const updateMovieUuidForWatch = `-- name: UpdateMovieUuidForWatch :exec
 UPDATE movie_watch SET movie_uuid = ? WHERE uuid = ?
 `
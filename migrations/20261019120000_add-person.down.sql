@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_movie_credit_person_id;
+DROP TABLE IF EXISTS movie_credit;
+DROP INDEX IF EXISTS idx_person_alias_person_id;
+DROP TABLE IF EXISTS person_alias;
+DROP TABLE IF EXISTS person;
@@ -0,0 +1,154 @@
+CREATE TABLE IF NOT EXISTS person (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT UNIQUE NOT NULL,
+    imdb_id TEXT UNIQUE,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH())
+);
+CREATE TABLE IF NOT EXISTS person_alias (
+    alias TEXT PRIMARY KEY NOT NULL,
+    person_id INTEGER NOT NULL,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH()),
+    FOREIGN KEY (person_id) REFERENCES person(id)
+);
+CREATE INDEX IF NOT EXISTS idx_person_alias_person_id ON person_alias(person_id);
+CREATE TABLE IF NOT EXISTS movie_credit (
+    movie_uuid TEXT NOT NULL,
+    person_id INTEGER NOT NULL,
+    role TEXT NOT NULL,
+    billing_order INTEGER NOT NULL,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH()),
+    PRIMARY KEY (movie_uuid, person_id, role),
+    FOREIGN KEY (movie_uuid) REFERENCES movie(uuid),
+    FOREIGN KEY (person_id) REFERENCES person(id)
+);
+CREATE INDEX IF NOT EXISTS idx_movie_credit_person_id ON movie_credit(person_id);
+
+-- Pull every credit into one place and normalize the names the same way
+-- NormalizePersonName does, so the people made here are the ones
+-- ResolvePersonID finds later: drop stray brackets, trim, strip one
+-- trailing parenthetical like "(uncredited)" or "(novel)", and collapse
+-- whitespace. Tabs and line breaks are spaces to both.
+CREATE TABLE person_staging (
+    movie_uuid TEXT NOT NULL,
+    raw_name TEXT NOT NULL,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL,
+    raw_order INTEGER NOT NULL
+);
+INSERT INTO person_staging (movie_uuid, raw_name, name, role, raw_order)
+SELECT movie_uuid, name, name, 'director', rowid
+FROM movie_director
+UNION ALL
+SELECT movie_uuid, name, name, 'writer', rowid
+FROM movie_writer
+UNION ALL
+SELECT movie_uuid, name, name, 'actor', rowid
+FROM movie_actor;
+UPDATE person_staging
+SET name = TRIM(
+        REPLACE(
+            REPLACE(
+                REPLACE(
+                    REPLACE(
+                        REPLACE(REPLACE(name, '[', ''), ']', ''),
+                        char(9),
+                        ' '
+                    ),
+                    char(10),
+                    ' '
+                ),
+                char(12),
+                ' '
+            ),
+            char(13),
+            ' '
+        ),
+        ' ' || char(11) || char(133) || char(160)
+    );
+-- Only a parenthetical at the very end with no brackets inside it, after a
+-- space. The prefix is the name up to and including its last "(", since
+-- RTRIM strips every other character the name has.
+CREATE TABLE person_staging_paren (
+    staging_rowid INTEGER PRIMARY KEY,
+    name TEXT NOT NULL
+);
+INSERT INTO person_staging_paren (staging_rowid, name)
+SELECT staging_rowid,
+    RTRIM(SUBSTR(name, 1, LENGTH(prefix) - 1), ' ')
+FROM (
+        SELECT rowid AS staging_rowid,
+            name,
+            RTRIM(name, REPLACE(name, '(', '')) AS prefix
+        FROM person_staging
+        WHERE name LIKE '%)'
+    )
+WHERE SUBSTR(prefix, -2, 1) = ' '
+    AND INSTR(
+        SUBSTR(
+            name,
+            LENGTH(prefix) + 1,
+            LENGTH(name) - LENGTH(prefix) - 1
+        ),
+        ')'
+    ) = 0;
+UPDATE person_staging
+SET name = (
+        SELECT p.name
+        FROM person_staging_paren AS p
+        WHERE p.staging_rowid = person_staging.rowid
+    )
+WHERE rowid IN (
+        SELECT staging_rowid
+        FROM person_staging_paren
+    );
+DROP TABLE person_staging_paren;
+-- Any run of spaces down to one, after the rest of the whitespace
+-- strings.Fields splits on that turns up in credits. Every space gets a
+-- marker after it, every marker followed by a space goes, and then the
+-- last marker of each run.
+UPDATE person_staging
+SET name = TRIM(
+        REPLACE(
+            REPLACE(
+                REPLACE(
+                    REPLACE(
+                        REPLACE(REPLACE(name, char(11), ' '), char(133), ' '),
+                        char(160),
+                        ' '
+                    ),
+                    ' ',
+                    ' ' || char(1)
+                ),
+                char(1) || ' ',
+                ''
+            ),
+            char(1),
+            ''
+        )
+    );
+
+INSERT OR IGNORE INTO person (name)
+SELECT DISTINCT name
+FROM person_staging
+WHERE name != ''
+ORDER BY name;
+
+INSERT OR IGNORE INTO person_alias (alias, person_id)
+SELECT DISTINCT s.raw_name, p.id
+FROM person_staging AS s
+    INNER JOIN person AS p ON p.name = s.name
+WHERE s.raw_name != s.name;
+
+INSERT OR IGNORE INTO movie_credit (movie_uuid, person_id, role, billing_order)
+SELECT s.movie_uuid,
+    p.id,
+    s.role,
+    ROW_NUMBER() OVER (
+        PARTITION BY s.movie_uuid, s.role
+        ORDER BY s.raw_order
+    )
+FROM person_staging AS s
+    INNER JOIN person AS p ON p.name = s.name
+ORDER BY s.movie_uuid, s.role, s.raw_order;
+
+DROP TABLE person_staging;
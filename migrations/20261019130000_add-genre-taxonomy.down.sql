@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_movie_genre_movie_uuid_name;
+UPDATE movie_genre SET name = 'Sci-Fi' WHERE name = 'Science Fiction';
+UPDATE movie_genre SET name = 'Film-Noir' WHERE name = 'Film Noir';
+UPDATE movie_genre SET name = 'Game-Show' WHERE name = 'Game Show';
+UPDATE movie_genre SET name = 'Reality-TV' WHERE name = 'Reality TV';
+UPDATE movie_genre SET name = 'Talk-Show' WHERE name = 'Talk Show';
+DROP INDEX IF EXISTS idx_movie_genre_name;
+DROP INDEX IF EXISTS idx_genre_alias_genre_id;
+DROP TABLE IF EXISTS genre_alias;
+DROP TABLE IF EXISTS genre;
@@ -0,0 +1,96 @@
+CREATE TABLE IF NOT EXISTS genre (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT UNIQUE NOT NULL,
+    slug TEXT UNIQUE NOT NULL,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH())
+);
+CREATE TABLE IF NOT EXISTS genre_alias (
+    alias TEXT PRIMARY KEY NOT NULL,
+    genre_id INTEGER NOT NULL,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH()),
+    FOREIGN KEY (genre_id) REFERENCES genre(id)
+);
+CREATE INDEX IF NOT EXISTS idx_genre_alias_genre_id ON genre_alias(genre_id);
+CREATE INDEX IF NOT EXISTS idx_movie_genre_name ON movie_genre(name);
+
+-- Everything OMDB hands out. This is the only place the default taxonomy
+-- lives, commands load it from here with LoadGenreTaxonomy.
+INSERT INTO genre (name, slug)
+VALUES ('Action', 'action'),
+    ('Adult', 'adult'),
+    ('Adventure', 'adventure'),
+    ('Animation', 'animation'),
+    ('Biography', 'biography'),
+    ('Comedy', 'comedy'),
+    ('Crime', 'crime'),
+    ('Documentary', 'documentary'),
+    ('Drama', 'drama'),
+    ('Family', 'family'),
+    ('Fantasy', 'fantasy'),
+    ('Film Noir', 'film-noir'),
+    ('Game Show', 'game-show'),
+    ('History', 'history'),
+    ('Horror', 'horror'),
+    ('Music', 'music'),
+    ('Musical', 'musical'),
+    ('Mystery', 'mystery'),
+    ('News', 'news'),
+    ('Reality TV', 'reality-tv'),
+    ('Romance', 'romance'),
+    ('Science Fiction', 'science-fiction'),
+    ('Short', 'short'),
+    ('Sport', 'sport'),
+    ('Talk Show', 'talk-show'),
+    ('Thriller', 'thriller'),
+    ('War', 'war'),
+    ('Western', 'western');
+INSERT INTO genre_alias (alias, genre_id)
+SELECT a.alias,
+    g.id
+FROM (
+        SELECT 'Sci-Fi' AS alias,
+            'Science Fiction' AS name
+        UNION ALL
+        SELECT 'SciFi', 'Science Fiction'
+        UNION ALL
+        SELECT 'Film-Noir', 'Film Noir'
+        UNION ALL
+        SELECT 'Game-Show', 'Game Show'
+        UNION ALL
+        SELECT 'Reality-TV', 'Reality TV'
+        UNION ALL
+        SELECT 'Talk-Show', 'Talk Show'
+    ) AS a
+    INNER JOIN genre AS g ON g.name = a.name;
+
+-- Point existing genres at their canonical names.
+UPDATE movie_genre
+SET name = (
+        SELECT g.name
+        FROM genre_alias AS a
+            INNER JOIN genre AS g ON g.id = a.genre_id
+        WHERE a.alias = movie_genre.name
+    )
+WHERE name IN (
+        SELECT alias
+        FROM genre_alias
+    );
+-- A movie that had both an alias and its genre now has the genre twice.
+DELETE FROM movie_genre
+WHERE rowid NOT IN (
+        SELECT MIN(rowid)
+        FROM movie_genre
+        GROUP BY movie_uuid,
+            name
+    );
+CREATE UNIQUE INDEX IF NOT EXISTS idx_movie_genre_movie_uuid_name ON movie_genre(movie_uuid, name);
+-- Anything else that's already in there gets a rough slug. Fix them up with
+-- genres rename.
+INSERT OR IGNORE INTO genre (name, slug)
+SELECT DISTINCT name,
+    LOWER(REPLACE(REPLACE(TRIM(name), ' ', '-'), '/', '-'))
+FROM movie_genre
+WHERE name NOT IN (
+        SELECT name
+        FROM genre
+    );
@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS review_fts_delete;
+DROP TRIGGER IF EXISTS review_fts_update;
+DROP TRIGGER IF EXISTS review_fts_insert;
+DROP TRIGGER IF EXISTS movie_watch_fts_delete;
+DROP TRIGGER IF EXISTS movie_watch_fts_update;
+DROP TRIGGER IF EXISTS movie_watch_fts_insert;
+DROP TRIGGER IF EXISTS movie_fts_delete;
+DROP TRIGGER IF EXISTS movie_fts_update;
+DROP TRIGGER IF EXISTS movie_fts_insert;
+DROP TABLE IF EXISTS review_fts;
+DROP TABLE IF EXISTS movie_watch_fts;
+DROP TABLE IF EXISTS movie_fts;
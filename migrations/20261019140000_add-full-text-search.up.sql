@@ -0,0 +1,83 @@
+-- FTS5 needs the sqlite_fts5 build tag on the driver (and on the migrate CLI).
+-- The search tables are external content tables over the tables they index,
+-- so they only store the index and are keyed on the source table's rowid.
+-- VACUUM can renumber implicit rowids, so rebuild them after one with
+-- INSERT INTO movie_fts(movie_fts) VALUES('rebuild').
+CREATE VIRTUAL TABLE IF NOT EXISTS movie_fts USING fts5(
+    title,
+    plot,
+    content = 'movie'
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS movie_watch_fts USING fts5(
+    notes,
+    content = 'movie_watch'
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS review_fts USING fts5(
+    review,
+    content = 'review'
+);
+INSERT INTO movie_fts(movie_fts)
+VALUES ('rebuild');
+INSERT INTO movie_watch_fts(movie_watch_fts)
+VALUES ('rebuild');
+INSERT INTO review_fts(review_fts)
+VALUES ('rebuild');
+-- Keep the search tables in sync with the tables they index.
+CREATE TRIGGER IF NOT EXISTS movie_fts_insert
+AFTER
+INSERT ON movie BEGIN
+INSERT INTO movie_fts (rowid, title, plot)
+VALUES (new.rowid, new.title, new.plot);
+END;
+CREATE TRIGGER IF NOT EXISTS movie_fts_update
+AFTER
+UPDATE OF title,
+    plot ON movie BEGIN
+INSERT INTO movie_fts (movie_fts, rowid, title, plot)
+VALUES ('delete', old.rowid, old.title, old.plot);
+INSERT INTO movie_fts (rowid, title, plot)
+VALUES (new.rowid, new.title, new.plot);
+END;
+CREATE TRIGGER IF NOT EXISTS movie_fts_delete
+AFTER DELETE ON movie BEGIN
+INSERT INTO movie_fts (movie_fts, rowid, title, plot)
+VALUES ('delete', old.rowid, old.title, old.plot);
+END;
+CREATE TRIGGER IF NOT EXISTS movie_watch_fts_insert
+AFTER
+INSERT ON movie_watch BEGIN
+INSERT INTO movie_watch_fts (rowid, notes)
+VALUES (new.rowid, new.notes);
+END;
+CREATE TRIGGER IF NOT EXISTS movie_watch_fts_update
+AFTER
+UPDATE OF notes ON movie_watch BEGIN
+INSERT INTO movie_watch_fts (movie_watch_fts, rowid, notes)
+VALUES ('delete', old.rowid, old.notes);
+INSERT INTO movie_watch_fts (rowid, notes)
+VALUES (new.rowid, new.notes);
+END;
+CREATE TRIGGER IF NOT EXISTS movie_watch_fts_delete
+AFTER DELETE ON movie_watch BEGIN
+INSERT INTO movie_watch_fts (movie_watch_fts, rowid, notes)
+VALUES ('delete', old.rowid, old.notes);
+END;
+CREATE TRIGGER IF NOT EXISTS review_fts_insert
+AFTER
+INSERT ON review BEGIN
+INSERT INTO review_fts (rowid, review)
+VALUES (new.rowid, new.review);
+END;
+CREATE TRIGGER IF NOT EXISTS review_fts_update
+AFTER
+UPDATE OF review ON review BEGIN
+INSERT INTO review_fts (review_fts, rowid, review)
+VALUES ('delete', old.rowid, old.review);
+INSERT INTO review_fts (rowid, review)
+VALUES (new.rowid, new.review);
+END;
+CREATE TRIGGER IF NOT EXISTS review_fts_delete
+AFTER DELETE ON review BEGIN
+INSERT INTO review_fts (review_fts, rowid, review)
+VALUES ('delete', old.rowid, old.review);
+END;
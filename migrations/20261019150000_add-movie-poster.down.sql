@@ -0,0 +1 @@
+ALTER TABLE movie DROP COLUMN poster;
@@ -0,0 +1 @@
+ALTER TABLE movie ADD COLUMN poster TEXT;
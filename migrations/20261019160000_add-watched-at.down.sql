@@ -0,0 +1,2 @@
+ALTER TABLE movie_watch DROP COLUMN timezone;
+ALTER TABLE movie_watch DROP COLUMN watched_at;
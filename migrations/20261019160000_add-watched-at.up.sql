@@ -0,0 +1,2 @@
+ALTER TABLE movie_watch ADD COLUMN watched_at TEXT;
+ALTER TABLE movie_watch ADD COLUMN timezone TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_watchlist_title;
+DROP TABLE IF EXISTS watchlist;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS watchlist (
+    uuid TEXT PRIMARY KEY NOT NULL,
+    imdb_id TEXT UNIQUE NOT NULL,
+    title TEXT NOT NULL,
+    year TEXT NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 3,
+    source TEXT,
+    added TEXT NOT NULL,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH())
+);
+CREATE INDEX IF NOT EXISTS idx_watchlist_title ON watchlist(title);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_list_item_movie_uuid;
+DROP TABLE IF EXISTS list_item;
+DROP TABLE IF EXISTS list;
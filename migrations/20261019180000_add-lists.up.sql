@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS list (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT UNIQUE NOT NULL,
+    description TEXT,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH())
+);
+CREATE TABLE IF NOT EXISTS list_item (
+    list_id INTEGER NOT NULL,
+    movie_uuid TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    comment TEXT,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH()),
+    PRIMARY KEY (list_id, movie_uuid),
+    FOREIGN KEY (list_id) REFERENCES list(id),
+    FOREIGN KEY (movie_uuid) REFERENCES movie(uuid)
+);
+CREATE INDEX IF NOT EXISTS idx_list_item_movie_uuid ON list_item(movie_uuid);
@@ -0,0 +1,2 @@
+ALTER TABLE review DROP COLUMN rating;
+ALTER TABLE movie_watch DROP COLUMN rating;
@@ -0,0 +1,4 @@
+-- Star ratings from 0.5 to 5 in half steps. The one on review is for the
+-- movie overall, the ones on movie_watch are for each viewing.
+ALTER TABLE movie_watch ADD COLUMN rating REAL;
+ALTER TABLE review ADD COLUMN rating REAL;
@@ -0,0 +1,70 @@
+PRAGMA foreign_keys = OFF;
+DROP TRIGGER IF EXISTS review_movie_watch_delete;
+DROP INDEX IF EXISTS idx_review_revision_review_uuid;
+DROP TABLE IF EXISTS review_revision;
+DROP INDEX idx_review_movie_uuid;
+DROP INDEX idx_review_movie_watch_uuid;
+ALTER TABLE review RENAME TO review_new;
+CREATE TABLE review (
+    uuid TEXT PRIMARY KEY NOT NULL,
+    movie_uuid TEXT UNIQUE NOT NULL,
+    movie_title TEXT UNIQUE NOT NULL,
+    review TEXT NOT NULL,
+    liked INTEGER NOT NULL DEFAULT 0,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH()),
+    rating REAL,
+    FOREIGN KEY (movie_uuid) REFERENCES movie(uuid)
+);
+CREATE INDEX idx_review_movie_uuid ON review(movie_uuid);
+CREATE INDEX idx_review_movie_title ON review(movie_title);
+-- Only the latest review for each movie survives.
+INSERT
+    OR IGNORE INTO review (
+        uuid,
+        movie_uuid,
+        movie_title,
+        review,
+        liked,
+        created_datetime,
+        rating
+    )
+SELECT r.uuid,
+    r.movie_uuid,
+    r.movie_title,
+    r.review,
+    r.liked,
+    r.created_datetime,
+    r.rating
+FROM review_new AS r
+WHERE r.uuid = (
+        SELECT uuid
+        FROM review_new
+        WHERE movie_uuid = r.movie_uuid
+        ORDER BY created_datetime DESC,
+            uuid
+        LIMIT 1
+    );
+DROP TABLE review_new;
+-- Copying the reviews over gave them new rowids, so reindex them.
+INSERT INTO review_fts(review_fts)
+VALUES ('rebuild');
+CREATE TRIGGER IF NOT EXISTS review_fts_insert
+AFTER
+INSERT ON review BEGIN
+INSERT INTO review_fts (rowid, review)
+VALUES (new.rowid, new.review);
+END;
+CREATE TRIGGER IF NOT EXISTS review_fts_update
+AFTER
+UPDATE OF review ON review BEGIN
+INSERT INTO review_fts (review_fts, rowid, review)
+VALUES ('delete', old.rowid, old.review);
+INSERT INTO review_fts (rowid, review)
+VALUES (new.rowid, new.review);
+END;
+CREATE TRIGGER IF NOT EXISTS review_fts_delete
+AFTER DELETE ON review BEGIN
+INSERT INTO review_fts (review_fts, rowid, review)
+VALUES ('delete', old.rowid, old.review);
+END;
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,96 @@
+PRAGMA foreign_keys = OFF;
+-- Drop the unique constraints on movie_uuid and movie_title so a movie can
+-- have more than one review, and let a review point at the watch it's for.
+DROP INDEX idx_review_movie_uuid;
+DROP INDEX idx_review_movie_title;
+ALTER TABLE review RENAME TO review_old;
+CREATE TABLE review (
+    uuid TEXT PRIMARY KEY NOT NULL,
+    movie_uuid TEXT NOT NULL,
+    movie_title TEXT NOT NULL,
+    review TEXT NOT NULL,
+    liked INTEGER NOT NULL DEFAULT 0,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH()),
+    rating REAL,
+    movie_watch_uuid TEXT,
+    updated_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH()),
+    FOREIGN KEY (movie_uuid) REFERENCES movie(uuid),
+    FOREIGN KEY (movie_watch_uuid) REFERENCES movie_watch(uuid)
+);
+CREATE INDEX idx_review_movie_uuid ON review(movie_uuid);
+CREATE INDEX idx_review_movie_watch_uuid ON review(movie_watch_uuid);
+INSERT INTO review (
+        uuid,
+        movie_uuid,
+        movie_title,
+        review,
+        liked,
+        created_datetime,
+        rating,
+        updated_datetime
+    )
+SELECT uuid,
+    movie_uuid,
+    movie_title,
+    review,
+    liked,
+    created_datetime,
+    rating,
+    created_datetime
+FROM review_old;
+-- Dropping the old table takes the search triggers with it.
+DROP TABLE review_old;
+-- Copying the reviews over gave them new rowids, so reindex them.
+INSERT INTO review_fts(review_fts)
+VALUES ('rebuild');
+CREATE TRIGGER IF NOT EXISTS review_fts_insert
+AFTER
+INSERT ON review BEGIN
+INSERT INTO review_fts (rowid, review)
+VALUES (new.rowid, new.review);
+END;
+CREATE TRIGGER IF NOT EXISTS review_fts_update
+AFTER
+UPDATE OF review ON review BEGIN
+INSERT INTO review_fts (review_fts, rowid, review)
+VALUES ('delete', old.rowid, old.review);
+INSERT INTO review_fts (rowid, review)
+VALUES (new.rowid, new.review);
+END;
+CREATE TRIGGER IF NOT EXISTS review_fts_delete
+AFTER DELETE ON review BEGIN
+INSERT INTO review_fts (review_fts, rowid, review)
+VALUES ('delete', old.rowid, old.review);
+END;
+-- Deleting a watch leaves its reviews as reviews of the movie.
+CREATE TRIGGER IF NOT EXISTS review_movie_watch_delete
+AFTER DELETE ON movie_watch BEGIN
+UPDATE review
+SET movie_watch_uuid = NULL
+WHERE movie_watch_uuid = old.uuid;
+END;
+-- Every version of a review, oldest first by id.
+CREATE TABLE IF NOT EXISTS review_revision (
+    id INTEGER PRIMARY KEY,
+    review_uuid TEXT NOT NULL,
+    review TEXT NOT NULL,
+    liked INTEGER NOT NULL,
+    rating REAL,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH()),
+    FOREIGN KEY (review_uuid) REFERENCES review(uuid)
+);
+CREATE INDEX IF NOT EXISTS idx_review_revision_review_uuid ON review_revision(review_uuid);
+INSERT INTO review_revision (
+        review_uuid,
+        review,
+        liked,
+        rating,
+        created_datetime
+    )
+SELECT uuid,
+    review,
+    liked,
+    rating,
+    created_datetime
+FROM review;
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_vault_name_file_title;
+DROP TABLE IF EXISTS vault_name;
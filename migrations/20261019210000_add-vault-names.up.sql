@@ -0,0 +1,10 @@
+-- The file title each movie's pages are named with. It's filled in as movies
+-- are inserted and by build-obsidian-vault, which needs the Go side of the
+-- mapping.
+CREATE TABLE IF NOT EXISTS vault_name (
+    movie_uuid TEXT PRIMARY KEY NOT NULL,
+    file_title TEXT NOT NULL,
+    created_datetime INTEGER NOT NULL DEFAULT (UNIXEPOCH()),
+    FOREIGN KEY (movie_uuid) REFERENCES movie(uuid) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_vault_name_file_title ON vault_name(file_title);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS movie_watch_updated_datetime;
+ALTER TABLE movie_watch DROP COLUMN updated_datetime;
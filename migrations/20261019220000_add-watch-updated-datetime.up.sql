@@ -0,0 +1,33 @@
+-- When the watch was last changed, for LAST-MODIFIED in the calendar export.
+-- It's null until then. The upserts rewrite every column whether anything
+-- changed or not, so the trigger only moves it when something did.
+ALTER TABLE movie_watch
+ADD COLUMN updated_datetime INTEGER;
+CREATE TRIGGER IF NOT EXISTS movie_watch_updated_datetime
+AFTER
+UPDATE OF movie_uuid,
+    movie_title,
+    imdb_id,
+    watched,
+    service,
+    first_time,
+    joe_bob,
+    notes,
+    watched_at,
+    timezone,
+    rating ON movie_watch
+    WHEN old.movie_uuid IS NOT new.movie_uuid
+    OR old.movie_title IS NOT new.movie_title
+    OR old.imdb_id IS NOT new.imdb_id
+    OR old.watched IS NOT new.watched
+    OR old.service IS NOT new.service
+    OR old.first_time IS NOT new.first_time
+    OR old.joe_bob IS NOT new.joe_bob
+    OR old.notes IS NOT new.notes
+    OR old.watched_at IS NOT new.watched_at
+    OR old.timezone IS NOT new.timezone
+    OR old.rating IS NOT new.rating BEGIN
+UPDATE movie_watch
+SET updated_datetime = UNIXEPOCH()
+WHERE uuid = new.uuid;
+END;
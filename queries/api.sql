@@ -0,0 +1,101 @@
+-- name: ListMoviesAfter :many
+SELECT uuid,
+    title,
+    imdb_id,
+    year
+FROM movie
+WHERE title > ?
+    OR (
+        title = ?
+        AND uuid > ?
+    )
+ORDER BY title,
+    uuid
+LIMIT ?;
+-- name: ListMovieWatchesBefore :many
+SELECT w.uuid,
+    w.watched,
+    w.watched_at,
+    w.timezone,
+    w.rating,
+    w.service,
+    w.first_time,
+    w.joe_bob,
+    w.notes,
+    m.uuid AS movie_uuid,
+    m.title,
+    m.imdb_id,
+    m.year
+FROM movie_watch AS w
+    INNER JOIN movie AS m ON m.uuid = w.movie_uuid
+WHERE w.watched >= ?
+    AND w.watched <= ?
+    AND w.service LIKE ? ESCAPE '\'
+    AND w.first_time >= ?
+    AND w.joe_bob >= ?
+    AND m.call_felissa >= ?
+    AND m.slasher >= ?
+    AND m.zombies >= ?
+    AND m.beast >= ?
+    AND m.godzilla >= ?
+    AND m.wallpaper_fu >= ?
+    AND (
+        w.watched < ?
+        OR (
+            w.watched = ?
+            AND w.uuid < ?
+        )
+    )
+ORDER BY w.watched DESC,
+    w.uuid DESC
+LIMIT ?;
+-- name: ListReviews :many
+SELECT r.uuid,
+    r.movie_uuid,
+    m.title,
+    m.imdb_id,
+    m.year,
+    r.review,
+    r.liked,
+    r.rating,
+    w.watched
+FROM review AS r
+    INNER JOIN movie AS m ON m.uuid = r.movie_uuid
+    LEFT JOIN movie_watch AS w ON w.uuid = r.movie_watch_uuid
+ORDER BY m.title,
+    r.created_datetime,
+    r.rowid;
+-- name: GetCreditsForPerson :many
+SELECT m.uuid,
+    m.title,
+    m.imdb_id,
+    m.year,
+    c.role,
+    c.billing_order
+FROM movie_credit AS c
+    INNER JOIN movie AS m ON m.uuid = c.movie_uuid
+WHERE c.person_id = ?
+ORDER BY m.year,
+    m.title,
+    c.role;
+-- name: GetWatchStats :one
+SELECT COUNT(*) AS num_watches,
+    COUNT(DISTINCT movie_uuid) AS num_movies,
+    CAST(COALESCE(SUM(first_time), 0) AS INTEGER) AS num_first_time,
+    CAST(COALESCE(SUM(joe_bob), 0) AS INTEGER) AS num_joe_bob,
+    CAST(COALESCE(MIN(watched), '') AS TEXT) AS first_watched,
+    CAST(COALESCE(MAX(watched), '') AS TEXT) AS last_watched
+FROM movie_watch;
+-- name: GetWatchCountsByYear :many
+SELECT CAST(SUBSTR(watched, 1, 4) AS TEXT) AS year,
+    COUNT(*) AS num_watches
+FROM movie_watch
+GROUP BY year
+ORDER BY year;
+-- name: GetWatchCountsByService :many
+SELECT service,
+    COUNT(*) AS num_watches
+FROM movie_watch
+GROUP BY service
+ORDER BY num_watches DESC,
+    service;
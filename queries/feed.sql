@@ -0,0 +1,35 @@
+-- name: GetRecentWatches :many
+SELECT w.uuid,
+    w.watched,
+    w.service,
+    w.first_time,
+    m.title,
+    m.imdb_id,
+    m.imdb_link,
+    m.year,
+    m.plot,
+    r.review,
+    r.liked
+FROM movie_watch AS w
+    INNER JOIN movie AS m ON m.uuid = w.movie_uuid
+    LEFT JOIN review AS r ON r.uuid = COALESCE(
+        (
+            SELECT uuid
+            FROM review
+            WHERE movie_watch_uuid = w.uuid
+            ORDER BY created_datetime DESC,
+                rowid DESC
+            LIMIT 1
+        ), (
+            SELECT uuid
+            FROM review
+            WHERE movie_uuid = w.movie_uuid
+                AND movie_watch_uuid IS NULL
+            ORDER BY created_datetime DESC,
+                rowid DESC
+            LIMIT 1
+        )
+    )
+ORDER BY w.watched DESC,
+    w.created_datetime DESC
+LIMIT ?;
@@ -0,0 +1,60 @@
+-- name: GetAllGenres :many
+SELECT *
+FROM genre
+ORDER BY name;
+-- name: GetAllGenreAliases :many
+SELECT *
+FROM genre_alias
+ORDER BY alias;
+-- name: ListGenres :many
+SELECT g.id,
+    g.name,
+    g.slug,
+    COUNT(mg.uuid) AS num_movies
+FROM genre AS g
+    LEFT JOIN movie_genre AS mg ON mg.name = g.name
+GROUP BY g.id
+ORDER BY g.name;
+-- name: FindGenreByName :one
+SELECT id
+FROM genre
+WHERE name = ?;
+-- name: GetGenre :one
+SELECT *
+FROM genre
+WHERE id = ?;
+-- name: InsertGenre :exec
+INSERT INTO genre (name, slug)
+VALUES (?, ?) ON CONFLICT (name) DO NOTHING;
+-- name: InsertGenreAlias :exec
+INSERT INTO genre_alias (alias, genre_id)
+VALUES (?, ?) ON CONFLICT (alias) DO
+UPDATE
+SET genre_id = excluded.genre_id;
+-- name: UpdateGenre :exec
+UPDATE genre
+SET name = ?,
+    slug = ?
+WHERE id = ?;
+-- name: DeleteCollidingMovieGenres :exec
+DELETE FROM movie_genre
+WHERE name = ?
+    AND movie_uuid IN (
+        SELECT movie_uuid
+        FROM movie_genre
+        WHERE name = ?
+    );
+-- name: RenameMovieGenres :exec
+UPDATE movie_genre
+SET name = ?
+WHERE name = ?;
+-- name: GetMoviesForGenre :many
+SELECT m.uuid,
+    m.title,
+    m.imdb_id,
+    m.year
+FROM movie_genre AS g
+    INNER JOIN movie AS m ON m.uuid = g.movie_uuid
+WHERE g.name = ?
+ORDER BY m.year,
+    m.title;
@@ -0,0 +1,18 @@
+-- name: GetWatchesForCalendar :many
+SELECT w.uuid,
+    w.watched,
+    w.service,
+    w.first_time,
+    w.notes,
+    w.created_datetime,
+    CAST(
+        COALESCE(w.updated_datetime, w.created_datetime) AS INTEGER
+    ) AS updated_datetime,
+    m.title,
+    m.year,
+    m.runtime_minutes,
+    m.imdb_link
+FROM movie_watch AS w
+    INNER JOIN movie AS m ON m.uuid = w.movie_uuid
+ORDER BY w.watched,
+    w.created_datetime;
@@ -0,0 +1,49 @@
+-- name: InsertList :execlastid
+INSERT INTO list (name, description)
+VALUES (?, ?);
+-- name: UpdateListDescription :exec
+UPDATE list
+SET description = ?
+WHERE id = ?;
+-- name: FindList :one
+SELECT *
+FROM list
+WHERE name = ?;
+-- name: GetLists :many
+SELECT *
+FROM list
+ORDER BY name;
+-- name: GetListItems :many
+SELECT i.movie_uuid,
+    i.position,
+    i.comment,
+    m.title,
+    m.imdb_id,
+    m.year
+FROM list_item AS i
+    INNER JOIN movie AS m ON m.uuid = i.movie_uuid
+WHERE i.list_id = ?
+ORDER BY i.position;
+-- name: InsertListItem :exec
+INSERT INTO list_item (list_id, movie_uuid, position, comment)
+VALUES (?, ?, ?, ?);
+-- name: UpdateListItemPosition :exec
+UPDATE list_item
+SET position = ?
+WHERE list_id = ?
+    AND movie_uuid = ?;
+-- name: DeleteListItem :exec
+DELETE FROM list_item
+WHERE list_id = ?
+    AND movie_uuid = ?;
+-- name: DeleteListItems :exec
+DELETE FROM list_item
+WHERE list_id = ?;
+-- name: FindMoviesByTitle :many
+SELECT uuid,
+    title,
+    imdb_id,
+    year
+FROM movie
+WHERE title = ? COLLATE NOCASE
+ORDER BY year;
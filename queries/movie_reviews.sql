@@ -1,16 +1,95 @@
--- name: GetReviewForMovie :one
-SELECT uuid,
-    movie_uuid,
-    movie_title,
-    review,
-    liked
+-- name: GetReview :one
+SELECT r.uuid,
+    r.movie_uuid,
+    r.movie_title,
+    m.imdb_id,
+    r.movie_watch_uuid,
+    w.watched,
+    r.review,
+    r.liked,
+    r.rating,
+    r.created_datetime,
+    r.updated_datetime
+FROM review AS r
+    INNER JOIN movie AS m ON m.uuid = r.movie_uuid
+    LEFT JOIN movie_watch AS w ON w.uuid = r.movie_watch_uuid
+WHERE r.uuid = ?;
+-- name: GetReviewsForMovie :many
+SELECT r.uuid,
+    r.movie_uuid,
+    r.movie_title,
+    m.imdb_id,
+    r.movie_watch_uuid,
+    w.watched,
+    r.review,
+    r.liked,
+    r.rating,
+    r.created_datetime,
+    r.updated_datetime
+FROM review AS r
+    INNER JOIN movie AS m ON m.uuid = r.movie_uuid
+    LEFT JOIN movie_watch AS w ON w.uuid = r.movie_watch_uuid
+WHERE m.imdb_id = ?
+ORDER BY r.created_datetime,
+    w.watched,
+    r.uuid;
+-- name: GetAllReviews :many
+SELECT r.uuid,
+    r.movie_uuid,
+    r.movie_title,
+    m.imdb_id,
+    r.movie_watch_uuid,
+    w.watched,
+    r.review,
+    r.liked,
+    r.rating,
+    r.created_datetime,
+    r.updated_datetime
+FROM review AS r
+    INNER JOIN movie AS m ON m.uuid = r.movie_uuid
+    LEFT JOIN movie_watch AS w ON w.uuid = r.movie_watch_uuid
+ORDER BY r.created_datetime,
+    w.watched,
+    r.uuid;
+-- name: FindReview :one
+SELECT uuid
 FROM review
-WHERE movie_title = ?;
+WHERE movie_uuid = ?
+    AND movie_watch_uuid IS ?
+ORDER BY created_datetime DESC
+LIMIT 1;
 -- name: InsertReview :exec
-INSERT INTO review (uuid, movie_uuid, movie_title, review, liked)
-VALUES (?, ?, ?, ?, ?) ON CONFLICT (movie_uuid) DO
-UPDATE
-SET review = excluded.review,
-    liked = excluded.liked;
+INSERT INTO review (
+        uuid,
+        movie_uuid,
+        movie_title,
+        movie_watch_uuid,
+        review,
+        liked,
+        rating
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?);
+-- name: UpdateReview :exec
+UPDATE review
+SET movie_title = ?,
+    movie_watch_uuid = ?,
+    review = ?,
+    liked = ?,
+    rating = ?,
+    updated_datetime = UNIXEPOCH()
+WHERE uuid = ?;
+-- name: InsertReviewRevision :exec
+INSERT INTO review_revision (review_uuid, review, liked, rating)
+VALUES (?, ?, ?, ?);
+-- name: GetReviewRevisions :many
+SELECT id,
+    review_uuid,
+    review,
+    liked,
+    rating,
+    created_datetime
+FROM review_revision
+WHERE review_uuid = ?
+ORDER BY id;
 -- name: UpdateMovieUuidForReview :exec
-UPDATE review SET movie_uuid = ? WHERE uuid = ?;
\ No newline at end of file
+UPDATE review SET movie_uuid = ? WHERE uuid = ?;
@@ -19,9 +19,35 @@ SELECT w.uuid,
     m.beast,
     m.godzilla,
     m.zombies,
-    m.wallpaper_fu
+    m.wallpaper_fu,
+    w.watched_at,
+    w.timezone,
+    w.rating
 FROM movie_watch AS w
     INNER JOIN movie AS m ON m.uuid = w.movie_uuid;
+-- name: GetMovieWatch :one
+SELECT w.uuid,
+    w.movie_uuid,
+    w.movie_title,
+    w.imdb_id,
+    w.watched,
+    w.service,
+    w.first_time,
+    w.joe_bob,
+    w.notes,
+    m.imdb_link,
+    m.slasher,
+    m.call_felissa,
+    m.beast,
+    m.godzilla,
+    m.zombies,
+    m.wallpaper_fu,
+    w.watched_at,
+    w.timezone,
+    w.rating
+FROM movie_watch AS w
+    INNER JOIN movie AS m ON m.uuid = w.movie_uuid
+WHERE w.uuid = ?;
 -- name: FindMovie :one
 SELECT uuid
 FROM movie
@@ -122,21 +148,29 @@ INSERT INTO movie_watch (
         service,
         first_time,
         joe_bob,
-        notes
+        notes,
+        watched_at,
+        timezone,
+        rating
     )
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (uuid) DO
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (uuid) DO
 UPDATE
 SET movie_uuid = excluded.movie_uuid,
     movie_title = excluded.movie_title,
     imdb_id = excluded.imdb_id,
+    watched = excluded.watched,
     service = excluded.service,
     first_time = excluded.first_time,
     joe_bob = excluded.joe_bob,
-    notes = excluded.notes;
+    notes = excluded.notes,
+    watched_at = excluded.watched_at,
+    timezone = excluded.timezone,
+    rating = excluded.rating;
 -- name: GetGenreNamesForMovie :many
 SELECT name
 FROM movie_genre
-WHERE movie_uuid = ?;
+WHERE movie_uuid = ?
+ORDER BY rowid;
 -- name: GetActorNamesForMovie :many
 SELECT name
 FROM movie_actor
@@ -181,5 +215,7 @@ WHERE movie_uuid = ?;
 -- name: DeleteMovie :exec
 DELETE FROM movie
 WHERE uuid = ?;
+-- name: UpdateMoviePoster :exec
+UPDATE movie SET poster = ? WHERE uuid = ?;
 -- name: UpdateMovieUuidForWatch :exec
 UPDATE movie_watch SET movie_uuid = ? WHERE uuid = ?;
\ No newline at end of file
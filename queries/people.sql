@@ -0,0 +1,97 @@
+-- name: GetCreditsForMovie :many
+SELECT p.id,
+    p.name,
+    c.role
+FROM movie_credit AS c
+    INNER JOIN person AS p ON p.id = c.person_id
+WHERE c.movie_uuid = ?
+ORDER BY CASE
+        c.role
+        WHEN 'director' THEN 0
+        WHEN 'writer' THEN 1
+        ELSE 2
+    END,
+    c.billing_order;
+-- name: GetWatchDatesForMovie :many
+SELECT watched
+FROM movie_watch
+WHERE movie_uuid = ?
+ORDER BY watched;
+-- name: FindPersonByName :one
+SELECT id
+FROM person
+WHERE name = ?;
+-- name: FindPersonByAlias :one
+SELECT person_id
+FROM person_alias
+WHERE alias = ?;
+-- name: GetPerson :one
+SELECT *
+FROM person
+WHERE id = ?;
+-- name: GetAliasesForPerson :many
+SELECT alias
+FROM person_alias
+WHERE person_id = ?
+ORDER BY alias;
+-- name: ListPeople :many
+SELECT p.id,
+    p.name,
+    p.imdb_id,
+    COUNT(c.movie_uuid) AS num_credits
+FROM person AS p
+    LEFT JOIN movie_credit AS c ON c.person_id = p.id
+WHERE p.name LIKE ?
+GROUP BY p.id
+ORDER BY p.name;
+-- name: GetAllMovieCredits :many
+SELECT c.movie_uuid,
+    c.person_id,
+    p.name,
+    c.role
+FROM movie_credit AS c
+    INNER JOIN person AS p ON p.id = c.person_id
+ORDER BY c.movie_uuid,
+    CASE
+        c.role
+        WHEN 'director' THEN 0
+        WHEN 'writer' THEN 1
+        ELSE 2
+    END,
+    c.billing_order;
+-- name: InsertPerson :execlastid
+INSERT INTO person (name)
+VALUES (?);
+-- name: InsertPersonAlias :exec
+INSERT INTO person_alias (alias, person_id)
+VALUES (?, ?) ON CONFLICT (alias) DO
+UPDATE
+SET person_id = excluded.person_id;
+-- name: InsertMovieCredit :exec
+INSERT INTO movie_credit (movie_uuid, person_id, role, billing_order)
+VALUES (?, ?, ?, ?) ON CONFLICT (movie_uuid, person_id, role) DO NOTHING;
+-- name: DeleteCreditsForMovie :exec
+DELETE FROM movie_credit
+WHERE movie_uuid = ?;
+-- name: ReassignCreditsForPerson :exec
+UPDATE OR IGNORE movie_credit
+SET person_id = ?
+WHERE person_id = ?;
+-- name: DeleteCreditsForPerson :exec
+DELETE FROM movie_credit
+WHERE person_id = ?;
+-- name: ReassignAliasesForPerson :exec
+UPDATE person_alias
+SET person_id = ?
+WHERE person_id = ?;
+-- name: DeletePerson :exec
+DELETE FROM person
+WHERE id = ?;
+-- name: UpdatePersonName :exec
+UPDATE person
+SET name = ?
+WHERE id = ?;
+-- name: UpdatePersonImdbID :exec
+UPDATE person
+SET imdb_id = ?
+WHERE id = ?;
@@ -0,0 +1,65 @@
+-- name: GetAverageRatingsByYear :many
+SELECT CAST(SUBSTR(watched, 1, 4) AS TEXT) AS year,
+    CAST(AVG(rating) AS REAL) AS average_rating,
+    COUNT(rating) AS num_ratings
+FROM movie_watch
+WHERE rating IS NOT NULL
+GROUP BY year
+ORDER BY year;
+-- name: GetAverageRatingsByGenre :many
+SELECT g.name,
+    CAST(AVG(w.rating) AS REAL) AS average_rating,
+    COUNT(w.rating) AS num_ratings
+FROM movie_watch AS w
+    INNER JOIN movie_genre AS g ON g.movie_uuid = w.movie_uuid
+WHERE w.rating IS NOT NULL
+GROUP BY g.name
+HAVING COUNT(w.rating) >= ?
+ORDER BY average_rating DESC,
+    g.name;
+-- name: GetAverageRatingsByDirector :many
+SELECT p.name,
+    CAST(AVG(w.rating) AS REAL) AS average_rating,
+    COUNT(w.rating) AS num_ratings
+FROM movie_watch AS w
+    INNER JOIN movie_credit AS c ON c.movie_uuid = w.movie_uuid
+    AND c.role = 'director'
+    INNER JOIN person AS p ON p.id = c.person_id
+WHERE w.rating IS NOT NULL
+GROUP BY p.id,
+    p.name
+HAVING COUNT(w.rating) >= ?
+ORDER BY average_rating DESC,
+    p.name;
+-- name: GetImdbRatingComparisons :many
+SELECT m.title,
+    m.imdb_id,
+    CAST(COALESCE(rv.rating, AVG(w.rating)) AS REAL) AS my_rating,
+    r.value AS imdb_rating
+FROM movie AS m
+    INNER JOIN movie_rating AS r ON r.movie_uuid = m.uuid
+    AND r.source = 'Internet Movie Database'
+    LEFT JOIN review AS rv ON rv.uuid = (
+        SELECT uuid
+        FROM review
+        WHERE movie_uuid = m.uuid
+        ORDER BY created_datetime DESC,
+            rowid DESC
+        LIMIT 1
+    )
+    LEFT JOIN movie_watch AS w ON w.movie_uuid = m.uuid
+    AND w.rating IS NOT NULL
+GROUP BY m.uuid,
+    m.title,
+    m.imdb_id,
+    rv.rating,
+    r.value
+HAVING rv.rating IS NOT NULL
+    OR COUNT(w.rating) > 0
+ORDER BY m.title;
+-- name: GetAverageWatchRatingForMovie :one
+SELECT COUNT(rating) AS num_ratings,
+    CAST(COALESCE(AVG(rating), 0) AS REAL) AS average_rating
+FROM movie_watch
+WHERE movie_uuid = ?
+    AND rating IS NOT NULL;
@@ -0,0 +1,63 @@
+-- name: SearchMovieText :many
+SELECT m.uuid,
+    m.title,
+    m.imdb_id,
+    m.year,
+    m.call_felissa,
+    m.slasher,
+    m.zombies,
+    m.beast,
+    m.godzilla,
+    m.wallpaper_fu,
+    CAST(
+        snippet(movie_fts, -1, '**', '**', '...', 16) AS TEXT
+    ) AS snippet,
+    CAST(bm25(movie_fts, 2.0, 1.0) AS REAL) AS rank
+FROM movie_fts
+    INNER JOIN movie AS m ON m.rowid = movie_fts.rowid
+WHERE movie_fts MATCH ?;
+-- name: SearchWatchNotes :many
+SELECT w.uuid,
+    w.watched,
+    m.uuid AS movie_uuid,
+    m.title,
+    m.imdb_id,
+    m.year,
+    m.call_felissa,
+    m.slasher,
+    m.zombies,
+    m.beast,
+    m.godzilla,
+    m.wallpaper_fu,
+    CAST(
+        snippet(movie_watch_fts, -1, '**', '**', '...', 16) AS TEXT
+    ) AS snippet,
+    CAST(bm25(movie_watch_fts, 1.0) AS REAL) AS rank
+FROM movie_watch_fts
+    INNER JOIN movie_watch AS w ON w.rowid = movie_watch_fts.rowid
+    INNER JOIN movie AS m ON m.uuid = w.movie_uuid
+WHERE movie_watch_fts MATCH ?;
+-- name: SearchReviewText :many
+SELECT r.uuid,
+    m.uuid AS movie_uuid,
+    m.title,
+    m.imdb_id,
+    m.year,
+    m.call_felissa,
+    m.slasher,
+    m.zombies,
+    m.beast,
+    m.godzilla,
+    m.wallpaper_fu,
+    CAST(
+        snippet(review_fts, -1, '**', '**', '...', 16) AS TEXT
+    ) AS snippet,
+    CAST(bm25(review_fts, 1.0) AS REAL) AS rank
+FROM review_fts
+    INNER JOIN review AS r ON r.rowid = review_fts.rowid
+    INNER JOIN movie AS m ON m.uuid = r.movie_uuid
+WHERE review_fts MATCH ?;
+-- name: GetMovieUuidsForPerson :many
+SELECT DISTINCT movie_uuid
+FROM movie_credit
+WHERE person_id = ?;
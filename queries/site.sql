@@ -0,0 +1,27 @@
+-- name: ListWatchedMovies :many
+SELECT m.uuid,
+    m.title,
+    m.imdb_id,
+    m.year,
+    m.poster,
+    COUNT(w.uuid) AS num_watches,
+    CAST(MAX(w.watched) AS TEXT) AS last_watched,
+    CAST(
+        MAX(
+            m.created_datetime,
+            MAX(COALESCE(w.updated_datetime, w.created_datetime)),
+            COALESCE(
+                (
+                    SELECT MAX(updated_datetime)
+                    FROM review
+                    WHERE movie_uuid = m.uuid
+                ),
+                0
+            )
+        ) AS INTEGER
+    ) AS latest_created_datetime
+FROM movie AS m
+    INNER JOIN movie_watch AS w ON w.movie_uuid = m.uuid
+GROUP BY m.uuid
+ORDER BY m.title,
+    m.year;
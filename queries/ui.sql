@@ -0,0 +1,38 @@
+-- name: GetWatchMonths :many
+SELECT CAST(substr(watched, 1, 7) AS TEXT) AS month,
+    COUNT(*) AS num_watches
+FROM movie_watch
+GROUP BY month
+ORDER BY month DESC;
+-- name: GetWatchesForMovie :many
+SELECT uuid,
+    watched,
+    service,
+    first_time,
+    joe_bob,
+    notes
+FROM movie_watch
+WHERE movie_uuid = ?
+ORDER BY watched;
+-- name: GetReviewsForMovieUuid :many
+SELECT r.uuid,
+    w.watched,
+    r.review,
+    r.liked,
+    r.rating
+FROM review AS r
+    LEFT JOIN movie_watch AS w ON w.uuid = r.movie_watch_uuid
+WHERE r.movie_uuid = ?
+ORDER BY r.created_datetime DESC,
+    r.rowid DESC;
+-- name: FindGenreBySlug :one
+SELECT *
+FROM genre
+WHERE slug = ?;
+-- name: GetGenresForMovie :many
+SELECT g.name,
+    g.slug
+FROM movie_genre AS mg
+    INNER JOIN genre AS g ON g.name = mg.name
+WHERE mg.movie_uuid = ?
+ORDER BY mg.rowid;
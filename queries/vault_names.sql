@@ -0,0 +1,37 @@
+-- name: UpsertVaultName :exec
+INSERT INTO vault_name (movie_uuid, file_title)
+VALUES (?, ?) ON CONFLICT (movie_uuid) DO
+UPDATE
+SET file_title = excluded.file_title;
+-- name: GetVaultFileTitle :one
+SELECT file_title
+FROM vault_name
+WHERE movie_uuid = ?;
+-- name: FindMoviesForFileTitle :many
+SELECT m.uuid,
+    m.title,
+    m.imdb_id
+FROM vault_name AS v
+    INNER JOIN movie AS m ON v.movie_uuid = m.uuid
+WHERE v.file_title = ?
+ORDER BY m.title,
+    m.imdb_id;
+-- name: GetVaultNames :many
+SELECT m.imdb_id,
+    v.file_title
+FROM vault_name AS v
+    INNER JOIN movie AS m ON v.movie_uuid = m.uuid;
+-- name: GetMoviesWithoutVaultName :many
+SELECT m.uuid,
+    m.title
+FROM movie AS m
+    LEFT JOIN vault_name AS v ON v.movie_uuid = m.uuid
+WHERE v.movie_uuid IS NULL
+ORDER BY m.title,
+    m.uuid;
+-- name: GetMovieTitles :many
+SELECT uuid,
+    title
+FROM movie
+ORDER BY title,
+    uuid;
@@ -0,0 +1,11 @@
+-- name: GetAllMovies :many
+SELECT *
+FROM movie
+ORDER BY title,
+    uuid;
+-- name: GetAllMovieGenreNames :many
+SELECT movie_uuid,
+    name
+FROM movie_genre
+ORDER BY movie_uuid,
+    rowid;
@@ -0,0 +1,32 @@
+-- name: InsertWatchlistItem :exec
+INSERT INTO watchlist (
+        uuid,
+        imdb_id,
+        title,
+        year,
+        priority,
+        source,
+        added
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?) ON CONFLICT (imdb_id) DO
+UPDATE
+SET priority = excluded.priority,
+    source = COALESCE(excluded.source, watchlist.source);
+-- name: GetWatchlist :many
+SELECT *
+FROM watchlist
+ORDER BY priority DESC,
+    added,
+    title;
+-- name: FindWatchlistItem :one
+SELECT *
+FROM watchlist
+WHERE imdb_id = ?;
+-- name: FindWatchlistItemsByTitle :many
+SELECT *
+FROM watchlist
+WHERE title = ? COLLATE NOCASE
+ORDER BY year;
+-- name: DeleteWatchlistItem :execrows
+DELETE FROM watchlist
+WHERE imdb_id = ?;
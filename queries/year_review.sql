@@ -0,0 +1,79 @@
+-- name: GetMovieWatchesBetween :many
+SELECT w.uuid,
+    w.movie_uuid,
+    w.movie_title,
+    w.imdb_id,
+    w.watched,
+    w.service,
+    w.first_time,
+    m.year,
+    m.runtime_minutes
+FROM movie_watch AS w
+    INNER JOIN movie AS m ON m.uuid = w.movie_uuid
+WHERE w.watched >= ?
+    AND w.watched < ?
+ORDER BY w.watched,
+    w.created_datetime;
+-- name: GetDirectorWatchCountsBetween :many
+SELECT p.name,
+    COUNT(*) AS num_watches
+FROM movie_watch AS w
+    INNER JOIN movie_credit AS c ON c.movie_uuid = w.movie_uuid
+    AND c.role = 'director'
+    INNER JOIN person AS p ON p.id = c.person_id
+WHERE w.watched >= ?
+    AND w.watched < ?
+GROUP BY p.id,
+    p.name
+ORDER BY num_watches DESC,
+    p.name
+LIMIT ?;
+-- name: GetActorWatchCountsBetween :many
+SELECT p.name,
+    COUNT(*) AS num_watches
+FROM movie_watch AS w
+    INNER JOIN movie_credit AS c ON c.movie_uuid = w.movie_uuid
+    AND c.role = 'actor'
+    INNER JOIN person AS p ON p.id = c.person_id
+WHERE w.watched >= ?
+    AND w.watched < ?
+GROUP BY p.id,
+    p.name
+ORDER BY num_watches DESC,
+    p.name
+LIMIT ?;
+-- name: GetRatingsForMoviesWatchedBetween :many
+SELECT m.uuid,
+    m.title,
+    m.imdb_id,
+    r.source,
+    r.value
+FROM movie AS m
+    INNER JOIN movie_rating AS r ON r.movie_uuid = m.uuid
+WHERE m.uuid IN (
+        SELECT movie_uuid
+        FROM movie_watch
+        WHERE watched >= ?
+            AND watched < ?
+    );
+-- name: GetLikedReviewsForMoviesWatchedBetween :many
+SELECT m.title,
+    m.imdb_id
+FROM review AS r
+    INNER JOIN movie AS m ON m.uuid = r.movie_uuid
+WHERE r.liked = 1
+    AND r.uuid = (
+        SELECT uuid
+        FROM review
+        WHERE movie_uuid = r.movie_uuid
+        ORDER BY created_datetime DESC,
+            rowid DESC
+        LIMIT 1
+    )
+    AND r.movie_uuid IN (
+        SELECT movie_uuid
+        FROM movie_watch
+        WHERE watched >= ?
+            AND watched < ?
+    )
+ORDER BY m.title;
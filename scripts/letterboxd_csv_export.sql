@@ -4,7 +4,8 @@
 SELECT
     movie.imdb_id AS imdbID,
     watch.watched AS WatchedDate,
-    NOT watch.first_time AS Rewatch
+    NOT watch.first_time AS Rewatch,
+    watch.rating AS Rating
 FROM movie_watch AS watch
 INNER JOIN movie ON
 watch.movie_uuid = movie.uuid